@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/luisdev-dark/realgov3.git/auth"
 	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/repository"
 	"github.com/luisdev-dark/realgov3.git/routes"
 )
 
@@ -17,14 +20,33 @@ var (
 // Handler es el entrypoint que Vercel usa para esta Function.
 func Handler(w http.ResponseWriter, r *http.Request) {
 	routerOnce.Do(func() {
-		// Inicializar DB
-		if err := db.InitDB(); err != nil {
+		// Inicializar DB (AUTO_MIGRATE=true aplica migraciones pendientes)
+		var dbOpts []db.Option
+		if db.AutoMigrateFromEnv() {
+			dbOpts = append(dbOpts, db.WithMigrations())
+		}
+		if err := db.InitDB(dbOpts...); err != nil {
 			log.Printf("Error inicializando DB: %v", err)
 			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
 			return
 		}
-		// Configurar router
-		r := routes.SetupRouter()
+		// Proveedor de SMS para los códigos de acceso
+		sender, err := auth.SenderFromEnv()
+		if err != nil {
+			log.Printf("Error configurando SMS: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+		// Clave de los pases de abordar
+		boarding, err := auth.BoardingSignerFromEnv()
+		if err != nil {
+			log.Printf("Error configurando pases de abordar: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+		// Configurar router con los repositorios de Postgres
+		h := handlers.New(repository.NewPostgres(db.GetDB()), sender, boarding)
+		r := routes.SetupRouter(h)
 		// Vercel envía la ruta completa (ej: /api/routes), pero el router espera /routes
 		// Usamos StripPrefix para remover /api
 		router = http.StripPrefix("/api", r)
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidBoardingToken indica que el pase de abordar está mal formado o
+// su firma no corresponde
+var ErrInvalidBoardingToken = errors.New("pase de abordar inválido")
+
+// boardingTokenVersion antecede a cada token para poder cambiar el formato
+// sin aceptar por error pases antiguos
+const boardingTokenVersion = "b1"
+
+// minBoardingKeyLen es el largo mínimo de BOARDING_SECRET
+const minBoardingKeyLen = 32
+
+// BoardingPass son los datos firmados en el pase de abordar de un viaje
+type BoardingPass struct {
+	TripID      uuid.UUID
+	DepartureID uuid.UUID
+	PassengerID uuid.UUID
+}
+
+// BoardingSigner firma y verifica pases de abordar con HMAC-SHA256. El
+// token lleva los ids en claro, así que se verifica sin consultar la base
+// de datos; solo quien tiene la clave puede generar uno válido.
+type BoardingSigner struct {
+	key []byte
+}
+
+// NewBoardingSigner crea un BoardingSigner con la clave dada
+func NewBoardingSigner(key []byte) *BoardingSigner {
+	return &BoardingSigner{key: key}
+}
+
+// ErrMissingBoardingSecret indica que BOARDING_SECRET no está definida fuera
+// del modo desarrollo
+var ErrMissingBoardingSecret = errors.New("BOARDING_SECRET no está definida")
+
+// BoardingSignerFromEnv usa BOARDING_SECRET (al menos 32 caracteres). Sin
+// configurar retorna ErrMissingBoardingSecret, salvo con APP_ENV=development:
+// ahí genera una clave aleatoria, y los pases dejan de valer al reiniciar y
+// no se comparten entre instancias.
+func BoardingSignerFromEnv() (*BoardingSigner, error) {
+	secret := os.Getenv("BOARDING_SECRET")
+	if secret == "" {
+		if os.Getenv("APP_ENV") != "development" {
+			return nil, ErrMissingBoardingSecret
+		}
+		log.Println("BOARDING_SECRET no está definida, usando una clave temporal para los pases de abordar (APP_ENV=development)")
+		key := make([]byte, minBoardingKeyLen)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		return NewBoardingSigner(key), nil
+	}
+	if len(secret) < minBoardingKeyLen {
+		return nil, fmt.Errorf("BOARDING_SECRET debe tener al menos %d caracteres", minBoardingKeyLen)
+	}
+	return NewBoardingSigner([]byte(secret)), nil
+}
+
+// Sign retorna el token del pase: versión, ids del viaje, salida y pasajero
+// y la firma, en base64url separados por puntos
+func (s *BoardingSigner) Sign(pass BoardingPass) string {
+	payload := make([]byte, 0, 48)
+	payload = append(payload, pass.TripID[:]...)
+	payload = append(payload, pass.DepartureID[:]...)
+	payload = append(payload, pass.PassengerID[:]...)
+	body := boardingTokenVersion + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.mac(body))
+}
+
+// Verify comprueba la firma del token y retorna sus datos.
+// Retorna ErrInvalidBoardingToken si no es válido.
+func (s *BoardingSigner) Verify(token string) (BoardingPass, error) {
+	token = strings.TrimSpace(token)
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return BoardingPass{}, ErrInvalidBoardingToken
+	}
+	body := token[:i]
+	signature, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(signature, s.mac(body)) {
+		return BoardingPass{}, ErrInvalidBoardingToken
+	}
+
+	version, encoded, ok := strings.Cut(body, ".")
+	if !ok || version != boardingTokenVersion {
+		return BoardingPass{}, ErrInvalidBoardingToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) != 48 {
+		return BoardingPass{}, ErrInvalidBoardingToken
+	}
+	var pass BoardingPass
+	copy(pass.TripID[:], payload[0:16])
+	copy(pass.DepartureID[:], payload[16:32])
+	copy(pass.PassengerID[:], payload[32:48])
+	return pass, nil
+}
+
+func (s *BoardingSigner) mac(body string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte(body))
+	return m.Sum(nil)
+}
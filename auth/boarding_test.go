@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBoardingSignerFromEnv(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	t.Setenv("BOARDING_SECRET", "")
+	if _, err := BoardingSignerFromEnv(); !errors.Is(err, ErrMissingBoardingSecret) {
+		t.Fatalf("sin BOARDING_SECRET err = %v, se esperaba ErrMissingBoardingSecret", err)
+	}
+
+	t.Setenv("BOARDING_SECRET", "corta")
+	if _, err := BoardingSignerFromEnv(); err == nil {
+		t.Fatal("una clave de menos de 32 caracteres no retornó error")
+	}
+
+	t.Setenv("BOARDING_SECRET", "")
+	t.Setenv("APP_ENV", "development")
+	if _, err := BoardingSignerFromEnv(); err != nil {
+		t.Fatalf("con APP_ENV=development err = %v", err)
+	}
+}
+
+func TestBoardingPassRoundTrip(t *testing.T) {
+	t.Setenv("BOARDING_SECRET", strings.Repeat("s", minBoardingKeyLen))
+	signer, err := BoardingSignerFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass := BoardingPass{TripID: uuid.New(), DepartureID: uuid.New(), PassengerID: uuid.New()}
+	token := signer.Sign(pass)
+
+	// Otra instancia con la misma clave verifica el pase
+	other, err := BoardingSignerFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := other.Verify(token)
+	if err != nil || got != pass {
+		t.Fatalf("Verify = %+v, %v; se esperaba %+v", got, err, pass)
+	}
+
+	foreign := NewBoardingSigner([]byte(strings.Repeat("x", minBoardingKeyLen)))
+	for _, bad := range []string{"", "b1.abc", token + "x", foreign.Sign(pass)} {
+		if _, err := signer.Verify(bad); !errors.Is(err, ErrInvalidBoardingToken) {
+			t.Errorf("Verify(%q) err = %v, se esperaba ErrInvalidBoardingToken", bad, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type contextKey struct{}
+
+// WithUser retorna un contexto con el usuario autenticado
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext retorna el usuario autenticado, si existe
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*models.User)
+	return user, ok && user != nil
+}
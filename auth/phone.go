@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPhone indica un número de teléfono con formato inválido
+var ErrInvalidPhone = errors.New("teléfono inválido")
+
+// NormalizePhone convierte un número a formato E.164.
+// Los celulares peruanos de 9 dígitos (9XXXXXXXX) reciben el prefijo +51.
+func NormalizePhone(raw string) (string, error) {
+	var digits strings.Builder
+	for i, c := range strings.TrimSpace(raw) {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case c == '+' && i == 0:
+		case c == ' ' || c == '-' || c == '(' || c == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	phone := digits.String()
+	if len(phone) == 9 && phone[0] == '9' {
+		phone = "51" + phone
+	}
+	if len(phone) < 8 || len(phone) > 15 {
+		return "", ErrInvalidPhone
+	}
+	return "+" + phone, nil
+}
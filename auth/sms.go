@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// SMSSender envía mensajes de texto a un número de teléfono
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// LogSender es el stub de desarrollo: escribe el mensaje en el log
+// en lugar de enviarlo
+type LogSender struct{}
+
+// Send registra el mensaje en el log
+func (LogSender) Send(ctx context.Context, phone, message string) error {
+	log.Printf("[SMS] %s: %s", phone, message)
+	return nil
+}
+
+// WebhookSender envía el SMS haciendo POST a un proveedor externo.
+// El cuerpo es {"to": "+51...", "message": "..."}
+type WebhookSender struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+// Send envía el mensaje al webhook configurado
+func (s WebhookSender) Send(ctx context.Context, phone, message string) error {
+	body, err := json.Marshal(map[string]string{"to": phone, "message": message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("proveedor SMS respondió %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SenderFromEnv elige el SMSSender según SMS_PROVIDER.
+// Sin configurar (o "log") usa LogSender; "webhook" usa SMS_WEBHOOK_URL
+// y SMS_WEBHOOK_TOKEN.
+func SenderFromEnv() (SMSSender, error) {
+	switch provider := os.Getenv("SMS_PROVIDER"); provider {
+	case "", "log":
+		return LogSender{}, nil
+	case "webhook":
+		url := os.Getenv("SMS_WEBHOOK_URL")
+		if url == "" {
+			return nil, fmt.Errorf("SMS_WEBHOOK_URL no está definida")
+		}
+		return WebhookSender{URL: url, Token: os.Getenv("SMS_WEBHOOK_TOKEN")}, nil
+	default:
+		return nil, fmt.Errorf("SMS_PROVIDER desconocido: %s", provider)
+	}
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// SessionTTL es la duración de un token de sesión
+	SessionTTL = 30 * 24 * time.Hour
+	// CodeTTL es la validez de un código de un solo uso
+	CodeTTL = 5 * time.Minute
+	// CodeResendInterval es el tiempo mínimo entre dos envíos al mismo teléfono
+	CodeResendInterval = time.Minute
+	// MaxCodeAttempts es el número de intentos fallidos permitidos por código
+	MaxCodeAttempts = 5
+)
+
+// NewToken genera un token de sesión opaco (32 bytes aleatorios en base64url)
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken retorna el hash que se guarda en base de datos.
+// El token en claro solo lo conoce el cliente.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewCode genera un código numérico de 6 dígitos
+func NewCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// HashCode retorna el hash de un código ligado al teléfono que lo recibió
+func HashCode(phone, code string) string {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
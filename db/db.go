@@ -10,8 +10,32 @@ import (
 
 var pool *pgxpool.Pool
 
+// Option configura InitDB
+type Option func(*options)
+
+type options struct {
+	migrate bool
+}
+
+// WithMigrations aplica las migraciones pendientes antes de que InitDB retorne
+func WithMigrations() Option {
+	return func(o *options) {
+		o.migrate = true
+	}
+}
+
+// AutoMigrateFromEnv indica si AUTO_MIGRATE pide migrar al arrancar
+func AutoMigrateFromEnv() bool {
+	return os.Getenv("AUTO_MIGRATE") == "true"
+}
+
 // InitDB inicializa el pool de conexiones a Postgres
-func InitDB() error {
+func InitDB(opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		log.Fatal("DATABASE_URL no está definida")
@@ -30,6 +54,16 @@ func InitDB() error {
 	}
 
 	log.Println("Conectado a Postgres")
+
+	if o.migrate {
+		applied, err := MigrateUp(ctx, pool)
+		if err != nil {
+			return err
+		}
+		for _, m := range applied {
+			log.Printf("Migración aplicada: %04d_%s", m.Version, m.Name)
+		}
+	}
 	return nil
 }
 
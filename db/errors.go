@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Errores de dominio en los que MapError traduce los errores de pgx
+var (
+	// ErrNotFound indica que la consulta no retornó filas
+	ErrNotFound = errors.New("registro no encontrado")
+	// ErrUniqueViolation indica un valor duplicado en una restricción única
+	ErrUniqueViolation = errors.New("valor duplicado")
+	// ErrForeignKeyViolation indica una referencia a un registro inexistente
+	// o el borrado de un registro que aún está referenciado
+	ErrForeignKeyViolation = errors.New("referencia inválida")
+	// ErrCheckViolation indica datos que no cumplen una restricción CHECK o NOT NULL
+	ErrCheckViolation = errors.New("datos fuera de las restricciones")
+	// ErrCanceled indica que la operación se canceló o superó su tiempo límite
+	ErrCanceled = errors.New("operación cancelada o fuera de tiempo")
+)
+
+// ConstraintError es una violación de restricción de Postgres. errors.Is
+// la compara con su Kind (ErrUniqueViolation, ErrForeignKeyViolation o
+// ErrCheckViolation) y errors.As permite leer la restricción que falló.
+type ConstraintError struct {
+	Kind       error
+	Constraint string
+	Err        *pgconn.PgError
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Constraint == "" {
+		return e.Kind.Error()
+	}
+	return fmt.Sprintf("%v (%s)", e.Kind, e.Constraint)
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// Códigos SQLSTATE que se traducen
+const (
+	codeNotNullViolation    = "23502"
+	codeForeignKeyViolation = "23503"
+	codeUniqueViolation     = "23505"
+	codeCheckViolation      = "23514"
+	codeQueryCanceled       = "57014"
+)
+
+// MapError traduce los errores de pgx y pgconn a los errores de dominio del
+// paquete. Los demás errores (y nil) se retornan sin cambios, y aplicarla
+// dos veces da el mismo resultado.
+func MapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrCanceled) {
+		return err
+	}
+	var constraintErr *ConstraintError
+	if errors.As(err, &constraintErr) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case codeUniqueViolation:
+			return &ConstraintError{Kind: ErrUniqueViolation, Constraint: pgErr.ConstraintName, Err: pgErr}
+		case codeForeignKeyViolation:
+			return &ConstraintError{Kind: ErrForeignKeyViolation, Constraint: pgErr.ConstraintName, Err: pgErr}
+		case codeCheckViolation, codeNotNullViolation:
+			return &ConstraintError{Kind: ErrCheckViolation, Constraint: pgErr.ConstraintName, Err: pgErr}
+		case codeQueryCanceled:
+			return fmt.Errorf("%w: %w", ErrCanceled, err)
+		}
+	}
+	return err
+}
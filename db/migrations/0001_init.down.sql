@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS app.trips;
+DROP TABLE IF EXISTS app.route_stops;
+DROP TABLE IF EXISTS app.routes;
+DROP TABLE IF EXISTS app.users;
+DROP SCHEMA IF EXISTS app;
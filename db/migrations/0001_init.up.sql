@@ -0,0 +1,67 @@
+-- Esquema base del MVP: rutas, paradas, usuarios y viajes.
+-- Usa IF NOT EXISTS para poder aplicarse sobre bases creadas a mano en Neon.
+
+CREATE SCHEMA IF NOT EXISTS app;
+
+CREATE TABLE IF NOT EXISTS app.users (
+    id          uuid PRIMARY KEY,
+    name        text NOT NULL,
+    email       text,
+    phone       text,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS app.routes (
+    id                uuid PRIMARY KEY,
+    name              text NOT NULL,
+    is_active         boolean NOT NULL DEFAULT true,
+    origin_name       text NOT NULL,
+    origin_lat        double precision NOT NULL,
+    origin_lon        double precision NOT NULL,
+    destination_name  text NOT NULL,
+    destination_lat   double precision NOT NULL,
+    destination_lon   double precision NOT NULL,
+    base_price_cents  integer NOT NULL CHECK (base_price_cents >= 0),
+    currency          text NOT NULL DEFAULT 'PEN',
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    updated_at        timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS app.route_stops (
+    id          uuid PRIMARY KEY,
+    route_id    uuid NOT NULL REFERENCES app.routes (id),
+    name        text NOT NULL,
+    stop_order  integer NOT NULL,
+    latitude    double precision NOT NULL,
+    longitude   double precision NOT NULL,
+    is_active   boolean NOT NULL DEFAULT true,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS route_stops_route_id_idx ON app.route_stops (route_id);
+
+CREATE TABLE IF NOT EXISTS app.trips (
+    id               uuid PRIMARY KEY,
+    route_id         uuid NOT NULL REFERENCES app.routes (id),
+    passenger_id     uuid NOT NULL REFERENCES app.users (id),
+    pickup_stop_id   uuid REFERENCES app.route_stops (id),
+    dropoff_stop_id  uuid REFERENCES app.route_stops (id),
+    status           text NOT NULL DEFAULT 'requested',
+    payment_method   text NOT NULL,
+    price_cents      integer NOT NULL,
+    currency         text NOT NULL,
+    scheduled_at     timestamptz,
+    started_at       timestamptz,
+    finished_at      timestamptz,
+    cancelled_at     timestamptz,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    updated_at       timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT trips_status_check
+        CHECK (status IN ('requested', 'confirmed', 'completed', 'cancelled')),
+    CONSTRAINT trips_payment_method_check
+        CHECK (payment_method IN ('cash', 'yape', 'pling'))
+);
+
+CREATE INDEX IF NOT EXISTS trips_passenger_id_idx ON app.trips (passenger_id);
+CREATE INDEX IF NOT EXISTS trips_route_id_idx ON app.trips (route_id);
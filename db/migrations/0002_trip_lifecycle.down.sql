@@ -0,0 +1,4 @@
+-- Falla si quedan viajes en estado "started"
+ALTER TABLE app.trips DROP CONSTRAINT IF EXISTS trips_status_check;
+ALTER TABLE app.trips ADD CONSTRAINT trips_status_check
+    CHECK (status IN ('requested', 'confirmed', 'completed', 'cancelled'));
@@ -0,0 +1,5 @@
+-- Ciclo de vida de viajes: agrega el estado "started"
+
+ALTER TABLE app.trips DROP CONSTRAINT IF EXISTS trips_status_check;
+ALTER TABLE app.trips ADD CONSTRAINT trips_status_check
+    CHECK (status IN ('requested', 'confirmed', 'started', 'completed', 'cancelled'));
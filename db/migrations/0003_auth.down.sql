@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS app.sessions;
+DROP TABLE IF EXISTS app.auth_codes;
+DROP INDEX IF EXISTS app.users_phone_key;
@@ -0,0 +1,22 @@
+-- Autenticación por teléfono: códigos de un solo uso y sesiones opacas
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_key ON app.users (phone);
+
+-- Un código vigente por teléfono (solo se guarda el hash)
+CREATE TABLE IF NOT EXISTS app.auth_codes (
+    phone       text PRIMARY KEY,
+    code_hash   text NOT NULL,
+    attempts    integer NOT NULL DEFAULT 0,
+    expires_at  timestamptz NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+-- Sesiones: token Bearer opaco, se guarda solo su sha256
+CREATE TABLE IF NOT EXISTS app.sessions (
+    token_hash  text PRIMARY KEY,
+    user_id     uuid NOT NULL REFERENCES app.users (id) ON DELETE CASCADE,
+    expires_at  timestamptz NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON app.sessions (user_id);
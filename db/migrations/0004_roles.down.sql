@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS app.route_drivers;
+ALTER TABLE app.users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE app.users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,18 @@
+-- Roles de usuario y asignación de conductores a rutas
+
+ALTER TABLE app.users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'passenger';
+ALTER TABLE app.users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE app.users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('passenger', 'driver', 'admin'));
+
+CREATE TABLE IF NOT EXISTS app.route_drivers (
+    route_id    uuid NOT NULL REFERENCES app.routes (id) ON DELETE CASCADE,
+    driver_id   uuid NOT NULL REFERENCES app.users (id) ON DELETE CASCADE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (route_id, driver_id)
+);
+
+CREATE INDEX IF NOT EXISTS route_drivers_driver_id_idx ON app.route_drivers (driver_id);
+
+-- El primer administrador se asigna a mano:
+-- UPDATE app.users SET role = 'admin' WHERE phone = '+51...';
@@ -0,0 +1,2 @@
+ALTER TABLE app.route_stops DROP CONSTRAINT IF EXISTS route_stops_coordinates_check;
+DROP INDEX IF EXISTS app.route_stops_route_order_key;
@@ -0,0 +1,11 @@
+-- Gestión de rutas y paradas desde la API de administración
+
+ALTER TABLE app.route_stops ADD COLUMN IF NOT EXISTS is_active boolean NOT NULL DEFAULT true;
+
+-- stop_order único por ruta; la API lo mantiene contiguo (1..n)
+CREATE UNIQUE INDEX IF NOT EXISTS route_stops_route_order_key
+    ON app.route_stops (route_id, stop_order);
+
+ALTER TABLE app.route_stops DROP CONSTRAINT IF EXISTS route_stops_coordinates_check;
+ALTER TABLE app.route_stops ADD CONSTRAINT route_stops_coordinates_check
+    CHECK (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180);
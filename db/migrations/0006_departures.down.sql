@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS app.trips_departure_id_idx;
+ALTER TABLE app.trips DROP COLUMN IF EXISTS departure_id;
+DROP TABLE IF EXISTS app.departures;
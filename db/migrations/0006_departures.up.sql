@@ -0,0 +1,20 @@
+-- Salidas programadas por ruta con capacidad de asientos.
+-- seats_booked nunca supera capacity: la reserva incrementa el contador con
+-- un UPDATE condicional y el CHECK protege ante cualquier otro camino.
+
+CREATE TABLE IF NOT EXISTS app.departures (
+    id            uuid PRIMARY KEY,
+    route_id      uuid NOT NULL REFERENCES app.routes (id),
+    departs_at    timestamptz NOT NULL,
+    capacity      integer NOT NULL CHECK (capacity > 0),
+    seats_booked  integer NOT NULL DEFAULT 0,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT departures_route_departs_at_key UNIQUE (route_id, departs_at),
+    CONSTRAINT departures_seats_check
+        CHECK (seats_booked >= 0 AND seats_booked <= capacity)
+);
+
+ALTER TABLE app.trips ADD COLUMN IF NOT EXISTS departure_id uuid REFERENCES app.departures (id);
+
+CREATE INDEX IF NOT EXISTS trips_departure_id_idx ON app.trips (departure_id);
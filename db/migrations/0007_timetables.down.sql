@@ -0,0 +1,6 @@
+ALTER TABLE app.departures DROP CONSTRAINT IF EXISTS departures_status_check;
+ALTER TABLE app.departures
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS vehicle_label,
+    DROP COLUMN IF EXISTS timetable_id;
+DROP TABLE IF EXISTS app.timetables;
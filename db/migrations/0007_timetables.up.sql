@@ -0,0 +1,29 @@
+-- Horarios recurrentes por ruta. Las salidas de cada fecha se generan a
+-- partir de los horarios la primera vez que se consultan.
+
+CREATE TABLE IF NOT EXISTS app.timetables (
+    id             uuid PRIMARY KEY,
+    route_id       uuid NOT NULL REFERENCES app.routes (id),
+    weekdays       integer[] NOT NULL,
+    times          text[] NOT NULL,
+    capacity       integer NOT NULL CHECK (capacity > 0),
+    vehicle_label  text NOT NULL DEFAULT '',
+    is_active      boolean NOT NULL DEFAULT true,
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT timetables_weekdays_check
+        CHECK (cardinality(weekdays) > 0 AND weekdays <@ ARRAY[1, 2, 3, 4, 5, 6, 7]),
+    CONSTRAINT timetables_times_check
+        CHECK (cardinality(times) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS timetables_route_id_idx ON app.timetables (route_id);
+
+ALTER TABLE app.departures
+    ADD COLUMN IF NOT EXISTS timetable_id uuid REFERENCES app.timetables (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS vehicle_label text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'scheduled';
+
+ALTER TABLE app.departures DROP CONSTRAINT IF EXISTS departures_status_check;
+ALTER TABLE app.departures ADD CONSTRAINT departures_status_check
+    CHECK (status IN ('scheduled', 'cancelled'));
@@ -0,0 +1,4 @@
+ALTER TABLE app.trips
+    DROP COLUMN IF EXISTS fare_rule_version,
+    DROP COLUMN IF EXISTS fare_rule_id;
+DROP TABLE IF EXISTS app.fare_rules;
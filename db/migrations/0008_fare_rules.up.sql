@@ -0,0 +1,21 @@
+-- Tarifas por tramo. Cada cambio es una versión nueva; los viajes guardan
+-- la versión con la que se calculó su precio.
+
+CREATE TABLE IF NOT EXISTS app.fare_rules (
+    id              uuid PRIMARY KEY,
+    route_id        uuid NOT NULL REFERENCES app.routes (id),
+    version         integer NOT NULL CHECK (version > 0),
+    kind            text NOT NULL,
+    base_cents      integer NOT NULL DEFAULT 0 CHECK (base_cents >= 0),
+    per_stop_cents  integer NOT NULL DEFAULT 0 CHECK (per_stop_cents >= 0),
+    per_km_cents    integer NOT NULL DEFAULT 0 CHECK (per_km_cents >= 0),
+    matrix          jsonb NOT NULL DEFAULT '[]',
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT fare_rules_route_version_key UNIQUE (route_id, version),
+    CONSTRAINT fare_rules_kind_check
+        CHECK (kind IN ('flat', 'per_stop', 'distance', 'matrix'))
+);
+
+ALTER TABLE app.trips
+    ADD COLUMN IF NOT EXISTS fare_rule_id uuid REFERENCES app.fare_rules (id),
+    ADD COLUMN IF NOT EXISTS fare_rule_version integer;
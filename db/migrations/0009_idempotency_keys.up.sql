@@ -0,0 +1,15 @@
+-- Respuestas guardadas por Idempotency-Key, por usuario
+
+CREATE TABLE IF NOT EXISTS app.idempotency_keys (
+    user_id        uuid NOT NULL REFERENCES app.users (id) ON DELETE CASCADE,
+    key            text NOT NULL,
+    request_hash   text NOT NULL,
+    status_code    integer NOT NULL DEFAULT 0,
+    content_type   text NOT NULL DEFAULT '',
+    response_body  bytea,
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    completed_at   timestamptz,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_at_idx ON app.idempotency_keys (created_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS app.trips_passenger_sort_idx;
@@ -0,0 +1,4 @@
+-- Historial de viajes por pasajero (GET /me/trips), ordenado por salida
+
+CREATE INDEX IF NOT EXISTS trips_passenger_sort_idx
+    ON app.trips (passenger_id, (COALESCE(scheduled_at, created_at)), id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS app.route_stops_location_idx;
@@ -0,0 +1,5 @@
+-- Búsqueda de paradas cercanas (GET /stops/nearby): prefiltro por rectángulo
+
+CREATE INDEX IF NOT EXISTS route_stops_location_idx
+    ON app.route_stops (latitude, longitude)
+    WHERE is_active = true;
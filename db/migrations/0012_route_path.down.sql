@@ -0,0 +1 @@
+ALTER TABLE app.routes DROP COLUMN IF EXISTS path_polyline;
@@ -0,0 +1,4 @@
+-- Trazado real de la ruta por las calles, como polilínea codificada
+-- (algoritmo de Google, 5 decimales). NULL = línea recta entre paradas
+
+ALTER TABLE app.routes ADD COLUMN IF NOT EXISTS path_polyline text;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS app.departures_vehicle_idx;
+DROP INDEX IF EXISTS app.departures_driver_idx;
+
+ALTER TABLE app.departures
+    DROP COLUMN IF EXISTS vehicle_id,
+    DROP COLUMN IF EXISTS driver_id;
+
+DROP TABLE IF EXISTS app.vehicles;
+DROP TABLE IF EXISTS app.drivers;
@@ -0,0 +1,35 @@
+-- Conductores, vehículos y su asignación a salidas.
+-- El perfil de conductor usa el id del usuario (rol driver), el mismo que
+-- app.route_drivers, para que departures.driver_id se compare directo.
+
+CREATE TABLE IF NOT EXISTS app.drivers (
+    user_id             uuid PRIMARY KEY REFERENCES app.users (id) ON DELETE CASCADE,
+    license_number      text NOT NULL,
+    license_expires_on  date NOT NULL,
+    is_active           boolean NOT NULL DEFAULT true,
+    created_at          timestamptz NOT NULL DEFAULT now(),
+    updated_at          timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS app.vehicles (
+    id                     uuid PRIMARY KEY,
+    plate                  text NOT NULL,
+    model                  text NOT NULL DEFAULT '',
+    capacity               integer NOT NULL CHECK (capacity > 0),
+    soat_expires_on        date NOT NULL,
+    inspection_expires_on  date NOT NULL,
+    is_active              boolean NOT NULL DEFAULT true,
+    created_at             timestamptz NOT NULL DEFAULT now(),
+    updated_at             timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT vehicles_plate_key UNIQUE (plate)
+);
+
+ALTER TABLE app.departures
+    ADD COLUMN IF NOT EXISTS driver_id uuid REFERENCES app.drivers (user_id),
+    ADD COLUMN IF NOT EXISTS vehicle_id uuid REFERENCES app.vehicles (id);
+
+-- Búsqueda de salidas cercanas del mismo conductor o vehículo al asignar
+CREATE INDEX IF NOT EXISTS departures_driver_idx
+    ON app.departures (driver_id, departs_at) WHERE driver_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS departures_vehicle_idx
+    ON app.departures (vehicle_id, departs_at) WHERE vehicle_id IS NOT NULL;
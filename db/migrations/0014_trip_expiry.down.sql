@@ -0,0 +1,8 @@
+-- Falla si quedan viajes en estado "no_show" o "expired"
+DROP INDEX IF EXISTS app.trips_pending_scheduled_at_idx;
+
+ALTER TABLE app.trips DROP COLUMN IF EXISTS status_reason;
+
+ALTER TABLE app.trips DROP CONSTRAINT IF EXISTS trips_status_check;
+ALTER TABLE app.trips ADD CONSTRAINT trips_status_check
+    CHECK (status IN ('requested', 'confirmed', 'started', 'completed', 'cancelled'));
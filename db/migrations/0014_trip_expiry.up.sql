@@ -0,0 +1,14 @@
+-- Viajes vencidos: "expired" (no se confirmó antes de la salida) y
+-- "no_show" (el pasajero no abordó). status_reason guarda el motivo del
+-- último cambio de estado automático.
+
+ALTER TABLE app.trips DROP CONSTRAINT IF EXISTS trips_status_check;
+ALTER TABLE app.trips ADD CONSTRAINT trips_status_check
+    CHECK (status IN ('requested', 'confirmed', 'started', 'completed', 'cancelled', 'no_show', 'expired'));
+
+ALTER TABLE app.trips ADD COLUMN IF NOT EXISTS status_reason text;
+
+-- El proceso de vencimiento busca viajes pendientes por hora de salida
+CREATE INDEX IF NOT EXISTS trips_pending_scheduled_at_idx
+    ON app.trips (scheduled_at)
+    WHERE status IN ('requested', 'confirmed');
@@ -0,0 +1,8 @@
+ALTER TABLE app.trips
+    DROP COLUMN IF EXISTS refund_cents,
+    DROP COLUMN IF EXISTS cancellation_fee_cents;
+
+ALTER TABLE app.routes DROP CONSTRAINT IF EXISTS routes_cancellation_policy_check;
+ALTER TABLE app.routes
+    DROP COLUMN IF EXISTS cancel_fee_percent,
+    DROP COLUMN IF EXISTS cancel_free_minutes;
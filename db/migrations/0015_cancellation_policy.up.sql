@@ -0,0 +1,20 @@
+-- Política de cancelación por ruta: gratis hasta cancel_free_minutes antes
+-- de la salida y con cargo de cancel_fee_percent del precio después.
+-- Cada viaje cancelado guarda el cargo y el reembolso calculados.
+
+ALTER TABLE app.routes
+    ADD COLUMN IF NOT EXISTS cancel_free_minutes integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cancel_fee_percent  integer NOT NULL DEFAULT 0;
+
+ALTER TABLE app.routes DROP CONSTRAINT IF EXISTS routes_cancellation_policy_check;
+ALTER TABLE app.routes ADD CONSTRAINT routes_cancellation_policy_check
+    CHECK (cancel_free_minutes >= 0 AND cancel_fee_percent BETWEEN 0 AND 100);
+
+ALTER TABLE app.trips
+    ADD COLUMN IF NOT EXISTS cancellation_fee_cents integer,
+    ADD COLUMN IF NOT EXISTS refund_cents           integer;
+
+-- Los viajes cancelados antes de esta migración no tuvieron cargo
+UPDATE app.trips
+SET cancellation_fee_cents = 0, refund_cents = price_cents
+WHERE status = 'cancelled' AND cancellation_fee_cents IS NULL;
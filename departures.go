@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+	"github.com/luisdev-dark/realgov3.git/worker"
+)
+
+const departuresUsage = `Uso: go run . departures <comando>
+
+Comandos:
+  schedule                          genera una vez las salidas de los horarios de
+                                    las rutas activas para los próximos días
+                                    (para cron o despliegues sin proceso permanente)`
+
+// runDepartures ejecuta los subcomandos de mantenimiento de salidas
+func runDepartures(args []string) {
+	if len(args) != 1 || args[0] != "schedule" {
+		fmt.Fprintln(os.Stderr, departuresUsage)
+		os.Exit(2)
+	}
+
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("Error conectando a la base de datos: %v", err)
+	}
+	defer db.CloseDB()
+
+	count, err := worker.ScheduleDepartures(context.Background(), repository.NewPostgres(db.GetDB()), time.Now())
+	if err != nil {
+		log.Fatalf("Error generando salidas: %v", err)
+	}
+	fmt.Printf("%d salidas de horarios revisadas para los próximos %d días\n", count, models.ScheduleDays)
+}
@@ -0,0 +1,52 @@
+// Package geo agrupa cálculos geográficos simples sobre coordenadas WGS84.
+package geo
+
+import "math"
+
+// EarthRadiusMeters es el radio medio de la Tierra
+const EarthRadiusMeters = 6371000.0
+
+// Distance retorna la distancia en metros entre dos puntos usando la
+// fórmula de haversine (suficiente para distancias urbanas)
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// Box es un rectángulo de coordenadas, usado para descartar rápido con
+// índices normales los puntos que no pueden estar dentro de un radio
+type Box struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+}
+
+// BoundingBox retorna el rectángulo que contiene el círculo de radiusMeters
+// alrededor de lat/lon. Cerca de los polos o del antimeridiano se amplía a
+// todas las longitudes.
+func BoundingBox(lat, lon, radiusMeters float64) Box {
+	dLat := radiusMeters / EarthRadiusMeters * 180 / math.Pi
+	box := Box{
+		MinLat: math.Max(lat-dLat, -90),
+		MaxLat: math.Min(lat+dLat, 90),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+	if box.MinLat > -90 && box.MaxLat < 90 {
+		dLon := dLat / math.Cos(lat*math.Pi/180)
+		if lon-dLon >= -180 && lon+dLon <= 180 {
+			box.MinLon, box.MaxLon = lon-dLon, lon+dLon
+		}
+	}
+	return box
+}
+
+// Contains indica si el punto está dentro del rectángulo
+func (b Box) Contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
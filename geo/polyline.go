@@ -0,0 +1,94 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// ErrInvalidPolyline indica que el texto no es una polilínea codificada válida
+var ErrInvalidPolyline = errors.New("polilínea inválida")
+
+// Point es una coordenada lat/lon
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// EncodePolyline codifica los puntos con el algoritmo de polilíneas de
+// Google (precisión de 5 decimales, ~1 m), el formato que entienden
+// directamente los SDK de mapas
+func EncodePolyline(points []Point) string {
+	var b strings.Builder
+	var prevLat, prevLon int64
+	for _, p := range points {
+		lat := int64(math.Round(p.Lat * 1e5))
+		lon := int64(math.Round(p.Lon * 1e5))
+		encodeValue(&b, lat-prevLat)
+		encodeValue(&b, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+	return b.String()
+}
+
+func encodeValue(b *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		b.WriteByte(byte(0x20|u&0x1f) + 63)
+		u >>= 5
+	}
+	b.WriteByte(byte(u) + 63)
+}
+
+// DecodePolyline decodifica una polilínea de EncodePolyline. Retorna
+// ErrInvalidPolyline si el texto está truncado, tiene caracteres fuera del
+// formato o coordenadas fuera de rango.
+func DecodePolyline(s string) ([]Point, error) {
+	var points []Point
+	var lat, lon int64
+	for i := 0; i < len(s); {
+		dLat, n, ok := decodeValue(s[i:])
+		if !ok {
+			return nil, ErrInvalidPolyline
+		}
+		i += n
+		dLon, n, ok := decodeValue(s[i:])
+		if !ok {
+			return nil, ErrInvalidPolyline
+		}
+		i += n
+
+		lat += dLat
+		lon += dLon
+		p := Point{Lat: float64(lat) / 1e5, Lon: float64(lon) / 1e5}
+		if p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+			return nil, ErrInvalidPolyline
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// decodeValue lee un valor del inicio de s y retorna cuántos bytes usó
+func decodeValue(s string) (int64, int, bool) {
+	var u uint64
+	for i := 0; i < len(s) && i < 12; i++ {
+		c := s[i]
+		if c < 63 || c > 126 {
+			return 0, 0, false
+		}
+		chunk := uint64(c - 63)
+		u |= (chunk & 0x1f) << (5 * i)
+		if chunk < 0x20 {
+			v := int64(u >> 1)
+			if u&1 != 0 {
+				v = ^v
+			}
+			return v, i + 1, true
+		}
+	}
+	return 0, 0, false
+}
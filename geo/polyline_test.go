@@ -0,0 +1,70 @@
+package geo_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/luisdev-dark/realgov3.git/geo"
+)
+
+func TestPolyline(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []geo.Point
+		encoded string
+	}{
+		// Ejemplo de la documentación de Google
+		{"referencia", []geo.Point{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}, "_p~iF~ps|U_ulLnnqC_mqNvxq`@"},
+		{"deltas negativos", []geo.Point{{-12.0464, -77.0428}, {-12.0502, -77.0401}, {-12.0464, -77.0428}}, "~xohAnlfuMvV{OwVzO"},
+		{"origen", []geo.Point{{0, 0}}, "??"},
+		{"extremos", []geo.Point{{90, 180}, {-90, -180}}, "_cidP_gsia@~fsia@~ngtcA"},
+		{"vacía", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := geo.EncodePolyline(tt.points); got != tt.encoded {
+				t.Errorf("EncodePolyline = %q, se esperaba %q", got, tt.encoded)
+			}
+			got, err := geo.DecodePolyline(tt.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.points) {
+				t.Errorf("DecodePolyline = %v, se esperaba %v", got, tt.points)
+			}
+		})
+	}
+}
+
+func TestPolylineRounding(t *testing.T) {
+	// La precisión es de 5 decimales
+	got, err := geo.DecodePolyline(geo.EncodePolyline([]geo.Point{{-12.046449, -77.042851}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []geo.Point{{-12.04645, -77.04285}}; !slices.Equal(got, want) {
+		t.Fatalf("puntos = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestDecodePolylineInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		encoded string
+	}{
+		{"truncada a mitad de valor", "_p~iF~ps|U_ulLnnqC_mqNvxq"},
+		{"sin longitud", "_p~iF"},
+		{"carácter fuera del formato", "_p~iF ps|U"},
+		{"latitud fuera de rango", geo.EncodePolyline([]geo.Point{{90.00001, 0}})},
+		{"longitud fuera de rango", geo.EncodePolyline([]geo.Point{{0, -180.00001}})},
+		{"fuera de rango por acumulación", geo.EncodePolyline([]geo.Point{{80, 0}}) + geo.EncodePolyline([]geo.Point{{20, 0}})},
+		{"valor demasiado largo", "~~~~~~~~~~~~~~?"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := geo.DecodePolyline(tt.encoded); !errors.Is(err, geo.ErrInvalidPolyline) {
+				t.Fatalf("err = %v, se esperaba ErrInvalidPolyline", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/gtfs"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+const gtfsUsage = `Uso: go run . gtfs <comando>
+
+Comandos:
+  export [-o gtfs.zip] [-days 60]   genera el feed GTFS estático ("-o -" escribe a stdout)
+  import [-dry-run] [-activate] [-price 0] [-currency PEN] feed.zip
+                                    crea o actualiza rutas y paradas desde un feed`
+
+// runGTFS ejecuta los subcomandos gtfs export y gtfs import
+func runGTFS(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, gtfsUsage)
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "export":
+		runGTFSExport(args[1:])
+	case "import":
+		runGTFSImport(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, gtfsUsage)
+		os.Exit(2)
+	}
+}
+
+func runGTFSExport(args []string) {
+	flags := flag.NewFlagSet("gtfs export", flag.ExitOnError)
+	output := flags.String("o", "gtfs.zip", "archivo de salida")
+	days := flags.Int("days", gtfs.DefaultDays, "días cubiertos desde hoy")
+	flags.Parse(args)
+
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("Error conectando a la base de datos: %v", err)
+	}
+	defer db.CloseDB()
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Error creando %s: %v", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	err := gtfs.Export(context.Background(), repository.NewPostgres(db.GetDB()), w, gtfs.Options{
+		Agency: gtfs.AgencyFromEnv(),
+		Start:  time.Now(),
+		Days:   *days,
+	})
+	if err != nil {
+		log.Fatalf("Error generando feed GTFS: %v", err)
+	}
+	if *output != "-" {
+		fmt.Printf("Feed GTFS escrito en %s\n", *output)
+	}
+}
+
+func runGTFSImport(args []string) {
+	flags := flag.NewFlagSet("gtfs import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "mostrar los cambios sin guardarlos")
+	activate := flags.Bool("activate", false, "publicar las rutas nuevas")
+	price := flags.Int("price", 0, "base_price_cents de las rutas nuevas")
+	currency := flags.String("currency", "PEN", "moneda de las rutas nuevas")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, gtfsUsage)
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Error abriendo %s: %v", flags.Arg(0), err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		log.Fatalf("Error leyendo %s: %v", flags.Arg(0), err)
+	}
+	routes, err := gtfs.ReadFeed(file, info.Size())
+	if err != nil {
+		log.Fatalf("Error leyendo feed: %v", err)
+	}
+
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("Error conectando a la base de datos: %v", err)
+	}
+	defer db.CloseDB()
+
+	result, err := gtfs.Import(context.Background(), repository.NewPostgres(db.GetDB()), routes, gtfs.ImportOptions{
+		DryRun:         *dryRun,
+		Activate:       *activate,
+		BasePriceCents: *price,
+		Currency:       strings.ToUpper(*currency),
+	})
+	if err != nil {
+		log.Fatalf("Error importando feed: %v", err)
+	}
+
+	fmt.Print(result.Diff())
+	if result.DryRun {
+		fmt.Printf("Dry-run: %d cambios sin aplicar (%d rutas, %d paradas en el feed)\n", len(result.Changes), result.Routes, result.Stops)
+	} else {
+		fmt.Printf("%d cambios aplicados (%d rutas, %d paradas en el feed)\n", len(result.Changes), result.Routes, result.Stops)
+	}
+}
@@ -0,0 +1,215 @@
+// Package gtfs genera el feed GTFS estático (https://gtfs.org/schedule/) de
+// las rutas activas: agency, routes, stops, trips, stop_times, calendar y,
+// si hace falta, calendar_dates.
+//
+// Cada viaje del feed es una hora de un horario (o una salida puntual) con
+// su propio service_id, así las salidas canceladas se pueden quitar fecha por
+// fecha con calendar_dates sin afectar las otras horas del horario.
+//
+// ReadFeed e Import hacen el camino inverso: crean o actualizan rutas y
+// paradas a partir de un feed.
+package gtfs
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+const (
+	// DefaultDays es cuántos días cubre el feed desde la fecha de inicio,
+	// igual que el límite de salidas programables
+	DefaultDays = 60
+
+	// averageSpeed es la velocidad media usada para estimar la hora de paso
+	// por cada parada (~20 km/h), en metros por segundo
+	averageSpeed = 20000.0 / 3600.0
+
+	// routeTypeBus es route_type 3 (bus)
+	routeTypeBus = "3"
+)
+
+// Agency son los datos de agency.txt
+type Agency struct {
+	ID       string
+	Name     string
+	URL      string
+	Timezone string
+	Lang     string
+}
+
+// AgencyFromEnv lee la agencia de GTFS_AGENCY_NAME, GTFS_AGENCY_URL y
+// GTFS_AGENCY_LANG, con valores por defecto para desarrollo
+func AgencyFromEnv() Agency {
+	agency := Agency{
+		ID:       "realgo",
+		Name:     os.Getenv("GTFS_AGENCY_NAME"),
+		URL:      os.Getenv("GTFS_AGENCY_URL"),
+		Timezone: "America/Lima", // nombre IANA de models.LocalZone
+		Lang:     os.Getenv("GTFS_AGENCY_LANG"),
+	}
+	if agency.Name == "" {
+		agency.Name = "RealGo"
+	}
+	if agency.URL == "" {
+		agency.URL = "http://localhost:8080"
+	}
+	if agency.Lang == "" {
+		agency.Lang = "es"
+	}
+	return agency
+}
+
+// Options configura la exportación
+type Options struct {
+	Agency Agency
+	// Start es el primer día del feed (se usan año, mes y día en LocalZone)
+	Start time.Time
+	// Days es la cantidad de días cubiertos; 0 usa DefaultDays
+	Days int
+}
+
+// RouteSource son los datos de una ruta usados para el feed
+type RouteSource struct {
+	Route models.Route
+	// Stops son las paradas activas ordenadas por stop_order
+	Stops []models.RouteStop
+	// Timetables incluye los horarios inactivos (sus salidas ya generadas
+	// siguen vigentes)
+	Timetables []models.Timetable
+	// Departures son las salidas guardadas dentro del rango del feed
+	Departures []models.Departure
+}
+
+// Load lee de los repositorios las rutas activas con sus paradas, horarios
+// y salidas entre start y start + days
+func Load(ctx context.Context, repos *repository.Repositories, start time.Time, days int) ([]RouteSource, error) {
+	from, to := window(start, days)
+
+	routes, err := repos.Routes.ListActive(ctx, repository.RouteFilter{Sort: models.RouteSortName})
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]RouteSource, 0, len(routes))
+	for _, route := range routes {
+		stops, err := repos.Stops.ListByRoute(ctx, route.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		timetables, err := repos.Timetables.ListByRoute(ctx, route.ID)
+		if err != nil {
+			return nil, err
+		}
+		departures, err := repos.Departures.ListByRoute(ctx, route.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, RouteSource{
+			Route:      route,
+			Stops:      stops,
+			Timetables: timetables,
+			Departures: departures,
+		})
+	}
+	return sources, nil
+}
+
+// window retorna el rango [from, to) de fechas del feed en LocalZone
+func window(start time.Time, days int) (time.Time, time.Time) {
+	if days <= 0 {
+		days = DefaultDays
+	}
+	start = start.In(models.LocalZone)
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, models.LocalZone)
+	return from, from.AddDate(0, 0, days)
+}
+
+// service es un viaje del feed: una hora fija que corre en ciertas fechas
+type service struct {
+	id       string
+	route    *RouteSource
+	clock    time.Duration // hora de salida desde medianoche
+	headsign string
+	// weekdays es el patrón del horario (ISO 1..7); vacío en salidas puntuales
+	weekdays []int
+	dates    map[string]bool // fechas YYYYMMDD en que corre
+}
+
+// services arma los viajes de una ruta: las salidas que se ven en
+// GET /routes/{id}/departures para cada día del rango, agrupadas por
+// horario y hora
+func services(source *RouteSource, from, to time.Time) []*service {
+	byKey := map[string]*service{}
+	timetables := map[uuid.UUID]*models.Timetable{}
+	for i := range source.Timetables {
+		timetables[source.Timetables[i].ID] = &source.Timetables[i]
+	}
+
+	// Una ruta sale una sola vez a cada hora (UNIQUE route_id, departs_at)
+	taken := map[int64]bool{}
+	for _, d := range source.Departures {
+		taken[d.DepartsAt.Unix()] = true
+	}
+
+	add := func(d models.Departure) {
+		local := d.DepartsAt.In(models.LocalZone)
+		midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, models.LocalZone)
+
+		key := "dep-" + d.ID.String()
+		var weekdays []int
+		if d.TimetableID != nil {
+			key = d.TimetableID.String() + "-" + local.Format("1504")
+			if t, ok := timetables[*d.TimetableID]; ok && t.IsActive {
+				weekdays = t.Weekdays
+			}
+		}
+		s, ok := byKey[key]
+		if !ok {
+			s = &service{
+				id:       key,
+				route:    source,
+				clock:    local.Sub(midnight),
+				headsign: source.Route.DestinationName,
+				weekdays: weekdays,
+				dates:    map[string]bool{},
+			}
+			byKey[key] = s
+		}
+		s.dates[local.Format("20060102")] = true
+	}
+
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		// Las salidas guardadas tienen prioridad sobre las que generaría el horario
+		for i := range source.Timetables {
+			for _, d := range source.Timetables[i].DeparturesOn(date) {
+				if !taken[d.DepartsAt.Unix()] {
+					taken[d.DepartsAt.Unix()] = true
+					add(d)
+				}
+			}
+		}
+	}
+	for _, d := range source.Departures {
+		if d.Status == models.DepartureStatusScheduled {
+			add(d)
+		}
+	}
+
+	list := make([]*service, 0, len(byKey))
+	for _, s := range byKey {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].clock != list[j].clock {
+			return list[i].clock < list[j].clock
+		}
+		return list[i].id < list[j].id
+	})
+	return list
+}
@@ -0,0 +1,89 @@
+package gtfs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// fixture es una ruta con tres paradas y un horario de lunes a viernes
+type fixture struct {
+	repos     *repository.Repositories
+	route     models.Route
+	stops     []models.RouteStop
+	timetable models.Timetable
+}
+
+// newFixture carga la ruta en repositorios en memoria
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &fixture{repos: repository.NewMemory()}
+
+	f.route = models.Route{
+		Name: "Ruta 1", IsActive: true, Currency: "PEN", BasePriceCents: 250,
+		OriginName: "Centro", OriginLat: -12.0464, OriginLon: -77.0428,
+		DestinationName: "Norte", DestinationLat: -12.0264, DestinationLon: -77.0428,
+	}
+	if err := f.repos.Routes.Create(ctx, &f.route); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"Centro", "Plaza", "Norte"} {
+		stop := models.RouteStop{RouteID: f.route.ID, Name: name, Latitude: -12.0464 + float64(i)*0.01, Longitude: -77.0428}
+		if err := f.repos.Stops.Create(ctx, &stop); err != nil {
+			t.Fatal(err)
+		}
+		f.stops = append(f.stops, stop)
+	}
+
+	f.timetable = models.Timetable{
+		RouteID: f.route.ID, Weekdays: []int{1, 2, 3, 4, 5}, Times: []string{"06:00", "07:30"},
+		Capacity: 15, IsActive: true,
+	}
+	if err := f.repos.Timetables.Create(ctx, &f.timetable); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// departure guarda una salida de la ruta a la hora local dada
+func (f *fixture) departure(t *testing.T, at time.Time, timetable bool, status string) models.Departure {
+	t.Helper()
+	d := models.Departure{RouteID: f.route.ID, DepartsAt: at, Capacity: 15, Status: status}
+	if timetable {
+		d.TimetableID = &f.timetable.ID
+	}
+	if err := f.repos.Departures.Create(context.Background(), &d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// readZip retorna las filas de cada archivo del zip, sin el encabezado
+func readZip(t *testing.T, data []byte) map[string][][]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][][]string{}
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(rc).ReadAll()
+		rc.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", file.Name, err)
+		}
+		files[file.Name] = rows[1:]
+	}
+	return files
+}
@@ -0,0 +1,280 @@
+package gtfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// Acciones de Change
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionActivate   = "activate"
+	ActionDeactivate = "deactivate"
+	ActionReorder    = "reorder"
+)
+
+// ImportOptions configura la importación
+type ImportOptions struct {
+	// DryRun calcula los cambios sin guardarlos
+	DryRun bool
+	// Activate publica las rutas nuevas; si es false se crean inactivas
+	Activate bool
+	// BasePriceCents y Currency se usan solo al crear rutas
+	BasePriceCents int
+	Currency       string
+}
+
+// Change es un cambio que aplica (o aplicaría, en dry-run) la importación
+type Change struct {
+	Action  string        `json:"action"`
+	Entity  string        `json:"entity"` // route, stop
+	ID      uuid.UUID     `json:"id"`
+	RouteID uuid.UUID     `json:"route_id"`
+	GTFSID  string        `json:"gtfs_id,omitempty"`
+	Name    string        `json:"name"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange es el valor anterior y el nuevo de un campo
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ImportResult resume una importación
+type ImportResult struct {
+	DryRun  bool     `json:"dry_run"`
+	Routes  int      `json:"routes"` // rutas en el feed
+	Stops   int      `json:"stops"`  // paradas en el feed
+	Changes []Change `json:"changes"`
+}
+
+// Diff retorna los cambios como texto, una línea por cambio
+func (r *ImportResult) Diff() string {
+	if len(r.Changes) == 0 {
+		return "Sin cambios\n"
+	}
+	symbols := map[string]string{
+		ActionCreate:     "+",
+		ActionUpdate:     "~",
+		ActionActivate:   "+",
+		ActionDeactivate: "-",
+		ActionReorder:    "↕",
+	}
+	var b strings.Builder
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "%s %s %s %q (%s)", symbols[c.Action], c.Action, c.Entity, c.Name, c.ID)
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "\n    %s: %q → %q", f.Field, f.From, f.To)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Import crea o actualiza rutas y paradas según el feed. Es idempotente:
+// los ids se derivan de los ids del feed, así que reimportar el mismo feed
+// no produce cambios.
+//
+// Por ruta: el nombre se toma del feed; origen y destino se completan con la
+// primera y última parada solo al crear la ruta, y precio, moneda e
+// is_active no se tocan al actualizar. Las paradas del feed se crean,
+// actualizan o reactivan y quedan en el orden del feed; las que ya no están
+// en el feed se desactivan (no se borran porque pueden tener viajes).
+//
+// Cada ruta se guarda con varias operaciones; si algo falla a mitad de
+// camino, volver a importar completa los cambios pendientes.
+func Import(ctx context.Context, repos *repository.Repositories, routes []FeedRoute, opts ImportOptions) (*ImportResult, error) {
+	if opts.Currency == "" {
+		opts.Currency = "PEN"
+	}
+	result := &ImportResult{DryRun: opts.DryRun, Routes: len(routes), Changes: []Change{}}
+	for i := range routes {
+		result.Stops += len(routes[i].Stops)
+		changes, err := importRoute(ctx, repos, &routes[i], opts)
+		if err != nil {
+			return nil, fmt.Errorf("ruta %s: %w", routes[i].GTFSID, err)
+		}
+		result.Changes = append(result.Changes, changes...)
+	}
+	return result, nil
+}
+
+func importRoute(ctx context.Context, repos *repository.Repositories, feed *FeedRoute, opts ImportOptions) ([]Change, error) {
+	var changes []Change
+
+	route, err := repos.Routes.Get(ctx, feed.ID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		first, last := feed.Stops[0], feed.Stops[len(feed.Stops)-1]
+		route = &models.Route{
+			ID:              feed.ID,
+			Name:            feed.Name,
+			IsActive:        opts.Activate,
+			OriginName:      first.Name,
+			OriginLat:       first.Latitude,
+			OriginLon:       first.Longitude,
+			DestinationName: last.Name,
+			DestinationLat:  last.Latitude,
+			DestinationLon:  last.Longitude,
+			BasePriceCents:  opts.BasePriceCents,
+			Currency:        opts.Currency,
+		}
+		changes = append(changes, Change{
+			Action: ActionCreate, Entity: "route", ID: route.ID, RouteID: route.ID, GTFSID: feed.GTFSID, Name: route.Name,
+		})
+		if !opts.DryRun {
+			if err := repos.Routes.Create(ctx, route); err != nil {
+				return nil, err
+			}
+		}
+
+	case err != nil:
+		return nil, err
+
+	case route.Name != feed.Name:
+		changes = append(changes, Change{
+			Action: ActionUpdate, Entity: "route", ID: route.ID, RouteID: route.ID, GTFSID: feed.GTFSID, Name: feed.Name,
+			Fields: []FieldChange{{Field: "name", From: route.Name, To: feed.Name}},
+		})
+		route.Name = feed.Name
+		if !opts.DryRun {
+			if err := repos.Routes.Update(ctx, route); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Paradas actuales (en dry-run de una ruta nueva no hay)
+	var current []models.RouteStop
+	if len(changes) == 0 || changes[0].Action != ActionCreate || !opts.DryRun {
+		current, err = repos.Stops.ListByRoute(ctx, route.ID, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	existing := map[uuid.UUID]models.RouteStop{}
+	for _, stop := range current {
+		existing[stop.ID] = stop
+	}
+
+	// Los stop_id de un feed propio ya son ids de RouteStop. Se reusan solo
+	// si son paradas de esta ruta: el uuid de una parada de otra ruta, o una
+	// parada repetida en una ruta circular, chocaría al crearla.
+	reused := map[uuid.UUID]bool{}
+	for i := range feed.Stops {
+		id, err := uuid.Parse(feed.Stops[i].GTFSID)
+		if _, ok := existing[id]; err == nil && ok && !reused[id] {
+			feed.Stops[i].ID = id
+			reused[id] = true
+		}
+	}
+
+	// Orden resultante antes de reordenar: las actuales y luego las nuevas
+	order := make([]uuid.UUID, 0, len(current)+len(feed.Stops))
+	for _, stop := range current {
+		order = append(order, stop.ID)
+	}
+
+	inFeed := map[uuid.UUID]bool{}
+	for _, fs := range feed.Stops {
+		inFeed[fs.ID] = true
+		stop, ok := existing[fs.ID]
+		if !ok {
+			changes = append(changes, Change{
+				Action: ActionCreate, Entity: "stop", ID: fs.ID, RouteID: route.ID, GTFSID: fs.GTFSID, Name: fs.Name,
+				Fields: []FieldChange{{Field: "coordinates", To: formatLatLon(fs.Latitude, fs.Longitude)}},
+			})
+			order = append(order, fs.ID)
+			if !opts.DryRun {
+				created := &models.RouteStop{ID: fs.ID, RouteID: route.ID, Name: fs.Name, Latitude: fs.Latitude, Longitude: fs.Longitude}
+				if err := repos.Stops.Create(ctx, created); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		var fields []FieldChange
+		if stop.Name != fs.Name {
+			fields = append(fields, FieldChange{Field: "name", From: stop.Name, To: fs.Name})
+		}
+		if stop.Latitude != fs.Latitude || stop.Longitude != fs.Longitude {
+			fields = append(fields, FieldChange{
+				Field: "coordinates",
+				From:  formatLatLon(stop.Latitude, stop.Longitude),
+				To:    formatLatLon(fs.Latitude, fs.Longitude),
+			})
+		}
+		if len(fields) > 0 {
+			changes = append(changes, Change{
+				Action: ActionUpdate, Entity: "stop", ID: fs.ID, RouteID: route.ID, GTFSID: fs.GTFSID, Name: fs.Name, Fields: fields,
+			})
+			if !opts.DryRun {
+				stop.Name, stop.Latitude, stop.Longitude = fs.Name, fs.Latitude, fs.Longitude
+				if err := repos.Stops.Update(ctx, &stop); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if !stop.IsActive {
+			changes = append(changes, Change{
+				Action: ActionActivate, Entity: "stop", ID: fs.ID, RouteID: route.ID, GTFSID: fs.GTFSID, Name: fs.Name,
+			})
+			if !opts.DryRun {
+				if _, err := repos.Stops.SetActive(ctx, route.ID, fs.ID, true); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// Las paradas que ya no están en el feed se desactivan y van al final
+	wanted := make([]uuid.UUID, 0, len(order))
+	for _, fs := range feed.Stops {
+		wanted = append(wanted, fs.ID)
+	}
+	for _, stop := range current {
+		if inFeed[stop.ID] {
+			continue
+		}
+		wanted = append(wanted, stop.ID)
+		if stop.IsActive {
+			changes = append(changes, Change{
+				Action: ActionDeactivate, Entity: "stop", ID: stop.ID, RouteID: route.ID, Name: stop.Name,
+			})
+			if !opts.DryRun {
+				if _, err := repos.Stops.SetActive(ctx, route.ID, stop.ID, false); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if !slices.Equal(order, wanted) {
+		changes = append(changes, Change{
+			Action: ActionReorder, Entity: "route", ID: route.ID, RouteID: route.ID, GTFSID: feed.GTFSID, Name: route.Name,
+			Fields: []FieldChange{{Field: "stops", From: strconv.Itoa(len(current)), To: strconv.Itoa(len(wanted))}},
+		})
+		if !opts.DryRun {
+			if _, err := repos.Stops.Reorder(ctx, route.ID, wanted); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return changes, nil
+}
+
+func formatLatLon(lat, lon float64) string {
+	return strconv.FormatFloat(lat, 'f', 6, 64) + "," + strconv.FormatFloat(lon, 'f', 6, 64)
+}
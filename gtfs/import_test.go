@@ -0,0 +1,202 @@
+package gtfs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/gtfs"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// writeZip arma un zip con los archivos dados
+func writeZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// feed arma un feed con una ruta R1 cuyo único viaje pasa por stopTimes
+func feed(routeName, stops, stopTimes string) map[string]string {
+	return map[string]string{
+		"routes.txt":     "route_id,route_short_name,route_type\nR1," + routeName + ",3\n",
+		"stops.txt":      "stop_id,stop_name,stop_lat,stop_lon\n" + stops,
+		"trips.txt":      "route_id,service_id,trip_id\nR1,S,T1\n",
+		"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" + stopTimes,
+	}
+}
+
+func importZip(t *testing.T, repos *repository.Repositories, data []byte, opts gtfs.ImportOptions) []gtfs.Change {
+	t.Helper()
+	routes, err := gtfs.ReadFeed(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := gtfs.Import(context.Background(), repos, routes, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Changes
+}
+
+// actions resume los cambios como "acción entidad nombre"
+func actions(changes []gtfs.Change) []string {
+	list := make([]string, 0, len(changes))
+	for _, c := range changes {
+		list = append(list, c.Action+" "+c.Entity+" "+c.Name)
+	}
+	return list
+}
+
+func assertActions(t *testing.T, changes []gtfs.Change, want ...string) {
+	t.Helper()
+	if got := actions(changes); !slices.Equal(got, want) {
+		t.Fatalf("cambios = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	f := newFixture(t)
+
+	var buf bytes.Buffer
+	start := time.Date(2026, time.March, 2, 0, 0, 0, 0, models.LocalZone)
+	if err := gtfs.Export(context.Background(), f.repos, &buf, gtfs.Options{Agency: gtfs.AgencyFromEnv(), Start: start, Days: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	// El feed propio usa los ids de ruta y parada, así que no hay cambios
+	assertActions(t, importZip(t, f.repos, buf.Bytes(), gtfs.ImportOptions{}))
+	stops, err := f.repos.Stops.ListByRoute(context.Background(), f.route.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stops) != len(f.stops) {
+		t.Fatalf("la ruta tiene %d paradas, se esperaban %d", len(stops), len(f.stops))
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+
+	original := writeZip(t, feed("Ruta 1",
+		"A,Centro,-12.0464,-77.0428\nB,Plaza,-12.0364,-77.0428\nC,Norte,-12.0264,-77.0428\n",
+		"T1,06:00:00,06:00:00,A,1\nT1,06:05:00,06:05:00,B,2\nT1,06:10:00,06:10:00,C,3\n"))
+
+	// En dry-run se informan los cambios sin guardarlos
+	opts := gtfs.ImportOptions{DryRun: true, Activate: true, BasePriceCents: 250}
+	assertActions(t, importZip(t, repos, original, opts),
+		"create route Ruta 1", "create stop Centro", "create stop Plaza", "create stop Norte")
+	if routes, _ := repos.Routes.ListActive(ctx, repository.RouteFilter{}); len(routes) != 0 {
+		t.Fatalf("el dry-run creó %d rutas", len(routes))
+	}
+
+	opts.DryRun = false
+	changes := importZip(t, repos, original, opts)
+	assertActions(t, changes,
+		"create route Ruta 1", "create stop Centro", "create stop Plaza", "create stop Norte")
+	route, err := repos.Routes.Get(ctx, changes[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !route.IsActive || route.BasePriceCents != 250 || route.OriginName != "Centro" || route.DestinationName != "Norte" {
+		t.Fatalf("ruta importada = %+v", route)
+	}
+	assertActions(t, importZip(t, repos, original, opts))
+
+	// Nombre nuevo, B se mueve, C sale del feed y entra D
+	changed := writeZip(t, feed("Ruta 1 Express",
+		"A,Centro,-12.0464,-77.0428\nB,Plaza,-12.0365,-77.0428\nC,Norte,-12.0264,-77.0428\nD,Estadio,-12.0164,-77.0428\n",
+		"T1,06:00:00,06:00:00,A,1\nT1,06:05:00,06:05:00,B,2\nT1,06:15:00,06:15:00,D,3\n"))
+	assertActions(t, importZip(t, repos, changed, opts),
+		"update route Ruta 1 Express", "update stop Plaza", "create stop Estadio", "deactivate stop Norte", "reorder route Ruta 1 Express")
+	assertActions(t, importZip(t, repos, changed, opts))
+
+	stops, err := repos.Stops.ListByRoute(ctx, route.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, stop := range stops {
+		if stop.IsActive {
+			names = append(names, stop.Name)
+		}
+	}
+	if len(stops) != 4 || len(names) != 3 || names[2] != "Estadio" {
+		t.Fatalf("paradas = %+v", stops)
+	}
+
+	// Al volver al feed original la parada desactivada se reactiva
+	assertActions(t, importZip(t, repos, original, opts),
+		"update route Ruta 1", "update stop Plaza", "activate stop Norte", "deactivate stop Estadio", "reorder route Ruta 1")
+}
+
+func TestImportForeignStopUUID(t *testing.T) {
+	f := newFixture(t)
+
+	// Un stop_id que es el uuid de una parada de otra ruta no se reusa
+	foreign := f.stops[1].ID.String()
+	data := writeZip(t, feed("Ruta 9",
+		"A,Centro,-12.0464,-77.0428\n"+foreign+",Plaza,-12.0364,-77.0428\n",
+		"T1,06:00:00,06:00:00,A,1\nT1,06:05:00,06:05:00,"+foreign+",2\n"))
+	changes := importZip(t, f.repos, data, gtfs.ImportOptions{})
+	assertActions(t, changes, "create route Ruta 9", "create stop Centro", "create stop Plaza")
+	if changes[2].ID == f.stops[1].ID {
+		t.Fatal("la parada importada tomó el id de una parada de otra ruta")
+	}
+
+	stop, err := f.repos.Stops.Get(context.Background(), f.stops[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stop.RouteID != f.route.ID {
+		t.Fatalf("la parada %s pasó a la ruta %s", stop.ID, stop.RouteID)
+	}
+	assertActions(t, importZip(t, f.repos, data, gtfs.ImportOptions{}))
+}
+
+func TestReadFeedInvalid(t *testing.T) {
+	stops := "A,Centro,-12.0464,-77.0428\nB,Plaza,-12.0364,-77.0428\n"
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"sin stop_times", map[string]string{"routes.txt": "route_id,route_short_name\nR1,Ruta 1\n"}},
+		{"stop_times sin stop_sequence", feed("Ruta 1", stops, "T1,06:00:00,06:00:00,A\n")},
+		{"stop_sequence no numérico", feed("Ruta 1", stops, "T1,06:00:00,06:00:00,A,uno\nT1,06:05:00,06:05:00,B,2\n")},
+		{"stop_sequence repetido", feed("Ruta 1", stops, "T1,06:00:00,06:00:00,A,1\nT1,06:05:00,06:05:00,B,1\n")},
+		{"parada desconocida", feed("Ruta 1", stops, "T1,06:00:00,06:00:00,A,1\nT1,06:05:00,06:05:00,Z,2\n")},
+		{"comillas sin cerrar", feed("Ruta 1", stops, "T1,\"06:00:00,06:00:00,A,1\n")},
+		{"coordenadas inválidas", feed("Ruta 1", "A,Centro,-120,-77\n", "T1,06:00:00,06:00:00,A,1\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := writeZip(t, tt.files)
+			_, err := gtfs.ReadFeed(bytes.NewReader(data), int64(len(data)))
+			if !errors.Is(err, gtfs.ErrInvalidFeed) {
+				t.Fatalf("err = %v, se esperaba ErrInvalidFeed", err)
+			}
+		})
+	}
+
+	if _, err := gtfs.ReadFeed(bytes.NewReader([]byte("no es un zip")), 12); !errors.Is(err, gtfs.ErrInvalidFeed) {
+		t.Fatalf("err = %v, se esperaba ErrInvalidFeed", err)
+	}
+}
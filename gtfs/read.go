@@ -0,0 +1,277 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+// ErrInvalidFeed indica que el zip no es un feed GTFS que se pueda importar
+var ErrInvalidFeed = errors.New("feed GTFS inválido")
+
+// importNamespace genera ids estables (uuid v5) a partir de los ids del
+// feed, para que reimportar el mismo feed actualice los mismos registros
+var importNamespace = uuid.MustParse("6c2cdb87-1121-4172-9361-076e239d59ff")
+
+// FeedRoute es una ruta leída de un feed, con las paradas de su recorrido
+// más largo en orden de stop_sequence
+type FeedRoute struct {
+	GTFSID string
+	ID     uuid.UUID
+	Name   string
+	Stops  []FeedStop
+}
+
+// FeedStop es una parada de FeedRoute. ID se deriva del stop_id; Import lo
+// reemplaza por el stop_id cuando este es el uuid de una parada de la ruta.
+type FeedStop struct {
+	GTFSID    string
+	ID        uuid.UUID
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+// ReadFeed lee routes.txt, stops.txt, trips.txt y stop_times.txt de un zip.
+// Cada ruta toma el orden de paradas de su viaje con más paradas (de
+// preferencia con direction_id 0). Las rutas sin viajes se omiten.
+func ReadFeed(r io.ReaderAt, size int64) ([]FeedRoute, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no es un zip: %v", ErrInvalidFeed, err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		// Algunos feeds traen los archivos dentro de una carpeta
+		name := f.Name[strings.LastIndex(f.Name, "/")+1:]
+		files[name] = f
+	}
+	for _, name := range []string{"routes.txt", "stops.txt", "trips.txt", "stop_times.txt"} {
+		if files[name] == nil {
+			return nil, fmt.Errorf("%w: falta %s", ErrInvalidFeed, name)
+		}
+	}
+
+	// routes.txt
+	names := map[string]string{}
+	var routeIDs []string
+	err = readCSV(files["routes.txt"], []string{"route_id"}, func(row map[string]string) error {
+		name := row["route_short_name"]
+		if name == "" {
+			name = row["route_long_name"]
+		}
+		if name == "" {
+			return fmt.Errorf("la ruta %s no tiene route_short_name ni route_long_name", row["route_id"])
+		}
+		if _, dup := names[row["route_id"]]; dup {
+			return fmt.Errorf("route_id repetido: %s", row["route_id"])
+		}
+		names[row["route_id"]] = name
+		routeIDs = append(routeIDs, row["route_id"])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// stops.txt
+	stops := map[string]FeedStop{}
+	err = readCSV(files["stops.txt"], []string{"stop_id", "stop_name", "stop_lat", "stop_lon"}, func(row map[string]string) error {
+		lat, errLat := strconv.ParseFloat(row["stop_lat"], 64)
+		lon, errLon := strconv.ParseFloat(row["stop_lon"], 64)
+		if errLat != nil || errLon != nil || !models.ValidCoordinates(lat, lon) {
+			return fmt.Errorf("coordenadas inválidas en la parada %s", row["stop_id"])
+		}
+		stops[row["stop_id"]] = FeedStop{GTFSID: row["stop_id"], Name: row["stop_name"], Latitude: lat, Longitude: lon}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// trips.txt
+	type trip struct {
+		id, routeID string
+		direction   string
+		stops       int
+	}
+	trips := map[string]*trip{}
+	err = readCSV(files["trips.txt"], []string{"route_id", "trip_id"}, func(row map[string]string) error {
+		if _, ok := names[row["route_id"]]; !ok {
+			return fmt.Errorf("el viaje %s usa la ruta desconocida %s", row["trip_id"], row["route_id"])
+		}
+		trips[row["trip_id"]] = &trip{id: row["trip_id"], routeID: row["route_id"], direction: row["direction_id"]}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Primera pasada por stop_times: contar paradas por viaje para elegir
+	// el recorrido de cada ruta sin cargar todo el archivo en memoria
+	err = readCSV(files["stop_times.txt"], []string{"trip_id", "stop_id", "stop_sequence"}, func(row map[string]string) error {
+		t, ok := trips[row["trip_id"]]
+		if !ok {
+			return fmt.Errorf("stop_times usa el viaje desconocido %s", row["trip_id"])
+		}
+		t.stops++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	chosen := map[string]*trip{} // route_id → viaje
+	for _, t := range trips {
+		best, ok := chosen[t.routeID]
+		if !ok || betterPattern(t.stops, t.direction, t.id, best.stops, best.direction, best.id) {
+			chosen[t.routeID] = t
+		}
+	}
+	wanted := map[string]bool{}
+	for _, t := range chosen {
+		wanted[t.id] = true
+	}
+
+	// Segunda pasada: las paradas de los viajes elegidos
+	type stopTime struct {
+		stopID   string
+		sequence int
+	}
+	sequences := map[string][]stopTime{}
+	err = readCSV(files["stop_times.txt"], []string{"trip_id", "stop_id", "stop_sequence"}, func(row map[string]string) error {
+		if !wanted[row["trip_id"]] {
+			return nil
+		}
+		seq, err := strconv.Atoi(row["stop_sequence"])
+		if err != nil || seq < 0 {
+			return fmt.Errorf("stop_sequence inválido en el viaje %s", row["trip_id"])
+		}
+		if _, ok := stops[row["stop_id"]]; !ok {
+			return fmt.Errorf("el viaje %s usa la parada desconocida %s", row["trip_id"], row["stop_id"])
+		}
+		sequences[row["trip_id"]] = append(sequences[row["trip_id"]], stopTime{row["stop_id"], seq})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(routeIDs)
+	routes := []FeedRoute{}
+	for _, gtfsID := range routeIDs {
+		t, ok := chosen[gtfsID]
+		if !ok {
+			continue
+		}
+		list := sequences[t.id]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].sequence < list[j].sequence })
+		for i := 1; i < len(list); i++ {
+			if list[i].sequence == list[i-1].sequence {
+				return nil, fmt.Errorf("%w: stop_sequence repetido en el viaje %s", ErrInvalidFeed, t.id)
+			}
+		}
+
+		route := FeedRoute{GTFSID: gtfsID, ID: importID("route", gtfsID), Name: names[gtfsID]}
+		seen := map[string]int{}
+		for _, st := range list {
+			stop := stops[st.stopID]
+			// Una parada puede repetirse en rutas circulares; cada paso es una RouteStop
+			seen[st.stopID]++
+			key := gtfsID + "/" + st.stopID
+			if n := seen[st.stopID]; n > 1 {
+				key += "/" + strconv.Itoa(n)
+			}
+			stop.ID = importID("stop", key)
+			route.Stops = append(route.Stops, stop)
+		}
+		if len(route.Stops) < 2 {
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// importID retorna el uuid estable de un id del feed. Las rutas cuyo id ya
+// es un uuid (por ejemplo, un feed generado por este servicio) lo usan tal cual.
+func importID(kind, gtfsID string) uuid.UUID {
+	if kind == "route" {
+		if id, err := uuid.Parse(gtfsID); err == nil {
+			return id
+		}
+	}
+	return uuid.NewSHA1(importNamespace, []byte(kind+":"+gtfsID))
+}
+
+// betterPattern indica si el viaje a representa mejor a la ruta que b:
+// más paradas, luego direction_id 0 y luego trip_id menor
+func betterPattern(aStops int, aDir, aID string, bStops int, bDir, bID string) bool {
+	if aStops != bStops {
+		return aStops > bStops
+	}
+	aOut, bOut := aDir != "1", bDir != "1"
+	if aOut != bOut {
+		return aOut
+	}
+	return aID < bID
+}
+
+// readCSV recorre un archivo del feed llamando fn con cada fila indexada por
+// nombre de columna. required son las columnas que deben existir y no estar
+// vacías.
+func readCSV(f *zip.File, required []string, fn func(row map[string]string) error) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFeed, f.Name, err)
+	}
+	defer rc.Close()
+
+	cr := csv.NewReader(rc)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFeed, f.Name, err)
+	}
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+	for _, col := range required {
+		if !slices.Contains(header, col) {
+			return fmt.Errorf("%w: %s no tiene la columna %s", ErrInvalidFeed, f.Name, col)
+		}
+	}
+
+	row := map[string]string{}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidFeed, f.Name, err)
+		}
+		clear(row)
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		for _, col := range required {
+			if row[col] == "" {
+				return fmt.Errorf("%w: %s línea %d: %s vacío", ErrInvalidFeed, f.Name, line, col)
+			}
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%w: %s línea %d: %v", ErrInvalidFeed, f.Name, line, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/geo"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// table es un archivo .txt del feed
+type table struct {
+	name   string
+	header []string
+	rows   [][]string
+}
+
+// Feed es el feed GTFS listo para escribir
+type Feed struct {
+	tables []*table
+}
+
+// Export lee los datos y escribe el feed como zip en w
+func Export(ctx context.Context, repos *repository.Repositories, w io.Writer, opts Options) error {
+	sources, err := Load(ctx, repos, opts.Start, opts.Days)
+	if err != nil {
+		return err
+	}
+	return Build(sources, opts).WriteZip(w)
+}
+
+// Build arma el feed. Se omiten las rutas con menos de dos paradas activas
+// o sin salidas en el rango, porque GTFS no admite viajes de una sola parada
+// ni rutas sin viajes.
+func Build(sources []RouteSource, opts Options) *Feed {
+	from, to := window(opts.Start, opts.Days)
+	startDate := from.Format("20060102")
+	endDate := to.AddDate(0, 0, -1).Format("20060102")
+
+	agency := &table{name: "agency.txt", header: []string{"agency_id", "agency_name", "agency_url", "agency_timezone", "agency_lang"}}
+	agency.rows = append(agency.rows, []string{opts.Agency.ID, opts.Agency.Name, opts.Agency.URL, opts.Agency.Timezone, opts.Agency.Lang})
+
+	routes := &table{name: "routes.txt", header: []string{"route_id", "agency_id", "route_short_name", "route_long_name", "route_type"}}
+	stops := &table{name: "stops.txt", header: []string{"stop_id", "stop_name", "stop_lat", "stop_lon"}}
+	trips := &table{name: "trips.txt", header: []string{"route_id", "service_id", "trip_id", "trip_headsign", "direction_id"}}
+	stopTimes := &table{name: "stop_times.txt", header: []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence", "timepoint"}}
+	calendar := &table{name: "calendar.txt", header: []string{"service_id", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "start_date", "end_date"}}
+	calendarDates := &table{name: "calendar_dates.txt", header: []string{"service_id", "date", "exception_type"}}
+
+	for i := range sources {
+		source := &sources[i]
+		if len(source.Stops) < 2 {
+			continue
+		}
+		list := services(source, from, to)
+		if len(list) == 0 {
+			continue
+		}
+
+		routeID := source.Route.ID.String()
+		routes.rows = append(routes.rows, []string{
+			routeID,
+			opts.Agency.ID,
+			source.Route.Name,
+			source.Route.OriginName + " - " + source.Route.DestinationName,
+			routeTypeBus,
+		})
+
+		// Segundos desde la primera parada, según la distancia recorrida
+		offsets := make([]int, len(source.Stops))
+		var meters float64
+		for n, stop := range source.Stops {
+			stops.rows = append(stops.rows, []string{
+				stop.ID.String(),
+				stop.Name,
+				formatCoord(stop.Latitude),
+				formatCoord(stop.Longitude),
+			})
+			if n > 0 {
+				prev := source.Stops[n-1]
+				meters += geo.Distance(prev.Latitude, prev.Longitude, stop.Latitude, stop.Longitude)
+			}
+			offsets[n] = int(meters / averageSpeed)
+		}
+
+		for _, s := range list {
+			trips.rows = append(trips.rows, []string{routeID, s.id, s.id, s.headsign, "0"})
+
+			for n, stop := range source.Stops {
+				at := formatClock(int(s.clock.Seconds()) + offsets[n])
+				// Solo la salida es exacta; las demás horas son estimadas
+				timepoint := "0"
+				if n == 0 {
+					timepoint = "1"
+				}
+				stopTimes.rows = append(stopTimes.rows, []string{s.id, at, at, stop.ID.String(), strconv.Itoa(n + 1), timepoint})
+			}
+
+			calendar.rows = append(calendar.rows, calendarRow(s, startDate, endDate))
+			calendarDates.rows = append(calendarDates.rows, exceptions(s, from, to)...)
+		}
+	}
+
+	tables := []*table{agency, routes, stops, trips, stopTimes, calendar}
+	if len(calendarDates.rows) > 0 {
+		tables = append(tables, calendarDates)
+	}
+	return &Feed{tables: tables}
+}
+
+// calendarRow es la fila de calendar.txt con el patrón semanal del horario.
+// Las salidas puntuales quedan con todos los días en 0 y sus fechas van en
+// calendar_dates.
+func calendarRow(s *service, startDate, endDate string) []string {
+	row := []string{s.id, "0", "0", "0", "0", "0", "0", "0", startDate, endDate}
+	for _, d := range s.weekdays {
+		row[d] = "1" // ISO 1 = lunes ... 7 = domingo, igual que las columnas
+	}
+	return row
+}
+
+// exceptions compara el patrón semanal con las fechas en que el viaje corre
+// de verdad: agrega (1) las fechas fuera del patrón y quita (2) las
+// canceladas
+func exceptions(s *service, from, to time.Time) [][]string {
+	pattern := map[int]bool{}
+	for _, d := range s.weekdays {
+		pattern[d] = true
+	}
+
+	var rows [][]string
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		day := date.Format("20060102")
+		weekday := int(date.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		switch {
+		case s.dates[day] && !pattern[weekday]:
+			rows = append(rows, []string{s.id, day, "1"})
+		case !s.dates[day] && pattern[weekday]:
+			rows = append(rows, []string{s.id, day, "2"})
+		}
+	}
+	return rows
+}
+
+// formatClock escribe segundos desde medianoche como HH:MM:SS (GTFS admite
+// horas mayores a 24 para viajes que pasan la medianoche)
+func formatClock(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+}
+
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
+
+// WriteZip escribe los archivos del feed en un zip
+func (f *Feed) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, t := range f.tables {
+		file, err := zw.Create(t.name)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(file)
+		cw.UseCRLF = true
+		if err := cw.Write(t.header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.rows); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,115 @@
+package gtfs_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/gtfs"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	day := func(d, hour, min int) time.Time {
+		return time.Date(2026, time.March, d, hour, min, 0, 0, models.LocalZone)
+	}
+
+	// Semana del lunes 2 al domingo 8 de marzo de 2026
+	f.departure(t, day(3, 7, 30), true, models.DepartureStatusScheduled)
+	f.departure(t, day(4, 6, 0), true, models.DepartureStatusCancelled)
+	extra := f.departure(t, day(7, 10, 0), false, models.DepartureStatusScheduled)
+
+	// Las rutas inactivas no se exportan
+	inactive := models.Route{Name: "Ruta 2", OriginName: "A", DestinationName: "B", Currency: "PEN"}
+	if err := f.repos.Routes.Create(ctx, &inactive); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	agency := gtfs.Agency{ID: "realgo", Name: "RealGo", URL: "https://realgo.pe", Timezone: "America/Lima", Lang: "es"}
+	if err := gtfs.Export(ctx, f.repos, &buf, gtfs.Options{Agency: agency, Start: day(2, 9, 0), Days: 7}); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	routeID := f.route.ID.String()
+	early := f.timetable.ID.String() + "-0600"
+	late := f.timetable.ID.String() + "-0730"
+	oneOff := "dep-" + extra.ID.String()
+
+	want := map[string][][]string{
+		"agency.txt": {{"realgo", "RealGo", "https://realgo.pe", "America/Lima", "es"}},
+		"routes.txt": {{routeID, "realgo", "Ruta 1", "Centro - Norte", "3"}},
+		"stops.txt": {
+			{f.stops[0].ID.String(), "Centro", "-12.046400", "-77.042800"},
+			{f.stops[1].ID.String(), "Plaza", "-12.036400", "-77.042800"},
+			{f.stops[2].ID.String(), "Norte", "-12.026400", "-77.042800"},
+		},
+		// Un viaje por hora del horario y otro por la salida puntual
+		"trips.txt": {
+			{routeID, early, early, "Norte", "0"},
+			{routeID, late, late, "Norte", "0"},
+			{routeID, oneOff, oneOff, "Norte", "0"},
+		},
+		"calendar.txt": {
+			{early, "1", "1", "1", "1", "1", "0", "0", "20260302", "20260308"},
+			{late, "1", "1", "1", "1", "1", "0", "0", "20260302", "20260308"},
+			{oneOff, "0", "0", "0", "0", "0", "0", "0", "20260302", "20260308"},
+		},
+		// La salida cancelada del miércoles se quita y la del sábado se agrega
+		"calendar_dates.txt": {
+			{early, "20260304", "2"},
+			{oneOff, "20260307", "1"},
+		},
+	}
+	for name, rows := range want {
+		if got := files[name]; !slices.EqualFunc(got, rows, slices.Equal) {
+			t.Errorf("%s = %v, se esperaba %v", name, got, rows)
+		}
+	}
+
+	// stop_times: las paradas de cada viaje en orden, con horas crecientes
+	stopTimes := files["stop_times.txt"]
+	if len(stopTimes) != 9 {
+		t.Fatalf("stop_times.txt tiene %d filas, se esperaban 9", len(stopTimes))
+	}
+	starts := map[string]string{early: "06:00:00", late: "07:30:00", oneOff: "10:00:00"}
+	for i, row := range stopTimes {
+		n := i % 3
+		tripID, arrival, departure, stopID, sequence, timepoint := row[0], row[1], row[2], row[3], row[4], row[5]
+		if stopID != f.stops[n].ID.String() || sequence != strconv.Itoa(n+1) || arrival != departure {
+			t.Errorf("stop_times fila %d = %v", i, row)
+		}
+		if n == 0 {
+			if arrival != starts[tripID] || timepoint != "1" {
+				t.Errorf("primera parada de %s = %v, se esperaba salida %s exacta", tripID, row, starts[tripID])
+			}
+			continue
+		}
+		if prev := stopTimes[i-1]; prev[0] != tripID || arrival <= prev[1] || timepoint != "0" {
+			t.Errorf("stop_times fila %d = %v después de %v", i, row, prev)
+		}
+	}
+}
+
+func TestExportWithoutExceptions(t *testing.T) {
+	f := newFixture(t)
+
+	var buf bytes.Buffer
+	start := time.Date(2026, time.March, 2, 0, 0, 0, 0, models.LocalZone)
+	if err := gtfs.Export(context.Background(), f.repos, &buf, gtfs.Options{Agency: gtfs.AgencyFromEnv(), Start: start, Days: 7}); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if _, ok := files["calendar_dates.txt"]; ok {
+		t.Fatal("calendar_dates.txt no debe incluirse si el horario se cumple tal cual")
+	}
+	if len(files["trips.txt"]) != 2 || len(files["stop_times.txt"]) != 6 {
+		t.Fatalf("trips = %v, stop_times = %d filas", files["trips.txt"], len(files["stop_times.txt"]))
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// UpdateUserRoleRequest estructura para cambiar el rol de un usuario
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"` // passenger, driver, admin
+}
+
+// AssignDriverRequest estructura para asignar un conductor a una ruta
+type AssignDriverRequest struct {
+	DriverID uuid.UUID `json:"driver_id"`
+}
+
+// ListUsers retorna los usuarios, opcionalmente filtrados por rol
+//
+// Request:
+// GET /admin/users?role=driver
+//
+// Response:
+// 200 OK
+// [
+//   {"id": "uuid", "name": "Juan Pérez", "email": "", "phone": "+51987654321", "role": "driver", ...}
+// ]
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	role := r.URL.Query().Get("role")
+	if role != "" && !models.ValidRole(role) {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "role inválido (passenger, driver, admin)")
+		return
+	}
+
+	users, err := h.Users.List(r.Context(), role)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando usuarios")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// UpdateUserRole cambia el rol de un usuario
+//
+// Request:
+// PUT /admin/users/{id}/role
+// {
+//   "role": "driver"
+// }
+//
+// Response:
+// 200 OK (usuario actualizado)
+func (h *Handler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de usuario inválido")
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if !models.ValidRole(req.Role) {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "role inválido (passenger, driver, admin)")
+		return
+	}
+
+	user, err := h.Users.UpdateRole(r.Context(), userID, req.Role)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeUserNotFound, "Usuario no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando usuario")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// AssignRouteDriver asigna un conductor a una ruta
+//
+// Request:
+// POST /admin/routes/{id}/drivers
+// {
+//   "driver_id": "uuid-del-conductor"
+// }
+//
+// Response:
+// 204 No Content
+func (h *Handler) AssignRouteDriver(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req AssignDriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if req.DriverID == uuid.Nil {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "driver_id es requerido")
+		return
+	}
+
+	// Verificar que la ruta existe y que el usuario es conductor
+	if _, err := h.Routes.Get(r.Context(), routeID); errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	} else if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+	driver, err := h.Users.Get(r.Context(), req.DriverID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		writeStoreError(w, err, "Error consultando usuario")
+		return
+	}
+	if driver == nil || driver.Role != models.RoleDriver {
+		writeError(w, http.StatusBadRequest, ErrCodeUserNotDriver, "El usuario no es conductor")
+		return
+	}
+
+	if err := h.Routes.AssignDriver(r.Context(), routeID, req.DriverID); err != nil {
+		writeStoreError(w, err, "Error asignando conductor")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnassignRouteDriver quita un conductor de una ruta
+//
+// Request:
+// DELETE /admin/routes/{id}/drivers/{driverID}
+//
+// Response:
+// 204 No Content
+func (h *Handler) UnassignRouteDriver(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de conductor inválido")
+		return
+	}
+
+	err = h.Routes.UnassignDriver(r.Context(), routeID, driverID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeAssignmentNotFound, "Asignación no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error quitando conductor")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// RouteRequest estructura para crear o actualizar una ruta
+type RouteRequest struct {
+	Name            string  `json:"name"`
+	OriginName      string  `json:"origin_name"`
+	OriginLat       float64 `json:"origin_lat"`
+	OriginLon       float64 `json:"origin_lon"`
+	DestinationName string  `json:"destination_name"`
+	DestinationLat  float64 `json:"destination_lat"`
+	DestinationLon  float64 `json:"destination_lon"`
+	BasePriceCents  int     `json:"base_price_cents"`
+	Currency        string  `json:"currency"` // por defecto PEN
+}
+
+// StopRequest estructura para crear o editar una parada.
+// Order solo se usa al crear; si se omite la parada va al final.
+type StopRequest struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Order     *int    `json:"order"`
+}
+
+// ReorderStopsRequest estructura para reordenar las paradas de una ruta.
+// Debe incluir todas las paradas de la ruta en el nuevo orden.
+type ReorderStopsRequest struct {
+	StopIDs []uuid.UUID `json:"stop_ids"`
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validate normaliza la ruta y retorna un mensaje si es inválida
+func (req *RouteRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.OriginName = strings.TrimSpace(req.OriginName)
+	req.DestinationName = strings.TrimSpace(req.DestinationName)
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		req.Currency = "PEN"
+	}
+
+	switch {
+	case req.Name == "":
+		return "name es requerido"
+	case req.OriginName == "":
+		return "origin_name es requerido"
+	case req.DestinationName == "":
+		return "destination_name es requerido"
+	case !models.ValidCoordinates(req.OriginLat, req.OriginLon):
+		return "origin_lat/origin_lon inválidos"
+	case !models.ValidCoordinates(req.DestinationLat, req.DestinationLon):
+		return "destination_lat/destination_lon inválidos"
+	case req.BasePriceCents < 0:
+		return "base_price_cents no puede ser negativo"
+	case !currencyPattern.MatchString(req.Currency):
+		return "currency inválida (código ISO 4217, ej: PEN)"
+	}
+	return ""
+}
+
+// route convierte el request en el modelo a guardar
+func (req *RouteRequest) route(id uuid.UUID) *models.Route {
+	return &models.Route{
+		ID:              id,
+		Name:            req.Name,
+		IsActive:        true,
+		OriginName:      req.OriginName,
+		OriginLat:       req.OriginLat,
+		OriginLon:       req.OriginLon,
+		DestinationName: req.DestinationName,
+		DestinationLat:  req.DestinationLat,
+		DestinationLon:  req.DestinationLon,
+		BasePriceCents:  req.BasePriceCents,
+		Currency:        req.Currency,
+	}
+}
+
+// validate normaliza la parada y retorna un mensaje si es inválida
+func (req *StopRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+
+	switch {
+	case req.Name == "":
+		return "name es requerido"
+	case !models.ValidCoordinates(req.Latitude, req.Longitude):
+		return "latitude/longitude inválidos"
+	}
+	return ""
+}
+
+// CreateRoute crea una ruta nueva (activa)
+//
+// Request:
+// POST /admin/routes
+// {
+//   "name": "Ruta Centro - Norte",
+//   "origin_name": "Centro", "origin_lat": -12.0464, "origin_lon": -77.0428,
+//   "destination_name": "Norte", "destination_lat": -11.9498, "destination_lon": -77.0622,
+//   "base_price_cents": 500,
+//   "currency": "PEN"
+// }
+//
+// Response:
+// 201 Created (ruta creada)
+func (h *Handler) CreateRoute(w http.ResponseWriter, r *http.Request) {
+	var req RouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, msg)
+		return
+	}
+
+	route := req.route(uuid.Nil)
+	if err := h.Routes.Create(r.Context(), route); err != nil {
+		writeStoreError(w, err, "Error creando ruta")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(route)
+}
+
+// UpdateRoute reemplaza los datos de una ruta
+//
+// Request:
+// PUT /admin/routes/{id}
+// (mismo cuerpo que POST /admin/routes)
+//
+// Response:
+// 200 OK (ruta actualizada)
+func (h *Handler) UpdateRoute(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req RouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, msg)
+		return
+	}
+
+	route := req.route(routeID)
+	err = h.Routes.Update(r.Context(), route)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando ruta")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(route)
+}
+
+// ActivateRoute vuelve a publicar una ruta desactivada
+//
+// Request:
+// POST /admin/routes/{id}/activate
+//
+// Response:
+// 200 OK (ruta con is_active = true)
+func (h *Handler) ActivateRoute(w http.ResponseWriter, r *http.Request) {
+	h.setRouteActive(w, r, true)
+}
+
+// DeactivateRoute oculta una ruta de GET /routes sin borrar su historial
+//
+// Request:
+// POST /admin/routes/{id}/deactivate
+//
+// Response:
+// 200 OK (ruta con is_active = false)
+func (h *Handler) DeactivateRoute(w http.ResponseWriter, r *http.Request) {
+	h.setRouteActive(w, r, false)
+}
+
+func (h *Handler) setRouteActive(w http.ResponseWriter, r *http.Request, active bool) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	route, err := h.Routes.SetActive(r.Context(), routeID, active)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando ruta")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(route)
+}
+
+// DeleteRoute elimina una ruta y sus paradas.
+// Si la ruta ya tiene viajes responde 409; en ese caso se debe desactivar.
+//
+// Request:
+// DELETE /admin/routes/{id}
+//
+// Response:
+// 204 No Content
+func (h *Handler) DeleteRoute(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	err = h.Routes.Delete(r.Context(), routeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, ErrCodeRouteHasTrips, "La ruta tiene viajes registrados; desactívala en lugar de eliminarla")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error eliminando ruta")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRouteStops retorna todas las paradas de una ruta, incluidas las inactivas
+//
+// Request:
+// GET /admin/routes/{id}/stops
+//
+// Response:
+// 200 OK
+// [
+//   {"id": "uuid", "route_id": "uuid", "name": "Parada A", "order": 1,
+//    "latitude": -12.04, "longitude": -77.04, "is_active": true, "created_at": "..."}
+// ]
+func (h *Handler) ListRouteStops(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	stops, err := h.Stops.ListByRoute(r.Context(), routeID, false)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando paradas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stops)
+}
+
+// CreateRouteStop agrega una parada a la ruta.
+// Si se indica order, las paradas desde esa posición se desplazan una posición.
+//
+// Request:
+// POST /admin/routes/{id}/stops
+// {
+//   "name": "Parada A",
+//   "latitude": -12.0464,
+//   "longitude": -77.0428,
+//   "order": 2
+// }
+//
+// Response:
+// 201 Created (parada creada)
+func (h *Handler) CreateRouteStop(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req StopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, msg)
+		return
+	}
+
+	stop := &models.RouteStop{
+		RouteID:   routeID,
+		Name:      req.Name,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+	if req.Order != nil {
+		if *req.Order < 1 {
+			writeError(w, http.StatusBadRequest, ErrCodeValidation, "order debe ser mayor o igual a 1")
+			return
+		}
+		stop.Order = *req.Order
+	}
+
+	err = h.Stops.Create(r.Context(), stop)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if errors.Is(err, repository.ErrInvalidStopOrder) {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidStopOrder, err.Error())
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error creando parada")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(stop)
+}
+
+// UpdateRouteStop edita nombre y coordenadas de una parada.
+// Para cambiar el orden se usa PUT /admin/routes/{id}/stops/order.
+//
+// Request:
+// PUT /admin/routes/{id}/stops/{stopID}
+// {
+//   "name": "Parada A",
+//   "latitude": -12.0464,
+//   "longitude": -77.0428
+// }
+//
+// Response:
+// 200 OK (parada actualizada)
+func (h *Handler) UpdateRouteStop(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	stopID, err := uuid.Parse(chi.URLParam(r, "stopID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de parada inválido")
+		return
+	}
+
+	var req StopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, msg)
+		return
+	}
+	if req.Order != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "order no se puede editar aquí; usa PUT /admin/routes/{id}/stops/order")
+		return
+	}
+
+	stop := &models.RouteStop{
+		ID:        stopID,
+		RouteID:   routeID,
+		Name:      req.Name,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+	err = h.Stops.Update(r.Context(), stop)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeStopNotFound, "Parada no encontrada en esta ruta")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando parada")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stop)
+}
+
+// ReorderRouteStops asigna un nuevo orden a las paradas de la ruta
+//
+// Request:
+// PUT /admin/routes/{id}/stops/order
+// {
+//   "stop_ids": ["uuid-parada-1", "uuid-parada-2", "uuid-parada-3"]
+// }
+//
+// Response:
+// 200 OK (paradas en el nuevo orden)
+func (h *Handler) ReorderRouteStops(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req ReorderStopsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+
+	stops, err := h.Stops.Reorder(r.Context(), routeID, req.StopIDs)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if errors.Is(err, repository.ErrInvalidStopOrder) {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidStopOrder, err.Error())
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error ordenando paradas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stops)
+}
+
+// ActivateRouteStop vuelve a mostrar una parada desactivada
+//
+// Request:
+// POST /admin/routes/{id}/stops/{stopID}/activate
+//
+// Response:
+// 200 OK (parada con is_active = true)
+func (h *Handler) ActivateRouteStop(w http.ResponseWriter, r *http.Request) {
+	h.setStopActive(w, r, true)
+}
+
+// DeactivateRouteStop oculta una parada de GET /routes/{id}.
+// La parada conserva su posición para no romper el orden de la ruta.
+//
+// Request:
+// POST /admin/routes/{id}/stops/{stopID}/deactivate
+//
+// Response:
+// 200 OK (parada con is_active = false)
+func (h *Handler) DeactivateRouteStop(w http.ResponseWriter, r *http.Request) {
+	h.setStopActive(w, r, false)
+}
+
+func (h *Handler) setStopActive(w http.ResponseWriter, r *http.Request, active bool) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	stopID, err := uuid.Parse(chi.URLParam(r, "stopID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de parada inválido")
+		return
+	}
+
+	stop, err := h.Stops.SetActive(r.Context(), routeID, stopID, active)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeStopNotFound, "Parada no encontrada en esta ruta")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando parada")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stop)
+}
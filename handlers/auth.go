@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/auth"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// RequestCodeRequest estructura para solicitar un código de acceso
+type RequestCodeRequest struct {
+	Phone string `json:"phone"`
+}
+
+// VerifyCodeRequest estructura para validar un código de acceso.
+// Name y Email solo se usan cuando el teléfono aún no está registrado.
+type VerifyCodeRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// AuthResponse es la respuesta de un login o registro exitoso
+type AuthResponse struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	User      models.User `json:"user"`
+	Created   bool        `json:"created"`
+}
+
+// RequestCode envía un código de un solo uso por SMS
+//
+// Request:
+// POST /auth/code
+// {
+//   "phone": "987654321"
+// }
+//
+// Response:
+// 204 No Content
+func (h *Handler) RequestCode(w http.ResponseWriter, r *http.Request) {
+	var req RequestCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+
+	phone, err := auth.NormalizePhone(req.Phone)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "phone inválido")
+		return
+	}
+
+	code, err := auth.NewCode()
+	if err != nil {
+		writeStoreError(w, err, "Error generando código")
+		return
+	}
+
+	// Un solo código vigente por teléfono; no se reenvía antes de CodeResendInterval
+	now := time.Now()
+	saved, err := h.Sessions.SaveCode(r.Context(), &models.AuthCode{
+		Phone:     phone,
+		CodeHash:  auth.HashCode(phone, code),
+		ExpiresAt: now.Add(auth.CodeTTL),
+		CreatedAt: now,
+	}, now.Add(-auth.CodeResendInterval))
+	if err != nil {
+		writeStoreError(w, err, "Error guardando código")
+		return
+	}
+	if !saved {
+		writeError(w, http.StatusTooManyRequests, ErrCodeCodeRateLimit, "Espera un momento antes de pedir otro código")
+		return
+	}
+
+	if err := h.SMS.Send(r.Context(), phone, "Tu código de acceso es "+code); err != nil {
+		log.Printf("Error enviando SMS a %s: %v", phone, err)
+		writeError(w, http.StatusBadGateway, ErrCodeSMSFailed, "Error enviando SMS")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyCode valida el código y abre una sesión.
+// Si el teléfono no está registrado crea el usuario (name es requerido).
+//
+// Request:
+// POST /auth/verify
+// {
+//   "phone": "987654321",
+//   "code": "123456",
+//   "name": "Juan Pérez | solo para registro",
+//   "email": "juan@example.com | opcional"
+// }
+//
+// Response:
+// 200 OK
+// {
+//   "token": "token-opaco",
+//   "expires_at": "2026-02-08T15:30:00Z",
+//   "user": {"id": "uuid", "name": "Juan Pérez", "email": "", "phone": "+51987654321", "role": "passenger", ...},
+//   "created": false
+// }
+func (h *Handler) VerifyCode(w http.ResponseWriter, r *http.Request) {
+	var req VerifyCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+
+	phone, err := auth.NormalizePhone(req.Phone)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "phone inválido")
+		return
+	}
+	if req.Code == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "code es requerido")
+		return
+	}
+
+	// Buscar usuario existente antes de consumir el código
+	user, err := h.Users.GetByPhone(r.Context(), phone)
+	if errors.Is(err, repository.ErrNotFound) {
+		user = nil
+		if strings.TrimSpace(req.Name) == "" {
+			writeError(w, http.StatusBadRequest, ErrCodeValidation, "name es requerido para registrarse")
+			return
+		}
+	} else if err != nil {
+		writeStoreError(w, err, "Error consultando usuario")
+		return
+	}
+
+	now := time.Now()
+	valid, err := h.Sessions.ConsumeCode(r.Context(), phone, auth.HashCode(phone, req.Code), now, auth.MaxCodeAttempts)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando código")
+		return
+	}
+	if !valid {
+		writeError(w, http.StatusUnauthorized, ErrCodeInvalidCode, "Código inválido o expirado")
+		return
+	}
+
+	// Registro de usuario nuevo
+	created := false
+	if user == nil {
+		user = &models.User{
+			Name:  strings.TrimSpace(req.Name),
+			Email: strings.TrimSpace(req.Email),
+			Phone: phone,
+			Role:  models.RolePassenger,
+		}
+		if err := h.Users.Create(r.Context(), user); err != nil {
+			writeStoreError(w, err, "Error registrando usuario")
+			return
+		}
+		created = true
+	}
+
+	// Crear sesión; en base de datos solo se guarda el hash del token
+	token, err := auth.NewToken()
+	if err != nil {
+		writeStoreError(w, err, "Error generando token")
+		return
+	}
+	session := models.Session{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(auth.SessionTTL),
+		CreatedAt: now,
+	}
+	if err := h.Sessions.CreateSession(r.Context(), &session); err != nil {
+		writeStoreError(w, err, "Error creando sesión")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AuthResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      *user,
+		Created:   created,
+	})
+}
+
+// Logout cierra la sesión del token actual
+//
+// Request:
+// POST /auth/logout
+// Authorization: Bearer <token>
+//
+// Response:
+// 204 No Content
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.Sessions.DeleteSession(r.Context(), auth.HashToken(bearerToken(r))); err != nil {
+		writeStoreError(w, err, "Error cerrando sesión")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMe retorna el usuario autenticado
+//
+// Request:
+// GET /me
+// Authorization: Bearer <token>
+//
+// Response:
+// 200 OK
+// {"id": "uuid", "name": "Juan Pérez", "email": "", "phone": "+51987654321", "role": "passenger", ...}
+func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// RequireAuth valida el token Bearer y agrega el usuario al contexto
+func (h *Handler) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Autenticación requerida")
+			return
+		}
+
+		user, err := h.Sessions.UserBySession(r.Context(), auth.HashToken(token), time.Now())
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusUnauthorized, ErrCodeInvalidSession, "Sesión inválida o expirada")
+			return
+		}
+		if err != nil {
+			writeStoreError(w, err, "Error validando sesión")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+}
+
+// RequireRole permite el acceso solo a usuarios con alguno de los roles dados.
+// Debe usarse después de RequireAuth.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := auth.UserFromContext(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Autenticación requerida")
+				return
+			}
+			if !user.HasRole(roles...) {
+				writeError(w, http.StatusForbidden, ErrCodeForbidden, "No tienes permiso para esta acción")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken extrae el token del header Authorization
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/auth"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+	"github.com/skip2/go-qrcode"
+)
+
+// boardingPassSize es el ancho y alto en píxeles del QR del pase
+const boardingPassSize = 320
+
+// CheckinRequest estructura para validar un pase de abordar
+type CheckinRequest struct {
+	DepartureID *uuid.UUID `json:"departure_id"`
+	Token       string     `json:"token"` // contenido del QR
+}
+
+// CheckinResponse es el viaje iniciado con los datos que el conductor
+// necesita para reconocer al pasajero
+type CheckinResponse struct {
+	Trip      *models.Trip     `json:"trip"`
+	Passenger string           `json:"passenger_name"`
+	Pickup    *models.StopInfo `json:"pickup"`
+	Dropoff   *models.StopInfo `json:"dropoff"`
+}
+
+// GetBoardingPass retorna el pase de abordar de un viaje confirmado como QR
+//
+// Request:
+// GET /trips/{id}/boarding-pass.png
+// Authorization: Bearer <token>
+//
+// El QR contiene un token firmado con HMAC-SHA256 sobre los ids del viaje,
+// la salida y el pasajero. Solo lo ven el pasajero dueño y los
+// administradores.
+//
+// Response:
+// 200 OK (image/png)
+// 404 Not Found si el viaje no existe o es de otro pasajero
+// 409 Conflict si el viaje no tiene salida o no está en estado "confirmed"
+func (h *Handler) GetBoardingPass(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+
+	tripID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de viaje inválido")
+		return
+	}
+
+	trip, err := h.Trips.Get(r.Context(), tripID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && trip.PassengerID != user.ID && user.Role != models.RoleAdmin) {
+		writeError(w, http.StatusNotFound, ErrCodeTripNotFound, "Viaje no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando viaje")
+		return
+	}
+	if trip.DepartureID == nil || trip.Status != models.TripStatusConfirmed {
+		writeError(w, http.StatusConflict, ErrCodeTripNotConfirmed, "Solo los viajes confirmados con salida tienen pase de abordar")
+		return
+	}
+
+	token := h.Boarding.Sign(auth.BoardingPass{
+		TripID:      trip.ID,
+		DepartureID: *trip.DepartureID,
+		PassengerID: trip.PassengerID,
+	})
+	png, err := qrcode.Encode(token, qrcode.Medium, boardingPassSize)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error generando pase de abordar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Write(png)
+}
+
+// CheckIn valida el pase de abordar que escanea el conductor e inicia el
+// viaje
+//
+// Request:
+// POST /driver/checkin
+// Authorization: Bearer <token>
+// {
+//   "departure_id": "uuid",
+//   "token": "b1.…"
+// }
+//
+// La firma se comprueba antes de consultar la base de datos. El viaje pasa
+// de "confirmed" a "started" y registra started_at, así que el mismo pase
+// no se puede usar dos veces.
+//
+// Response:
+// 200 OK
+// {
+//   "trip": {...viaje con status "started"...},
+//   "passenger_name": "Ana",
+//   "pickup": {"id": "uuid", "name": "Plaza de Armas"},
+//   "dropoff": {"id": "uuid", "name": "Terminal Norte"}
+// }
+// 400 Bad Request (invalid_boarding_pass) si el token no es válido
+// 404 Not Found si la salida no existe o no está asignada al conductor
+// 409 Conflict: wrong_departure si el pase es de otra salida,
+// boarding_pass_used si el pasajero ya abordó y trip_not_confirmed si el
+// viaje no está confirmado
+func (h *Handler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+
+	var req CheckinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	var fields []FieldError
+	if req.DepartureID == nil {
+		fields = append(fields, FieldError{Field: "departure_id", Code: "required", Message: "departure_id es requerido"})
+	}
+	if strings.TrimSpace(req.Token) == "" {
+		fields = append(fields, FieldError{Field: "token", Code: "required", Message: "token es requerido"})
+	}
+	if len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+
+	pass, err := h.Boarding.Verify(req.Token)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidBoardingPass, "Pase de abordar inválido")
+		return
+	}
+
+	// Solo el conductor asignado valida pases de la salida
+	departure, err := h.Departures.Get(r.Context(), *req.DepartureID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && (departure.DriverID == nil || *departure.DriverID != user.ID)) {
+		writeError(w, http.StatusNotFound, ErrCodeDepartureNotFound, "Salida no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando salida")
+		return
+	}
+	if pass.DepartureID != departure.ID {
+		writeError(w, http.StatusConflict, ErrCodeWrongDeparture, "El pase de abordar es de otra salida")
+		return
+	}
+
+	trip, err := h.Trips.Update(r.Context(), pass.TripID, func(t *models.Trip) error {
+		// El pase deja de valer si el viaje cambió de salida o de pasajero
+		if t.DepartureID == nil || *t.DepartureID != pass.DepartureID || t.PassengerID != pass.PassengerID {
+			return errWrongDeparture
+		}
+		return t.Board(time.Now())
+	})
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidBoardingPass, "Pase de abordar inválido")
+		return
+	case errors.Is(err, errWrongDeparture):
+		writeError(w, http.StatusConflict, ErrCodeWrongDeparture, "El pase de abordar es de otra salida")
+		return
+	case errors.Is(err, models.ErrTripAlreadyBoarded):
+		writeError(w, http.StatusConflict, ErrCodeBoardingPassUsed, "El pase de abordar ya fue usado")
+		return
+	case errors.Is(err, models.ErrTripNotConfirmed):
+		writeError(w, http.StatusConflict, ErrCodeTripNotConfirmed, "El viaje no está confirmado")
+		return
+	case err != nil:
+		writeStoreError(w, err, "Error registrando abordaje")
+		return
+	}
+
+	resp := CheckinResponse{Trip: trip}
+	passenger, err := h.Users.Get(r.Context(), trip.PassengerID)
+	if err == nil {
+		resp.Passenger = passenger.Name
+	}
+	stops, err := h.Stops.ListByRoute(r.Context(), trip.RouteID, false)
+	if err == nil {
+		for _, stop := range stops {
+			info := &models.StopInfo{ID: stop.ID, Name: stop.Name}
+			if trip.PickupStopID != nil && *trip.PickupStopID == stop.ID {
+				resp.Pickup = info
+			}
+			if trip.DropoffStopID != nil && *trip.DropoffStopID == stop.ID {
+				resp.Dropoff = info
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// errWrongDeparture indica que el viaje ya no corresponde al pase
+var errWrongDeparture = errors.New("el pase de abordar es de otra salida")
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// CancellationPolicyRequest estructura para configurar la política de
+// cancelación de una ruta
+type CancellationPolicyRequest struct {
+	FreeUntilMinutes *int `json:"free_until_minutes"`
+	FeePercent       *int `json:"fee_percent"`
+}
+
+// Validate valida el request y retorna los errores por campo
+func (req *CancellationPolicyRequest) Validate() []FieldError {
+	var fields []FieldError
+	if req.FreeUntilMinutes == nil {
+		fields = append(fields, FieldError{Field: "free_until_minutes", Code: "required", Message: "free_until_minutes es requerido"})
+	} else if *req.FreeUntilMinutes < 0 || *req.FreeUntilMinutes > models.MaxCancellationFreeMinutes {
+		fields = append(fields, FieldError{
+			Field:   "free_until_minutes",
+			Code:    "invalid",
+			Message: "free_until_minutes debe estar entre 0 y " + strconv.Itoa(models.MaxCancellationFreeMinutes),
+		})
+	}
+	if req.FeePercent == nil {
+		fields = append(fields, FieldError{Field: "fee_percent", Code: "required", Message: "fee_percent es requerido"})
+	} else if *req.FeePercent < 0 || *req.FeePercent > 100 {
+		fields = append(fields, FieldError{Field: "fee_percent", Code: "invalid", Message: "fee_percent debe estar entre 0 y 100"})
+	}
+	return fields
+}
+
+// GetRouteCancellationPolicy retorna la política de cancelación de una ruta
+// activa
+//
+// Request:
+// GET /routes/{id}/cancellation-policy
+//
+// Response:
+// 200 OK
+// {"free_until_minutes": 120, "fee_percent": 20}
+func (h *Handler) GetRouteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	route, err := h.Routes.Get(r.Context(), routeID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !route.IsActive) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+	policy, err := h.Routes.CancellationPolicy(r.Context(), routeID)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando política de cancelación")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// SetRouteCancellationPolicy configura la política de cancelación de una ruta
+//
+// Request:
+// PUT /admin/routes/{id}/cancellation-policy
+// {"free_until_minutes": 120, "fee_percent": 20}
+//
+// Cancelar es gratis hasta free_until_minutes antes de la salida; después
+// se cobra fee_percent del precio del viaje. {"free_until_minutes": 0,
+// "fee_percent": 0} (la política por defecto) permite cancelar gratis
+// siempre. Solo aplica a las cancelaciones que se hagan desde ahora.
+//
+// Response:
+// 200 OK
+// {"free_until_minutes": 120, "fee_percent": 20}
+func (h *Handler) SetRouteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req CancellationPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if fields := req.Validate(); len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+
+	policy := models.CancellationPolicy{FreeUntilMinutes: *req.FreeUntilMinutes, FeePercent: *req.FeePercent}
+	err = h.Routes.SetCancellationPolicy(r.Context(), routeID, policy)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error guardando política de cancelación")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// GetCancellationQuote calcula cuánto costaría cancelar el viaje ahora, para
+// mostrarlo antes de que el usuario confirme
+//
+// Request:
+// GET /trips/{id}/cancellation
+// Authorization: Bearer <token>
+//
+// Response:
+// 200 OK
+// {
+//   "free": false,
+//   "free_until": "2026-01-10T08:00:00Z",
+//   "fee_percent": 20,
+//   "fee_cents": 100,
+//   "refund_cents": 400,
+//   "fee": 1.00,
+//   "refund": 4.00,
+//   "currency": "PEN"
+// }
+// 409 Conflict (invalid_transition) si el viaje ya no se puede cancelar
+func (h *Handler) GetCancellationQuote(w http.ResponseWriter, r *http.Request) {
+	user, trip, ok := h.visibleTrip(w, r)
+	if !ok {
+		return
+	}
+	policy, err := h.cancellationPolicyFor(r.Context(), user, trip)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando política de cancelación")
+		return
+	}
+
+	quote, err := cancellationQuote(policy, trip, time.Now())
+	if errors.Is(err, models.ErrInvalidTransition) {
+		writeError(w, http.StatusConflict, ErrCodeInvalidTransition, "El viaje ya no se puede cancelar (status "+trip.Status+")")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+// cancellationPolicyFor retorna la política que aplica si user cancela el
+// viaje: la de la ruta para el pasajero dueño y nil (sin cargo) para el
+// conductor o un admin
+func (h *Handler) cancellationPolicyFor(ctx context.Context, user *models.User, trip *models.Trip) (*models.CancellationPolicy, error) {
+	if trip.PassengerID != user.ID {
+		return nil, nil
+	}
+	return h.Routes.CancellationPolicy(ctx, trip.RouteID)
+}
+
+// cancellationQuote cotiza la cancelación con policy, o sin cargo si es nil
+func cancellationQuote(policy *models.CancellationPolicy, trip *models.Trip, now time.Time) (*models.CancellationQuote, error) {
+	if policy == nil {
+		if !models.CanTransition(trip.Status, models.TripStatusCancelled) {
+			return nil, models.ErrInvalidTransition
+		}
+		return models.WaivedCancellation(trip), nil
+	}
+	return policy.Quote(trip, now)
+}
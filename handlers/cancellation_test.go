@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestCancellationPolicy(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	route, _ := s.route(admin, "A", "B")
+	path := "/routes/" + route.ID.String() + "/cancellation-policy"
+
+	// Sin configurar se cancela gratis siempre
+	policy := decode[models.CancellationPolicy](t, s.do(http.MethodGet, path, "", nil), http.StatusOK)
+	if policy != (models.CancellationPolicy{}) {
+		t.Fatalf("política por defecto = %+v", policy)
+	}
+
+	want := models.CancellationPolicy{FreeUntilMinutes: 120, FeePercent: 20}
+	decode[models.CancellationPolicy](t, s.do(http.MethodPut, "/admin"+path, admin, want), http.StatusOK)
+	if got := decode[models.CancellationPolicy](t, s.do(http.MethodGet, path, "", nil), http.StatusOK); got != want {
+		t.Fatalf("política = %+v, se esperaba %+v", got, want)
+	}
+
+	for _, body := range []map[string]any{
+		{},
+		{"free_until_minutes": -1, "fee_percent": 20},
+		{"free_until_minutes": 60, "fee_percent": 101},
+		{"free_until_minutes": models.MaxCancellationFreeMinutes + 1, "fee_percent": 0},
+	} {
+		assertError(t, s.do(http.MethodPut, "/admin"+path, admin, body), http.StatusBadRequest, handlers.ErrCodeValidation)
+	}
+}
+
+func TestCancellationQuote(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+	s.do(http.MethodPut, "/admin/routes/"+route.ID.String()+"/cancellation-policy", admin,
+		models.CancellationPolicy{FreeUntilMinutes: 120, FeePercent: 20})
+
+	t.Run("dentro del plazo gratuito", func(t *testing.T) {
+		trip := s.bookTrip(passenger, s.departure(route.ID, 10, 24*time.Hour))
+		quote := decode[models.CancellationQuote](t, s.do(http.MethodGet, "/trips/"+trip.ID.String()+"/cancellation", passenger, nil), http.StatusOK)
+		if !quote.Free || quote.FeeCents != 0 || quote.RefundCents != 500 {
+			t.Fatalf("cotización = %+v", quote)
+		}
+		if quote.FreeUntil == nil || !quote.FreeUntil.Equal(trip.ScheduledAt.Add(-2*time.Hour)) {
+			t.Fatalf("free_until = %v", quote.FreeUntil)
+		}
+	})
+
+	t.Run("fuera del plazo el pasajero paga el cargo", func(t *testing.T) {
+		trip := s.bookTrip(passenger, s.departure(route.ID, 10, time.Hour))
+		path := "/trips/" + trip.ID.String()
+		quote := decode[models.CancellationQuote](t, s.do(http.MethodGet, path+"/cancellation", passenger, nil), http.StatusOK)
+		if quote.Free || quote.FeePercent != 20 || quote.FeeCents != 100 || quote.RefundCents != 400 {
+			t.Fatalf("cotización = %+v", quote)
+		}
+
+		cancelled := decode[models.Trip](t, s.do(http.MethodPost, path+"/cancel", passenger, nil), http.StatusOK)
+		if cancelled.CancellationFeeCents == nil || *cancelled.CancellationFeeCents != 100 ||
+			cancelled.RefundCents == nil || *cancelled.RefundCents != 400 {
+			t.Fatalf("cargo = %v, reembolso = %v", cancelled.CancellationFeeCents, cancelled.RefundCents)
+		}
+
+		assertError(t, s.do(http.MethodGet, path+"/cancellation", passenger, nil), http.StatusConflict, handlers.ErrCodeInvalidTransition)
+	})
+
+	t.Run("el admin cancela sin cargo", func(t *testing.T) {
+		trip := s.bookTrip(passenger, s.departure(route.ID, 10, 30*time.Minute))
+		path := "/trips/" + trip.ID.String()
+		quote := decode[models.CancellationQuote](t, s.do(http.MethodGet, path+"/cancellation", admin, nil), http.StatusOK)
+		if !quote.Free || quote.RefundCents != 500 {
+			t.Fatalf("cotización = %+v", quote)
+		}
+		cancelled := decode[models.Trip](t, s.do(http.MethodPost, path+"/cancel", admin, nil), http.StatusOK)
+		if cancelled.CancellationFeeCents == nil || *cancelled.CancellationFeeCents != 0 {
+			t.Fatalf("cargo = %v, se esperaba 0", cancelled.CancellationFeeCents)
+		}
+	})
+}
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// maxScheduleDays limita cuántos días hacia adelante se pueden consultar
+// salidas; más allá los horarios todavía no las generaron
+const maxScheduleDays = models.ScheduleDays
+
+// DepartureRequest estructura para programar una salida
+type DepartureRequest struct {
+	DepartsAt time.Time `json:"departs_at"`
+	Capacity  int       `json:"capacity"`
+	Vehicle   string    `json:"vehicle"`
+}
+
+// DepartureCapacityRequest estructura para cambiar la capacidad de una salida
+type DepartureCapacityRequest struct {
+	Capacity int `json:"capacity"`
+}
+
+// DepartureAssignmentRequest estructura para asignar conductor y vehículo a
+// una salida. Reemplaza ambos: un campo null u omitido quita esa asignación.
+type DepartureAssignmentRequest struct {
+	DriverID  *uuid.UUID `json:"driver_id"`
+	VehicleID *uuid.UUID `json:"vehicle_id"`
+}
+
+// ListRouteDepartures retorna las salidas de una ruta en una fecha con sus
+// asientos libres. Solo lee: las salidas de los horarios las genera el
+// worker de salidas y las altas o cambios de horarios.
+//
+// Request:
+// GET /routes/{id}/departures?date=2026-01-10
+//
+// date se interpreta en hora de Lima; por defecto es hoy.
+//
+// Response:
+// 200 OK
+// [
+//   {"id": "uuid", "route_id": "uuid", "timetable_id": "uuid | null",
+//    "departs_at": "2026-01-10T06:00:00-05:00", "capacity": 15, "seats_booked": 3,
+//    "seats_available": 12, "vehicle": "Combi ABC-123", "status": "scheduled", ...}
+// ]
+// 404 route_inactive si la ruta está desactivada
+func (h *Handler) ListRouteDepartures(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	now := time.Now().In(models.LocalZone)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, models.LocalZone)
+	date := today
+	if v := r.URL.Query().Get("date"); v != "" {
+		if date, err = time.ParseInLocation(time.DateOnly, v, models.LocalZone); err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeValidation, "date inválido (formato YYYY-MM-DD)")
+			return
+		}
+	}
+	if date.After(today.AddDate(0, 0, maxScheduleDays)) {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "date no puede estar a más de 60 días")
+		return
+	}
+
+	route, err := h.Routes.Get(r.Context(), routeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+	if !route.IsActive {
+		writeError(w, http.StatusNotFound, ErrCodeRouteInactive, "La ruta está desactivada")
+		return
+	}
+
+	departures, err := h.Departures.ListByRoute(r.Context(), routeID, date, date.AddDate(0, 0, 1))
+	if err != nil {
+		writeStoreError(w, err, "Error consultando salidas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(departures)
+}
+
+// CreateRouteDeparture programa una salida de la ruta
+//
+// Request:
+// POST /admin/routes/{id}/departures
+// {
+//   "departs_at": "2026-01-10T07:00:00-05:00",
+//   "capacity": 15,
+//   "vehicle": "Combi ABC-123"
+// }
+//
+// Response:
+// 201 Created (salida creada)
+// 409 Conflict si la ruta ya tiene una salida a esa hora
+func (h *Handler) CreateRouteDeparture(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req DepartureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if req.DepartsAt.IsZero() {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "departs_at es requerido")
+		return
+	}
+	if req.Capacity < 1 {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "capacity debe ser mayor o igual a 1")
+		return
+	}
+
+	if _, err := h.Routes.Get(r.Context(), routeID); errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	} else if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+
+	departure := &models.Departure{
+		RouteID:      routeID,
+		DepartsAt:    req.DepartsAt,
+		Capacity:     req.Capacity,
+		VehicleLabel: strings.TrimSpace(req.Vehicle),
+		Status:       models.DepartureStatusScheduled,
+	}
+	err = h.Departures.Create(r.Context(), departure)
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, ErrCodeDepartureExists, "La ruta ya tiene una salida a esa hora")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error creando salida")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(departure)
+}
+
+// UpdateDepartureCapacity cambia la capacidad de una salida
+//
+// Request:
+// PUT /admin/routes/{id}/departures/{departureID}
+// {
+//   "capacity": 20
+// }
+//
+// Response:
+// 200 OK (salida actualizada)
+// 409 Conflict si la capacidad es menor que los asientos ya reservados o
+// mayor que la del vehículo asignado
+func (h *Handler) UpdateDepartureCapacity(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	departureID, err := uuid.Parse(chi.URLParam(r, "departureID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de salida inválido")
+		return
+	}
+
+	var req DepartureCapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if req.Capacity < 1 {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, "capacity debe ser mayor o igual a 1")
+		return
+	}
+
+	departure, err := h.Departures.Get(r.Context(), departureID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && departure.RouteID != routeID) {
+		writeError(w, http.StatusNotFound, ErrCodeDepartureNotFound, "Salida no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando salida")
+		return
+	}
+
+	if departure.VehicleID != nil {
+		vehicle, err := h.Vehicles.Get(r.Context(), *departure.VehicleID)
+		if err != nil {
+			writeStoreError(w, err, "Error consultando vehículo")
+			return
+		}
+		if req.Capacity > vehicle.Capacity {
+			writeError(w, http.StatusConflict, ErrCodeVehicleTooSmall, "La capacidad no puede superar los asientos del vehículo asignado")
+			return
+		}
+	}
+
+	departure, err = h.Departures.SetCapacity(r.Context(), departureID, req.Capacity)
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, ErrCodeCapacityBelowBooked, "La capacidad no puede ser menor que los asientos reservados")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando salida")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(departure)
+}
+
+// CancelDeparture cancela una salida y los viajes reservados en ella que
+// aún no iniciaron
+//
+// Request:
+// POST /admin/routes/{id}/departures/{departureID}/cancel
+//
+// Response:
+// 200 OK (salida con status "cancelled")
+func (h *Handler) CancelDeparture(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	departureID, err := uuid.Parse(chi.URLParam(r, "departureID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de salida inválido")
+		return
+	}
+
+	departure, err := h.Departures.Get(r.Context(), departureID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && departure.RouteID != routeID) {
+		writeError(w, http.StatusNotFound, ErrCodeDepartureNotFound, "Salida no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando salida")
+		return
+	}
+
+	departure, err = h.Departures.Cancel(r.Context(), departureID)
+	if err != nil {
+		writeStoreError(w, err, "Error cancelando salida")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(departure)
+}
+
+// AssignDeparture asigna conductor y vehículo a una salida
+//
+// Request:
+// PUT /admin/routes/{id}/departures/{departureID}/assignment
+// {
+//   "driver_id": "uuid-del-conductor",
+//   "vehicle_id": "uuid-del-vehiculo"
+// }
+//
+// El conductor debe tener perfil activo, la licencia vigente el día de la
+// salida y estar asignado a la ruta. El vehículo debe estar activo, con SOAT
+// y revisión técnica vigentes el día de la salida y al menos tantos asientos
+// como la capacidad de la salida. Ninguno puede tener otra salida programada
+// a menos de 2 horas.
+//
+// Response:
+// 200 OK (salida con driver_id y vehicle_id)
+// 409 Conflict (driver_inactive, driver_license_expired, driver_not_on_route,
+// driver_busy, vehicle_inactive, vehicle_documents_expired,
+// vehicle_too_small, vehicle_busy o departure_closed)
+func (h *Handler) AssignDeparture(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	departureID, err := uuid.Parse(chi.URLParam(r, "departureID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de salida inválido")
+		return
+	}
+
+	var req DepartureAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+
+	departure, err := h.Departures.Get(r.Context(), departureID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && departure.RouteID != routeID) {
+		writeError(w, http.StatusNotFound, ErrCodeDepartureNotFound, "Salida no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando salida")
+		return
+	}
+	if departure.Status != models.DepartureStatusScheduled {
+		writeError(w, http.StatusConflict, ErrCodeDepartureClosed, "La salida fue cancelada")
+		return
+	}
+	day := models.DateOf(departure.DepartsAt)
+
+	if req.DriverID != nil {
+		driver, err := h.Drivers.Get(r.Context(), *req.DriverID)
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, ErrCodeDriverNotFound, "Conductor no encontrado")
+			return
+		}
+		if err != nil {
+			writeStoreError(w, err, "Error consultando conductor")
+			return
+		}
+		if !driver.IsActive {
+			writeError(w, http.StatusConflict, ErrCodeDriverInactive, "El conductor está inactivo")
+			return
+		}
+		if !driver.LicenseValidOn(day) {
+			writeErrorDetails(w, http.StatusConflict, ErrCodeDriverLicenseExpired, "La licencia del conductor vence antes de la salida",
+				map[string]any{"license_expires_on": driver.LicenseExpiresOn, "departure_date": day})
+			return
+		}
+		onRoute, err := h.Routes.HasDriver(r.Context(), routeID, driver.UserID)
+		if err != nil {
+			writeStoreError(w, err, "Error consultando asignación")
+			return
+		}
+		if !onRoute {
+			writeError(w, http.StatusConflict, ErrCodeDriverNotOnRoute, "El conductor no está asignado a la ruta")
+			return
+		}
+	}
+
+	if req.VehicleID != nil {
+		vehicle, err := h.Vehicles.Get(r.Context(), *req.VehicleID)
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, ErrCodeVehicleNotFound, "Vehículo no encontrado")
+			return
+		}
+		if err != nil {
+			writeStoreError(w, err, "Error consultando vehículo")
+			return
+		}
+		if !vehicle.IsActive {
+			writeError(w, http.StatusConflict, ErrCodeVehicleInactive, "El vehículo está inactivo")
+			return
+		}
+		if expired := vehicle.ExpiredDocuments(day); len(expired) > 0 {
+			writeErrorDetails(w, http.StatusConflict, ErrCodeVehicleDocumentsExpired, "El vehículo tiene documentos vencidos para la fecha de la salida",
+				map[string]any{"documents": expired, "departure_date": day})
+			return
+		}
+		if vehicle.Capacity < departure.Capacity {
+			writeError(w, http.StatusConflict, ErrCodeVehicleTooSmall, "El vehículo tiene menos asientos que la capacidad de la salida")
+			return
+		}
+	}
+
+	departure, err = h.Departures.Assign(r.Context(), departureID, req.DriverID, req.VehicleID)
+	switch {
+	case errors.Is(err, repository.ErrDriverBusy):
+		writeError(w, http.StatusConflict, ErrCodeDriverBusy, "El conductor ya tiene otra salida a menos de 2 horas")
+		return
+	case errors.Is(err, repository.ErrVehicleBusy):
+		writeError(w, http.StatusConflict, ErrCodeVehicleBusy, "El vehículo ya tiene otra salida a menos de 2 horas")
+		return
+	case err != nil:
+		writeStoreError(w, err, "Error asignando salida")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(departure)
+}
@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestConcurrentBookingNeverOversells(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	route, _ := s.route(admin, "A", "B")
+	departure := s.departure(route.ID, 3, 24*time.Hour)
+
+	const passengers = 10
+	tokens := make([]string, passengers)
+	for i := range tokens {
+		_, tokens[i] = s.login(models.RolePassenger)
+	}
+
+	var wg sync.WaitGroup
+	codes := make([]int, passengers)
+	for i, token := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = s.do(http.MethodPost, "/trips", token, map[string]any{
+				"route_id":       route.ID,
+				"departure_id":   departure.ID,
+				"payment_method": "cash",
+			}).Code
+		}()
+	}
+	wg.Wait()
+
+	booked := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			booked++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("status inesperado %d", code)
+		}
+	}
+	if booked != departure.Capacity {
+		t.Fatalf("%d reservas aceptadas, se esperaban %d", booked, departure.Capacity)
+	}
+	got, err := s.repos.Departures.Get(context.Background(), departure.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SeatsBooked != departure.Capacity || got.SeatsAvailable() != 0 {
+		t.Fatalf("seats_booked = %d, seats_available = %d", got.SeatsBooked, got.SeatsAvailable())
+	}
+}
+
+func TestDepartureCapacity(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+	departure := s.departure(route.ID, 2, 24*time.Hour)
+	s.bookTrip(passenger, departure)
+	s.bookTrip(passenger, departure)
+
+	path := "/admin/routes/" + route.ID.String() + "/departures/" + departure.ID.String()
+	assertError(t, s.do(http.MethodPut, path, admin, map[string]any{"capacity": 1}),
+		http.StatusConflict, handlers.ErrCodeCapacityBelowBooked)
+
+	got := decode[models.Departure](t, s.do(http.MethodPut, path, admin, map[string]any{"capacity": 3}), http.StatusOK)
+	if got.Capacity != 3 || got.SeatsBooked != 2 {
+		t.Fatalf("salida = %+v", got)
+	}
+	s.bookTrip(passenger, departure)
+}
+
+func TestTimetableDepartures(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	everyDay := map[string]any{"weekdays": []int{1, 2, 3, 4, 5, 6, 7}, "times": []string{"06:00", "18:30"}, "capacity": 12}
+	tomorrow := time.Now().In(models.LocalZone).AddDate(0, 0, 1).Format(time.DateOnly)
+
+	// Crear el horario de una ruta activa genera sus salidas
+	route, _ := s.route(admin, "A", "B")
+	timetable := decode[models.Timetable](t, s.do(http.MethodPost, "/admin/routes/"+route.ID.String()+"/timetables", admin, everyDay), http.StatusCreated)
+	path := "/routes/" + route.ID.String() + "/departures?date=" + tomorrow
+	departures := decode[[]models.Departure](t, s.do(http.MethodGet, path, "", nil), http.StatusOK)
+	if len(departures) != 2 || departures[0].TimetableID == nil || *departures[0].TimetableID != timetable.ID || departures[1].Capacity != 12 {
+		t.Fatalf("salidas = %+v", departures)
+	}
+
+	s.do(http.MethodPost, "/admin/routes/"+route.ID.String()+"/deactivate", admin, nil)
+	assertError(t, s.do(http.MethodGet, path, "", nil), http.StatusNotFound, handlers.ErrCodeRouteInactive)
+
+	// Una ruta desactivada no genera salidas, y consultarla al reactivarla
+	// tampoco: las genera el worker
+	inactive, _ := s.route(admin, "C", "D")
+	s.do(http.MethodPost, "/admin/routes/"+inactive.ID.String()+"/deactivate", admin, nil)
+	s.do(http.MethodPost, "/admin/routes/"+inactive.ID.String()+"/timetables", admin, everyDay)
+	s.do(http.MethodPost, "/admin/routes/"+inactive.ID.String()+"/activate", admin, nil)
+	path = "/routes/" + inactive.ID.String() + "/departures?date=" + tomorrow
+	if departures := decode[[]models.Departure](t, s.do(http.MethodGet, path, "", nil), http.StatusOK); len(departures) != 0 {
+		t.Fatalf("la consulta generó %d salidas", len(departures))
+	}
+}
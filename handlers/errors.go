@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// ErrorResponse es el cuerpo de todas las respuestas de error:
+//
+//	{"error": {"code": "route_not_found", "message": "Ruta no encontrada", "details": ...}}
+//
+// code es estable y es lo que deben comparar los clientes; message es
+// texto para mostrar y puede cambiar.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// APIError describe un error de la API
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// FieldError es el detalle de validación de un campo del request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"` // required, invalid
+	Message string `json:"message"`
+}
+
+// Códigos de error estables para los clientes
+const (
+	// Generales
+	ErrCodeInternal         = "internal_error"
+	ErrCodeInvalidJSON      = "invalid_json"
+	ErrCodeInvalidID        = "invalid_id"
+	ErrCodeValidation       = "validation_failed"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+	ErrCodeConflict         = "conflict"
+	ErrCodeConstraint       = "constraint_violation"
+	ErrCodeTimeout          = "timeout"
+	ErrCodeBodyTooLarge     = "body_too_large"
+
+	// Autenticación y permisos
+	ErrCodeUnauthorized   = "unauthorized"
+	ErrCodeInvalidSession = "invalid_session"
+	ErrCodeInvalidCode    = "invalid_code"
+	ErrCodeCodeRateLimit  = "code_rate_limited"
+	ErrCodeSMSFailed      = "sms_failed"
+	ErrCodeForbidden      = "forbidden"
+
+	// Recursos no encontrados
+	ErrCodeRouteNotFound      = "route_not_found"
+	ErrCodeStopNotFound       = "stop_not_found"
+	ErrCodeTripNotFound       = "trip_not_found"
+	ErrCodeUserNotFound       = "user_not_found"
+	ErrCodeDepartureNotFound  = "departure_not_found"
+	ErrCodeTimetableNotFound  = "timetable_not_found"
+	ErrCodeAssignmentNotFound = "assignment_not_found"
+
+	// Conflictos y reglas de negocio
+	ErrCodeInvalidTransition   = "invalid_transition"
+	ErrCodeRouteHasTrips       = "route_has_trips"
+	ErrCodeInvalidStopOrder    = "invalid_stop_order"
+	ErrCodeUserNotDriver       = "user_not_driver"
+	ErrCodeDepartureExists     = "departure_exists"
+	ErrCodeCapacityBelowBooked = "capacity_below_booked"
+	ErrCodeDepartureFull       = "departure_full"
+	ErrCodeDepartureClosed     = "departure_closed"
+	ErrCodeNoFare              = "no_fare_for_segment"
+	ErrCodeRouteInactive       = "route_inactive"
+
+	// Idempotency-Key
+	ErrCodeIdempotencyKeyReused   = "idempotency_key_reused"
+	ErrCodeIdempotencyKeyInFlight = "idempotency_key_in_progress"
+
+	// Conductores y vehículos
+	ErrCodeDriverNotFound          = "driver_not_found"
+	ErrCodeVehicleNotFound         = "vehicle_not_found"
+	ErrCodeDriverExists            = "driver_exists"
+	ErrCodePlateExists             = "plate_exists"
+	ErrCodeDriverInactive          = "driver_inactive"
+	ErrCodeDriverLicenseExpired    = "driver_license_expired"
+	ErrCodeDriverNotOnRoute        = "driver_not_on_route"
+	ErrCodeDriverBusy              = "driver_busy"
+	ErrCodeVehicleInactive         = "vehicle_inactive"
+	ErrCodeVehicleDocumentsExpired = "vehicle_documents_expired"
+	ErrCodeVehicleTooSmall         = "vehicle_too_small"
+	ErrCodeVehicleBusy             = "vehicle_busy"
+
+	// Pases de abordar
+	ErrCodeTripNotConfirmed    = "trip_not_confirmed"
+	ErrCodeInvalidBoardingPass = "invalid_boarding_pass"
+	ErrCodeWrongDeparture      = "wrong_departure"
+	ErrCodeBoardingPassUsed    = "boarding_pass_used"
+
+	// Importación GTFS
+	ErrCodeInvalidGTFS = "invalid_gtfs"
+
+	// Tramos pickup → dropoff
+	ErrCodeStopNotOnRoute    = "stop_not_on_route"
+	ErrCodeStopInactive      = "stop_inactive"
+	ErrCodeZeroLengthSegment = "zero_length_segment"
+	ErrCodeStopOrderReversed = "stop_order_reversed"
+	ErrCodeRouteWithoutStops = "route_without_stops"
+)
+
+// writeError responde con un ErrorResponse en JSON
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeErrorDetails(w, status, code, message, nil)
+}
+
+// writeErrorDetails responde con un ErrorResponse que incluye details
+func writeErrorDetails(w http.ResponseWriter, status int, code, message string, details any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: APIError{Code: code, Message: message, Details: details}})
+}
+
+// writeStoreError responde a un error inesperado del repositorio según su
+// tipo (ver db.MapError): 404 si no existe, 409 si choca con datos
+// existentes, 422 si viola una restricción, 504 si se canceló y 500 en
+// otro caso. Los handlers que esperan un error concreto (por ejemplo,
+// ErrNotFound con un código más específico) lo revisan antes.
+func writeStoreError(w http.ResponseWriter, err error, message string) {
+	err = db.MapError(err)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "Recurso no encontrado")
+	case errors.Is(err, repository.ErrConflict),
+		errors.Is(err, db.ErrUniqueViolation),
+		errors.Is(err, db.ErrForeignKeyViolation):
+		writeError(w, http.StatusConflict, ErrCodeConflict, "Conflicto con datos existentes")
+	case errors.Is(err, db.ErrCheckViolation):
+		writeError(w, http.StatusUnprocessableEntity, ErrCodeConstraint, "Los datos no cumplen las restricciones")
+	case errors.Is(err, db.ErrCanceled):
+		writeError(w, http.StatusGatewayTimeout, ErrCodeTimeout, "La operación tardó demasiado o fue cancelada")
+	default:
+		log.Printf("%s: %v", message, err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, message)
+	}
+}
+
+// writeValidationErrors responde 400 validation_failed con el detalle por campo
+func writeValidationErrors(w http.ResponseWriter, fields []FieldError) {
+	writeErrorDetails(w, http.StatusBadRequest, ErrCodeValidation, "Datos inválidos", fields)
+}
+
+// NotFound responde a rutas inexistentes con el formato de error de la API
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, ErrCodeNotFound, "Recurso no encontrado")
+}
+
+// MethodNotAllowed responde a métodos no soportados con el formato de error de la API
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Método no permitido")
+}
+
+// segmentErrorCodes asocia los errores de models.Segment con su código
+var segmentErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{models.ErrStopNotOnRoute, ErrCodeStopNotOnRoute},
+	{models.ErrStopInactive, ErrCodeStopInactive},
+	{models.ErrZeroLengthSegment, ErrCodeZeroLengthSegment},
+	{models.ErrStopOrderReversed, ErrCodeStopOrderReversed},
+	{models.ErrRouteWithoutStops, ErrCodeRouteWithoutStops},
+}
+
+// writeSegmentError responde 400 con el código del tramo inválido
+func writeSegmentError(w http.ResponseWriter, err error) {
+	for _, e := range segmentErrorCodes {
+		if errors.Is(err, e.err) {
+			writeError(w, http.StatusBadRequest, e.code, err.Error())
+			return
+		}
+	}
+	writeError(w, http.StatusBadRequest, ErrCodeStopNotOnRoute, err.Error())
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// FareRuleRequest estructura para crear una nueva versión de tarifa
+type FareRuleRequest struct {
+	Kind         string                   `json:"kind"` // flat, per_stop, distance, matrix
+	BaseCents    int                      `json:"base_cents"`
+	PerStopCents int                      `json:"per_stop_cents"`
+	PerKmCents   int                      `json:"per_km_cents"`
+	Matrix       []models.FareMatrixEntry `json:"matrix"`
+}
+
+// quoteFare calcula el precio del tramo con la tarifa vigente de la ruta.
+// Sin tarifa por tramo se cobra base_price_cents de la ruta.
+func (h *Handler) quoteFare(ctx context.Context, route *models.Route, path []models.RouteStop) (*models.FareQuote, error) {
+	quote := &models.FareQuote{
+		RouteID:    route.ID,
+		PriceCents: route.BasePriceCents,
+		Currency:   route.Currency,
+		Kind:       "base",
+	}
+	if len(path) > 0 {
+		quote.PickupStopID = &path[0].ID
+		quote.DropoffStopID = &path[len(path)-1].ID
+	}
+
+	rule, err := h.Fares.Current(ctx, route.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		quote.Price = float64(quote.PriceCents) / 100.0
+		return quote, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := rule.Price(path)
+	if err != nil {
+		return nil, err
+	}
+	quote.PriceCents = price
+	quote.Price = float64(price) / 100.0
+	quote.FareRuleID = &rule.ID
+	quote.FareRuleVersion = &rule.Version
+	quote.Kind = rule.Kind
+	return quote, nil
+}
+
+// GetRouteFare cotiza el precio de un tramo de la ruta
+//
+// Request:
+// GET /routes/{id}/fare?pickup_stop_id=uuid&dropoff_stop_id=uuid
+//
+// Sin paradas se cotiza la ruta completa.
+//
+// Response:
+// 200 OK
+// {
+//   "route_id": "uuid",
+//   "pickup_stop_id": "uuid",
+//   "dropoff_stop_id": "uuid",
+//   "price_cents": 350,
+//   "price": 3.50,
+//   "currency": "PEN",
+//   "fare_rule_id": "uuid | null",
+//   "fare_rule_version": 2,
+//   "kind": "per_stop"
+// }
+func (h *Handler) GetRouteFare(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var pickupID, dropoffID *uuid.UUID
+	for param, dst := range map[string]**uuid.UUID{"pickup_stop_id": &pickupID, "dropoff_stop_id": &dropoffID} {
+		if v := r.URL.Query().Get(param); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, ErrCodeInvalidID, param+" inválido")
+				return
+			}
+			*dst = &id
+		}
+	}
+
+	route, err := h.Routes.Get(r.Context(), routeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+
+	stops, err := h.Stops.ListByRoute(r.Context(), routeID, false)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando paradas")
+		return
+	}
+	path, err := models.Segment(stops, pickupID, dropoffID)
+	if err != nil {
+		writeSegmentError(w, err)
+		return
+	}
+
+	quote, err := h.quoteFare(r.Context(), route, path)
+	if errors.Is(err, models.ErrNoFare) {
+		writeError(w, http.StatusBadRequest, ErrCodeNoFare, "No hay tarifa para este tramo")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error calculando tarifa")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+// ListRouteFares retorna todas las versiones de tarifa de la ruta,
+// la vigente primero
+//
+// Request:
+// GET /admin/routes/{id}/fares
+//
+// Response:
+// 200 OK
+// [
+//   {"id": "uuid", "route_id": "uuid", "version": 2, "kind": "per_stop",
+//    "base_cents": 150, "per_stop_cents": 100, "per_km_cents": 0, "matrix": [], ...}
+// ]
+func (h *Handler) ListRouteFares(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	rules, err := h.Fares.ListByRoute(r.Context(), routeID)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando tarifas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// CreateRouteFare publica una nueva versión de tarifa para la ruta.
+// Los viajes ya reservados conservan el precio y la versión con que se
+// crearon.
+//
+// Request:
+// POST /admin/routes/{id}/fares
+// {
+//   "kind": "per_stop",
+//   "base_cents": 150,
+//   "per_stop_cents": 100
+// }
+//
+// Para kind "matrix":
+// {
+//   "kind": "matrix",
+//   "matrix": [
+//     {"from_stop_id": "uuid", "to_stop_id": "uuid", "price_cents": 300}
+//   ]
+// }
+//
+// Response:
+// 201 Created (regla con su número de versión)
+func (h *Handler) CreateRouteFare(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req FareRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	rule := &models.FareRule{
+		RouteID:      routeID,
+		Kind:         req.Kind,
+		BaseCents:    req.BaseCents,
+		PerStopCents: req.PerStopCents,
+		PerKmCents:   req.PerKmCents,
+		Matrix:       req.Matrix,
+	}
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, err.Error())
+		return
+	}
+
+	// Las paradas de la matriz deben ser de la ruta y respetar el sentido
+	if rule.Kind == models.FareKindMatrix {
+		stops, err := h.Stops.ListByRoute(r.Context(), routeID, false)
+		if err != nil {
+			writeStoreError(w, err, "Error consultando paradas")
+			return
+		}
+		for _, e := range rule.Matrix {
+			// Se permiten paradas inactivas: pueden volver a activarse
+			from, to := e.FromStopID, e.ToStopID
+			if _, err := models.Segment(stops, &from, &to); err != nil && !errors.Is(err, models.ErrStopInactive) {
+				writeError(w, http.StatusBadRequest, ErrCodeValidation, "matrix: "+err.Error())
+				return
+			}
+		}
+	}
+
+	err = h.Fares.Create(r.Context(), rule)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error creando tarifa")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
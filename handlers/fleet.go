@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// DriverRequest estructura para crear o editar el perfil de un conductor.
+// UserID solo se usa al crear; is_active es true por defecto al crear y al
+// editar se conserva si se omite.
+type DriverRequest struct {
+	UserID           uuid.UUID    `json:"user_id"`
+	LicenseNumber    string       `json:"license_number"`
+	LicenseExpiresOn *models.Date `json:"license_expires_on"`
+	IsActive         *bool        `json:"is_active"`
+}
+
+// VehicleRequest estructura para crear o editar un vehículo. is_active es
+// true por defecto al crear y al editar se conserva si se omite.
+type VehicleRequest struct {
+	Plate               string       `json:"plate"`
+	Model               string       `json:"model"`
+	Capacity            int          `json:"capacity"`
+	SOATExpiresOn       *models.Date `json:"soat_expires_on"`
+	InspectionExpiresOn *models.Date `json:"inspection_expires_on"`
+	IsActive            *bool        `json:"is_active"`
+}
+
+// platePattern valida la placa ya normalizada (ABC-123 se guarda ABC123)
+var platePattern = regexp.MustCompile(`^[A-Z0-9]{5,8}$`)
+
+// validate normaliza el perfil y retorna los errores de validación
+func (req *DriverRequest) validate() []FieldError {
+	req.LicenseNumber = strings.ToUpper(strings.TrimSpace(req.LicenseNumber))
+
+	var errs []FieldError
+	if req.LicenseNumber == "" {
+		errs = append(errs, FieldError{Field: "license_number", Code: "required", Message: "license_number es requerido"})
+	}
+	if req.LicenseExpiresOn == nil {
+		errs = append(errs, FieldError{Field: "license_expires_on", Code: "required", Message: "license_expires_on es requerido (YYYY-MM-DD)"})
+	}
+	return errs
+}
+
+// validate normaliza el vehículo y retorna los errores de validación
+func (req *VehicleRequest) validate() []FieldError {
+	// Sin espacios ni guiones, para que una placa no se registre dos veces
+	req.Plate = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(req.Plate))
+	req.Model = strings.TrimSpace(req.Model)
+
+	var errs []FieldError
+	switch {
+	case req.Plate == "":
+		errs = append(errs, FieldError{Field: "plate", Code: "required", Message: "plate es requerido"})
+	case !platePattern.MatchString(req.Plate):
+		errs = append(errs, FieldError{Field: "plate", Code: "invalid", Message: "plate inválida (ej: ABC-123)"})
+	}
+	if req.Capacity < 1 {
+		errs = append(errs, FieldError{Field: "capacity", Code: "invalid", Message: "capacity debe ser mayor o igual a 1"})
+	}
+	if req.SOATExpiresOn == nil {
+		errs = append(errs, FieldError{Field: "soat_expires_on", Code: "required", Message: "soat_expires_on es requerido (YYYY-MM-DD)"})
+	}
+	if req.InspectionExpiresOn == nil {
+		errs = append(errs, FieldError{Field: "inspection_expires_on", Code: "required", Message: "inspection_expires_on es requerido (YYYY-MM-DD)"})
+	}
+	return errs
+}
+
+// ListDrivers retorna los perfiles de conductor
+//
+// Request:
+// GET /admin/drivers
+//
+// Response:
+// 200 OK
+// [
+//   {"user_id": "uuid", "name": "Juan Pérez", "phone": "+51987654321",
+//    "license_number": "Q12345678", "license_expires_on": "2027-03-31", "is_active": true, ...}
+// ]
+func (h *Handler) ListDrivers(w http.ResponseWriter, r *http.Request) {
+	drivers, err := h.Drivers.List(r.Context())
+	if err != nil {
+		writeStoreError(w, err, "Error consultando conductores")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(drivers)
+}
+
+// GetDriver retorna el perfil de un conductor
+//
+// Request:
+// GET /admin/drivers/{id}
+//
+// Response:
+// 200 OK (perfil del conductor)
+func (h *Handler) GetDriver(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de conductor inválido")
+		return
+	}
+
+	driver, err := h.Drivers.Get(r.Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeDriverNotFound, "Conductor no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando conductor")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(driver)
+}
+
+// CreateDriver registra el perfil de conductor de un usuario con rol driver
+//
+// Request:
+// POST /admin/drivers
+// {
+//   "user_id": "uuid-del-usuario",
+//   "license_number": "Q12345678",
+//   "license_expires_on": "2027-03-31"
+// }
+//
+// Response:
+// 201 Created (perfil creado)
+// 409 Conflict si el usuario ya tiene perfil
+func (h *Handler) CreateDriver(w http.ResponseWriter, r *http.Request) {
+	var req DriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	errs := req.validate()
+	if req.UserID == uuid.Nil {
+		errs = append([]FieldError{{Field: "user_id", Code: "required", Message: "user_id es requerido"}}, errs...)
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	user, err := h.Users.Get(r.Context(), req.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		writeStoreError(w, err, "Error consultando usuario")
+		return
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, ErrCodeUserNotFound, "Usuario no encontrado")
+		return
+	}
+	if user.Role != models.RoleDriver {
+		writeError(w, http.StatusBadRequest, ErrCodeUserNotDriver, "El usuario no es conductor; cambia su rol a driver primero")
+		return
+	}
+
+	driver := &models.Driver{
+		UserID:           req.UserID,
+		LicenseNumber:    req.LicenseNumber,
+		LicenseExpiresOn: *req.LicenseExpiresOn,
+		IsActive:         req.IsActive == nil || *req.IsActive,
+	}
+	err = h.Drivers.Create(r.Context(), driver)
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, ErrCodeDriverExists, "El usuario ya tiene perfil de conductor")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error creando conductor")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(driver)
+}
+
+// UpdateDriver edita licencia, vencimiento y estado de un conductor.
+// Un conductor inactivo o con la licencia vencida no se puede asignar a
+// salidas; las asignaciones que ya tiene se conservan.
+//
+// Request:
+// PUT /admin/drivers/{id}
+// {
+//   "license_number": "Q12345678",
+//   "license_expires_on": "2028-03-31",
+//   "is_active": false
+// }
+//
+// Response:
+// 200 OK (perfil actualizado)
+func (h *Handler) UpdateDriver(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de conductor inválido")
+		return
+	}
+
+	var req DriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	driver, err := h.Drivers.Get(r.Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeDriverNotFound, "Conductor no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando conductor")
+		return
+	}
+
+	driver.LicenseNumber = req.LicenseNumber
+	driver.LicenseExpiresOn = *req.LicenseExpiresOn
+	if req.IsActive != nil {
+		driver.IsActive = *req.IsActive
+	}
+	if err := h.Drivers.Update(r.Context(), driver); err != nil {
+		writeStoreError(w, err, "Error actualizando conductor")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(driver)
+}
+
+// ListVehicles retorna los vehículos de la flota
+//
+// Request:
+// GET /admin/vehicles
+//
+// Response:
+// 200 OK
+// [
+//   {"id": "uuid", "plate": "ABC-123", "model": "Toyota Hiace", "capacity": 15,
+//    "soat_expires_on": "2026-12-31", "inspection_expires_on": "2026-08-15", "is_active": true, ...}
+// ]
+func (h *Handler) ListVehicles(w http.ResponseWriter, r *http.Request) {
+	vehicles, err := h.Vehicles.List(r.Context())
+	if err != nil {
+		writeStoreError(w, err, "Error consultando vehículos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vehicles)
+}
+
+// GetVehicle retorna un vehículo
+//
+// Request:
+// GET /admin/vehicles/{id}
+//
+// Response:
+// 200 OK (vehículo)
+func (h *Handler) GetVehicle(w http.ResponseWriter, r *http.Request) {
+	vehicleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de vehículo inválido")
+		return
+	}
+
+	vehicle, err := h.Vehicles.Get(r.Context(), vehicleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeVehicleNotFound, "Vehículo no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando vehículo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vehicle)
+}
+
+// CreateVehicle registra un vehículo
+//
+// Request:
+// POST /admin/vehicles
+// {
+//   "plate": "ABC-123",
+//   "model": "Toyota Hiace",
+//   "capacity": 15,
+//   "soat_expires_on": "2026-12-31",
+//   "inspection_expires_on": "2026-08-15"
+// }
+//
+// Response:
+// 201 Created (vehículo creado)
+// 409 Conflict si la placa ya está registrada
+func (h *Handler) CreateVehicle(w http.ResponseWriter, r *http.Request) {
+	var req VehicleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	vehicle := &models.Vehicle{
+		Plate:               req.Plate,
+		Model:               req.Model,
+		Capacity:            req.Capacity,
+		SOATExpiresOn:       *req.SOATExpiresOn,
+		InspectionExpiresOn: *req.InspectionExpiresOn,
+		IsActive:            req.IsActive == nil || *req.IsActive,
+	}
+	err := h.Vehicles.Create(r.Context(), vehicle)
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, ErrCodePlateExists, "Ya existe un vehículo con esa placa")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error creando vehículo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(vehicle)
+}
+
+// UpdateVehicle reemplaza los datos de un vehículo. Un vehículo inactivo o
+// con documentos vencidos no se puede asignar a salidas; las asignaciones
+// que ya tiene se conservan.
+//
+// Request:
+// PUT /admin/vehicles/{id}
+// (mismo cuerpo que POST /admin/vehicles, con is_active opcional)
+//
+// Response:
+// 200 OK (vehículo actualizado)
+func (h *Handler) UpdateVehicle(w http.ResponseWriter, r *http.Request) {
+	vehicleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de vehículo inválido")
+		return
+	}
+
+	var req VehicleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	vehicle, err := h.Vehicles.Get(r.Context(), vehicleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeVehicleNotFound, "Vehículo no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando vehículo")
+		return
+	}
+
+	vehicle.Plate = req.Plate
+	vehicle.Model = req.Model
+	vehicle.Capacity = req.Capacity
+	vehicle.SOATExpiresOn = *req.SOATExpiresOn
+	vehicle.InspectionExpiresOn = *req.InspectionExpiresOn
+	if req.IsActive != nil {
+		vehicle.IsActive = *req.IsActive
+	}
+	err = h.Vehicles.Update(r.Context(), vehicle)
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, ErrCodePlateExists, "Ya existe un vehículo con esa placa")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando vehículo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vehicle)
+}
@@ -0,0 +1,182 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+// dateIn retorna la fecha YYYY-MM-DD de dentro de days días
+func dateIn(days int) string {
+	return time.Now().AddDate(0, 0, days).Format(time.DateOnly)
+}
+
+// driver registra el perfil de conductor de un usuario nuevo con la licencia dada
+func (s *testServer) driver(adminToken, licenseExpiresOn string) models.Driver {
+	s.t.Helper()
+	user, _ := s.login(models.RoleDriver)
+	rec := s.do(http.MethodPost, "/admin/drivers", adminToken, map[string]any{
+		"user_id":            user.ID,
+		"license_number":     "Q" + user.Phone[len(user.Phone)-8:],
+		"license_expires_on": licenseExpiresOn,
+	})
+	return decode[models.Driver](s.t, rec, http.StatusCreated)
+}
+
+// vehicle registra un vehículo con la capacidad y el vencimiento de SOAT dados
+func (s *testServer) vehicle(adminToken, plate string, capacity int, soatExpiresOn string) models.Vehicle {
+	s.t.Helper()
+	rec := s.do(http.MethodPost, "/admin/vehicles", adminToken, map[string]any{
+		"plate":                 plate,
+		"model":                 "Toyota Hiace",
+		"capacity":              capacity,
+		"soat_expires_on":       soatExpiresOn,
+		"inspection_expires_on": dateIn(365),
+	})
+	return decode[models.Vehicle](s.t, rec, http.StatusCreated)
+}
+
+func TestDrivers(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	passenger, passengerToken := s.login(models.RolePassenger)
+
+	driver := s.driver(admin, dateIn(365))
+	if !driver.IsActive || driver.Name == "" {
+		t.Fatalf("conductor = %+v", driver)
+	}
+	assertError(t, s.do(http.MethodPost, "/admin/drivers", admin, map[string]any{
+		"user_id": driver.UserID, "license_number": "Q1", "license_expires_on": dateIn(365),
+	}), http.StatusConflict, handlers.ErrCodeDriverExists)
+	assertError(t, s.do(http.MethodPost, "/admin/drivers", admin, map[string]any{
+		"user_id": passenger.ID, "license_number": "Q2", "license_expires_on": dateIn(365),
+	}), http.StatusBadRequest, handlers.ErrCodeUserNotDriver)
+	assertError(t, s.do(http.MethodPost, "/admin/drivers", admin, map[string]any{
+		"user_id": uuid.New(), "license_number": "Q3", "license_expires_on": dateIn(365),
+	}), http.StatusNotFound, handlers.ErrCodeUserNotFound)
+	assertError(t, s.do(http.MethodPost, "/admin/drivers", admin, map[string]any{"user_id": driver.UserID}),
+		http.StatusBadRequest, handlers.ErrCodeValidation)
+
+	// is_active se conserva si se omite
+	path := "/admin/drivers/" + driver.UserID.String()
+	updated := decode[models.Driver](t, s.do(http.MethodPut, path, admin, map[string]any{
+		"license_number": " q999 ", "license_expires_on": dateIn(30), "is_active": false,
+	}), http.StatusOK)
+	if updated.IsActive || updated.LicenseNumber != "Q999" || updated.LicenseExpiresOn.String() != dateIn(30) {
+		t.Fatalf("conductor actualizado = %+v", updated)
+	}
+	updated = decode[models.Driver](t, s.do(http.MethodPut, path, admin, map[string]any{
+		"license_number": "Q999", "license_expires_on": dateIn(60),
+	}), http.StatusOK)
+	if updated.IsActive {
+		t.Fatal("is_active cambió sin enviarlo")
+	}
+	assertError(t, s.do(http.MethodPut, "/admin/drivers/"+uuid.NewString(), admin, map[string]any{
+		"license_number": "Q1", "license_expires_on": dateIn(30),
+	}), http.StatusNotFound, handlers.ErrCodeDriverNotFound)
+
+	assertError(t, s.do(http.MethodGet, "/admin/drivers", passengerToken, nil), http.StatusForbidden, handlers.ErrCodeForbidden)
+}
+
+func TestVehicles(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+
+	hiace := s.vehicle(admin, "abc-123", 15, dateIn(365))
+	if hiace.Plate != "ABC123" || !hiace.IsActive {
+		t.Fatalf("vehículo = %+v", hiace)
+	}
+	other := s.vehicle(admin, "XYZ 789", 12, dateIn(365))
+
+	// La placa se compara normalizada
+	body := map[string]any{
+		"plate": "ABC 123", "model": "Hyundai H1", "capacity": 10,
+		"soat_expires_on": dateIn(365), "inspection_expires_on": dateIn(365),
+	}
+	assertError(t, s.do(http.MethodPost, "/admin/vehicles", admin, body), http.StatusConflict, handlers.ErrCodePlateExists)
+	assertError(t, s.do(http.MethodPut, "/admin/vehicles/"+other.ID.String(), admin, body), http.StatusConflict, handlers.ErrCodePlateExists)
+
+	body["plate"] = "XYZ-789"
+	updated := decode[models.Vehicle](t, s.do(http.MethodPut, "/admin/vehicles/"+other.ID.String(), admin, body), http.StatusOK)
+	if updated.Model != "Hyundai H1" || updated.Capacity != 10 || !updated.IsActive {
+		t.Fatalf("vehículo actualizado = %+v", updated)
+	}
+
+	for _, invalid := range []map[string]any{
+		{"plate": "AB", "capacity": 10, "soat_expires_on": dateIn(1), "inspection_expires_on": dateIn(1)},
+		{"plate": "DEF456", "capacity": 0, "soat_expires_on": dateIn(1), "inspection_expires_on": dateIn(1)},
+		{"plate": "DEF456", "capacity": 10},
+	} {
+		assertError(t, s.do(http.MethodPost, "/admin/vehicles", admin, invalid), http.StatusBadRequest, handlers.ErrCodeValidation)
+	}
+	assertError(t, s.do(http.MethodGet, "/admin/vehicles/"+uuid.NewString(), admin, nil), http.StatusNotFound, handlers.ErrCodeVehicleNotFound)
+}
+
+func TestAssignDeparture(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+
+	onRoute := func(driver models.Driver) models.Driver {
+		rec := s.do(http.MethodPost, "/admin/routes/"+route.ID.String()+"/drivers", admin, map[string]any{"driver_id": driver.UserID})
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("asignando conductor a la ruta: %d %s", rec.Code, rec.Body)
+		}
+		return driver
+	}
+	driver := onRoute(s.driver(admin, dateIn(365)))
+	expiredLicense := onRoute(s.driver(admin, dateIn(-1)))
+	offRoute := s.driver(admin, dateIn(365))
+	van := s.vehicle(admin, "ABC123", 15, dateIn(365))
+	expiredSOAT := s.vehicle(admin, "DEF456", 15, dateIn(-1))
+	small := s.vehicle(admin, "GHI789", 2, dateIn(365))
+
+	departure := s.departure(route.ID, 10, 24*time.Hour)
+	s.bookTrip(passenger, departure)
+	s.bookTrip(passenger, departure)
+	s.bookTrip(passenger, departure)
+	path := "/admin/routes/" + route.ID.String() + "/departures/" + departure.ID.String() + "/assignment"
+
+	tests := []struct {
+		name   string
+		body   map[string]any
+		status int
+		code   string
+	}{
+		{"licencia vencida", map[string]any{"driver_id": expiredLicense.UserID}, http.StatusConflict, handlers.ErrCodeDriverLicenseExpired},
+		{"conductor fuera de la ruta", map[string]any{"driver_id": offRoute.UserID}, http.StatusConflict, handlers.ErrCodeDriverNotOnRoute},
+		{"conductor inexistente", map[string]any{"driver_id": uuid.New()}, http.StatusNotFound, handlers.ErrCodeDriverNotFound},
+		{"SOAT vencido", map[string]any{"vehicle_id": expiredSOAT.ID}, http.StatusConflict, handlers.ErrCodeVehicleDocumentsExpired},
+		{"menos asientos que los reservados", map[string]any{"vehicle_id": small.ID}, http.StatusConflict, handlers.ErrCodeVehicleTooSmall},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertError(t, s.do(http.MethodPut, path, admin, tt.body), tt.status, tt.code)
+		})
+	}
+
+	assigned := decode[models.Departure](t, s.do(http.MethodPut, path, admin, map[string]any{
+		"driver_id": driver.UserID, "vehicle_id": van.ID,
+	}), http.StatusOK)
+	if assigned.DriverID == nil || *assigned.DriverID != driver.UserID || assigned.VehicleID == nil || *assigned.VehicleID != van.ID {
+		t.Fatalf("salida asignada = %+v", assigned)
+	}
+
+	// Una hora después conductor y vehículo siguen ocupados
+	overlapping := s.departure(route.ID, 10, 25*time.Hour)
+	overlapPath := "/admin/routes/" + route.ID.String() + "/departures/" + overlapping.ID.String() + "/assignment"
+	assertError(t, s.do(http.MethodPut, overlapPath, admin, map[string]any{"driver_id": driver.UserID}),
+		http.StatusConflict, handlers.ErrCodeDriverBusy)
+	assertError(t, s.do(http.MethodPut, overlapPath, admin, map[string]any{"vehicle_id": van.ID}),
+		http.StatusConflict, handlers.ErrCodeVehicleBusy)
+
+	// Con dos horas de diferencia ya no se cruzan
+	later := s.departure(route.ID, 10, 26*time.Hour)
+	laterPath := "/admin/routes/" + route.ID.String() + "/departures/" + later.ID.String() + "/assignment"
+	decode[models.Departure](t, s.do(http.MethodPut, laterPath, admin, map[string]any{"driver_id": driver.UserID, "vehicle_id": van.ID}), http.StatusOK)
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/geo"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// maxPathPoints limita los puntos del trazado guardado de una ruta
+const maxPathPoints = 10000
+
+// Valores de la propiedad path_source del LineString
+const (
+	pathSourcePolyline = "polyline" // trazado guardado con PUT /admin/routes/{id}/path
+	pathSourceStops    = "stops"    // línea recta origen → paradas → destino
+)
+
+// RoutePathRequest estructura para guardar el trazado de una ruta. Se envía
+// polyline (codificada, 5 decimales) o coordinates en orden GeoJSON
+// [lon, lat], no ambos.
+type RoutePathRequest struct {
+	Polyline    string       `json:"polyline"`
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+// RoutePathResponse es el trazado guardado
+type RoutePathResponse struct {
+	Polyline     string  `json:"polyline"`
+	Points       int     `json:"points"`
+	LengthMeters float64 `json:"length_m"`
+}
+
+// GetRouteGeoJSON retorna la geometría de una ruta activa para mapas
+//
+// Request:
+// GET /routes/{id}.geojson
+//
+// Response:
+// 200 OK (application/geo+json)
+// {
+//   "type": "FeatureCollection",
+//   "features": [
+//     {
+//       "type": "Feature",
+//       "id": "uuid",
+//       "geometry": {"type": "LineString", "coordinates": [[-77.0428, -12.0464], ...]},
+//       "properties": {"kind": "route", "route_id": "uuid", "name": "Ruta Centro - Norte",
+//                      "origin_name": "Centro", "destination_name": "Norte", "path_source": "polyline"}
+//     },
+//     {
+//       "type": "Feature",
+//       "id": "uuid",
+//       "geometry": {"type": "Point", "coordinates": [-77.0428, -12.0464]},
+//       "properties": {"kind": "stop", "route_id": "uuid", "name": "Plaza de Armas", "order": 1}
+//     }
+//   ]
+// }
+//
+// El LineString usa el trazado guardado de la ruta (path_source "polyline")
+// o, si no tiene, une origen, paradas activas y destino en línea recta
+// (path_source "stops"). Las paradas van en orden de recorrido.
+func (h *Handler) GetRouteGeoJSON(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	route, err := h.Routes.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !route.IsActive) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+
+	features, err := h.routeFeatures(r.Context(), route)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando geometría de la ruta")
+		return
+	}
+
+	writeGeoJSON(w, models.NewFeatureCollection(features))
+}
+
+// GetRoutesGeoJSON retorna la geometría de todas las rutas activas
+//
+// Request:
+// GET /routes.geojson
+//
+// Response:
+// 200 OK (application/geo+json; mismo formato que GET /routes/{id}.geojson,
+// con un LineString y sus paradas por ruta, ordenadas por nombre de ruta)
+func (h *Handler) GetRoutesGeoJSON(w http.ResponseWriter, r *http.Request) {
+	routes, err := h.Routes.ListActive(r.Context(), repository.RouteFilter{Sort: models.RouteSortName})
+	if err != nil {
+		writeStoreError(w, err, "Error consultando rutas")
+		return
+	}
+
+	features := []models.Feature{}
+	for i := range routes {
+		routeFeatures, err := h.routeFeatures(r.Context(), &routes[i])
+		if err != nil {
+			writeStoreError(w, err, "Error consultando geometría de las rutas")
+			return
+		}
+		features = append(features, routeFeatures...)
+	}
+
+	writeGeoJSON(w, models.NewFeatureCollection(features))
+}
+
+// routeFeatures arma el LineString de la ruta seguido de sus paradas activas
+func (h *Handler) routeFeatures(ctx context.Context, route *models.Route) ([]models.Feature, error) {
+	stops, err := h.Stops.ListByRoute(ctx, route.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	polyline, err := h.Routes.Path(ctx, route.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	source := pathSourceStops
+	var line [][2]float64
+	if points, err := geo.DecodePolyline(polyline); err == nil && len(points) >= 2 {
+		source = pathSourcePolyline
+		for _, p := range points {
+			line = append(line, [2]float64{p.Lon, p.Lat})
+		}
+	} else {
+		add := func(lat, lon float64) {
+			// Se omiten puntos repetidos, por ejemplo origen y primera parada
+			if n := len(line); n > 0 && line[n-1] == [2]float64{lon, lat} {
+				return
+			}
+			line = append(line, [2]float64{lon, lat})
+		}
+		add(route.OriginLat, route.OriginLon)
+		for _, stop := range stops {
+			add(stop.Latitude, stop.Longitude)
+		}
+		add(route.DestinationLat, route.DestinationLon)
+	}
+
+	features := make([]models.Feature, 0, len(stops)+1)
+	features = append(features, models.Feature{
+		Type:     "Feature",
+		ID:       route.ID.String(),
+		Geometry: models.LineStringGeometry(line),
+		Properties: map[string]any{
+			"kind":             "route",
+			"route_id":         route.ID,
+			"name":             route.Name,
+			"origin_name":      route.OriginName,
+			"destination_name": route.DestinationName,
+			"path_source":      source,
+		},
+	})
+	for _, stop := range stops {
+		features = append(features, models.Feature{
+			Type:     "Feature",
+			ID:       stop.ID.String(),
+			Geometry: models.PointGeometry(stop.Latitude, stop.Longitude),
+			Properties: map[string]any{
+				"kind":     "stop",
+				"route_id": route.ID,
+				"name":     stop.Name,
+				"order":    stop.Order,
+			},
+		})
+	}
+	return features, nil
+}
+
+func writeGeoJSON(w http.ResponseWriter, collection models.FeatureCollection) {
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+// SetRoutePath guarda el trazado real de la ruta por las calles
+//
+// Request:
+// PUT /admin/routes/{id}/path
+// {"polyline": "~xohAnlfuM..."}
+// o bien
+// {"coordinates": [[-77.0428, -12.0464], [-77.0401, -12.0502], ...]}
+//
+// polyline es una polilínea codificada (algoritmo de Google, 5 decimales),
+// como la que retornan las APIs de direcciones; coordinates va en orden
+// GeoJSON [lon, lat]. Se necesitan entre 2 y 10000 puntos.
+//
+// Response:
+// 200 OK
+// {"polyline": "~xohAnlfuM...", "points": 42, "length_m": 5230.4}
+func (h *Handler) SetRoutePath(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req RoutePathRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+
+	var points []geo.Point
+	switch {
+	case req.Polyline != "" && req.Coordinates != nil:
+		writeValidationErrors(w, []FieldError{{Field: "polyline", Code: "invalid", Message: "envía polyline o coordinates, no ambos"}})
+		return
+	case req.Polyline != "":
+		points, err = geo.DecodePolyline(req.Polyline)
+		if err != nil {
+			writeValidationErrors(w, []FieldError{{Field: "polyline", Code: "invalid", Message: "polyline no es una polilínea codificada válida"}})
+			return
+		}
+	case req.Coordinates != nil:
+		for i, c := range req.Coordinates {
+			if !models.ValidCoordinates(c[1], c[0]) {
+				writeValidationErrors(w, []FieldError{{
+					Field:   "coordinates",
+					Code:    "invalid",
+					Message: "coordinates[" + strconv.Itoa(i) + "] debe ser [lon, lat] válido",
+				}})
+				return
+			}
+			points = append(points, geo.Point{Lat: c[1], Lon: c[0]})
+		}
+	default:
+		writeValidationErrors(w, []FieldError{{Field: "polyline", Code: "required", Message: "polyline o coordinates es requerido"}})
+		return
+	}
+	if len(points) < 2 || len(points) > maxPathPoints {
+		writeValidationErrors(w, []FieldError{{Field: "coordinates", Code: "invalid", Message: "el trazado debe tener entre 2 y 10000 puntos"}})
+		return
+	}
+
+	// Se vuelve a codificar para guardar siempre el mismo formato y precisión
+	polyline := geo.EncodePolyline(points)
+	err = h.Routes.SetPath(r.Context(), routeID, polyline)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error guardando trazado")
+		return
+	}
+
+	var length float64
+	for i := 1; i < len(points); i++ {
+		length += geo.Distance(points[i-1].Lat, points[i-1].Lon, points[i].Lat, points[i].Lon)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RoutePathResponse{
+		Polyline:     polyline,
+		Points:       len(points),
+		LengthMeters: roundMeters(length),
+	})
+}
+
+// DeleteRoutePath borra el trazado guardado; la geometría vuelve a ser la
+// línea recta entre paradas
+//
+// Request:
+// DELETE /admin/routes/{id}/path
+//
+// Response:
+// 204 No Content
+func (h *Handler) DeleteRoutePath(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	err = h.Routes.SetPath(r.Context(), routeID, "")
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error borrando trazado")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+// featureCollection lee una respuesta GeoJSON dejando las coordenadas sin decodificar
+type featureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Type     string `json:"type"`
+		ID       string `json:"id"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	} `json:"features"`
+}
+
+func TestRouteGeoJSON(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	route, stops := s.route(admin, "Plaza", "Estadio")
+	path := "/routes/" + route.ID.String() + ".geojson"
+
+	rec := s.do(http.MethodGet, path, "", nil)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/geo+json" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	collection := decode[featureCollection](t, rec, http.StatusOK)
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 3 {
+		t.Fatalf("colección = %+v", collection)
+	}
+
+	// Sin trazado guardado: origen, paradas y destino en orden [lon, lat];
+	// la primera parada coincide con el origen y no se repite
+	line := collection.Features[0]
+	var coords [][2]float64
+	if err := json.Unmarshal(line.Geometry.Coordinates, &coords); err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]float64{{-77.0428, -12.0464}, {-77.0428, -12.0364}, {-77.0622, -11.9498}}
+	if line.Type != "Feature" || line.Geometry.Type != "LineString" || !slices.Equal(coords, want) {
+		t.Fatalf("LineString = %s %v, se esperaba %v", line.Geometry.Type, coords, want)
+	}
+	if line.ID != route.ID.String() || line.Properties["kind"] != "route" || line.Properties["path_source"] != "stops" {
+		t.Fatalf("propiedades de la ruta = %v", line.Properties)
+	}
+
+	for i, feature := range collection.Features[1:] {
+		var point [2]float64
+		if err := json.Unmarshal(feature.Geometry.Coordinates, &point); err != nil {
+			t.Fatal(err)
+		}
+		stop := stops[i]
+		if feature.Geometry.Type != "Point" || point != [2]float64{stop.Longitude, stop.Latitude} {
+			t.Fatalf("parada %d = %s %v, se esperaba [%v, %v]", i, feature.Geometry.Type, point, stop.Longitude, stop.Latitude)
+		}
+		if feature.ID != stop.ID.String() || feature.Properties["kind"] != "stop" || feature.Properties["order"] != float64(i+1) {
+			t.Fatalf("propiedades de la parada %d = %v", i, feature.Properties)
+		}
+	}
+
+	// Con trazado guardado el LineString es el trazado
+	admPath := "/admin/routes/" + route.ID.String() + "/path"
+	trace := [][2]float64{{-77.0428, -12.0464}, {-77.04, -12.05}, {-77.0622, -11.9498}}
+	saved := decode[handlers.RoutePathResponse](t, s.do(http.MethodPut, admPath, admin, map[string]any{"coordinates": trace}), http.StatusOK)
+	if saved.Points != 3 || saved.LengthMeters <= 0 {
+		t.Fatalf("trazado = %+v", saved)
+	}
+	line = decode[featureCollection](t, s.do(http.MethodGet, path, "", nil), http.StatusOK).Features[0]
+	if err := json.Unmarshal(line.Geometry.Coordinates, &coords); err != nil {
+		t.Fatal(err)
+	}
+	if line.Properties["path_source"] != "polyline" || !slices.Equal(coords, trace) {
+		t.Fatalf("LineString = %v (%v), se esperaba el trazado %v", coords, line.Properties["path_source"], trace)
+	}
+
+	for _, body := range []map[string]any{
+		{"polyline": "_p~iF~ps|U_ulLnnqC_mqNvxq"},
+		{"coordinates": [][2]float64{{-77.0428, -12.0464}, {-77.04, -120.05}}},
+		{"coordinates": [][2]float64{{-77.0428, -12.0464}}},
+		{"polyline": saved.Polyline, "coordinates": trace},
+	} {
+		assertError(t, s.do(http.MethodPut, admPath, admin, body), http.StatusBadRequest, handlers.ErrCodeValidation)
+	}
+
+	// Al borrar el trazado vuelve la línea entre paradas
+	if rec := s.do(http.MethodDelete, admPath, admin, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d: %s", rec.Code, rec.Body)
+	}
+	line = decode[featureCollection](t, s.do(http.MethodGet, path, "", nil), http.StatusOK).Features[0]
+	if line.Properties["path_source"] != "stops" {
+		t.Fatalf("path_source = %v después de borrar el trazado", line.Properties["path_source"])
+	}
+}
+
+func TestRoutesGeoJSON(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	active, _ := s.route(admin, "A", "B")
+	inactive, _ := s.route(admin, "C", "D")
+	s.do(http.MethodPost, "/admin/routes/"+inactive.ID.String()+"/deactivate", admin, nil)
+
+	collection := decode[featureCollection](t, s.do(http.MethodGet, "/routes.geojson", "", nil), http.StatusOK)
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 3 {
+		t.Fatalf("colección con %d features, se esperaban la ruta activa y sus 2 paradas", len(collection.Features))
+	}
+	for _, feature := range collection.Features {
+		if feature.Properties["route_id"] != active.ID.String() {
+			t.Fatalf("feature de la ruta %v, solo se esperaba %s", feature.Properties["route_id"], active.ID)
+		}
+	}
+
+	assertError(t, s.do(http.MethodGet, "/routes/"+inactive.ID.String()+".geojson", "", nil),
+		http.StatusNotFound, handlers.ErrCodeRouteNotFound)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/gtfs"
+)
+
+// ExportGTFS genera el feed GTFS estático de las rutas activas
+//
+// Request:
+// GET /gtfs.zip
+//
+// Response:
+// 200 OK (application/zip con agency.txt, routes.txt, stops.txt, trips.txt,
+// stop_times.txt, calendar.txt y calendar_dates.txt si hay excepciones)
+//
+// El feed cubre desde hoy los próximos 60 días.
+func (h *Handler) ExportGTFS(w http.ResponseWriter, r *http.Request) {
+	// Se arma completo antes de responder para poder devolver un error JSON
+	var buf bytes.Buffer
+	err := gtfs.Export(r.Context(), h.Repositories, &buf, gtfs.Options{
+		Agency: gtfs.AgencyFromEnv(),
+		Start:  time.Now(),
+	})
+	if err != nil {
+		writeStoreError(w, err, "Error generando feed GTFS")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="gtfs.zip"`)
+	w.Write(buf.Bytes())
+}
+
+// maxGTFSUpload es el tamaño máximo del zip en POST /admin/gtfs/import
+const maxGTFSUpload = 20 << 20
+
+// ImportGTFS crea o actualiza rutas y paradas desde un feed GTFS estático
+//
+// Request:
+// POST /admin/gtfs/import?dry_run=true&activate=false&base_price_cents=250&currency=PEN
+// Content-Type: application/zip (el zip como cuerpo) o multipart/form-data
+// (el zip en el campo "file"), máximo 20 MB
+//
+// Cada route_id del feed es una ruta y sus paradas salen del viaje con más
+// paradas. Con dry_run=true solo se calculan los cambios. activate,
+// base_price_cents y currency (por defecto false, 0 y PEN) se usan solo al
+// crear rutas; las rutas existentes conservan precio, moneda y estado.
+// Reimportar el mismo feed no produce cambios.
+//
+// Response:
+// 200 OK
+// {
+//   "dry_run": true,
+//   "routes": 1,
+//   "stops": 5,
+//   "changes": [
+//     {"action": "create", "entity": "route", "id": "uuid", "route_id": "uuid", "gtfs_id": "R1", "name": "Ruta 1"},
+//     {"action": "update", "entity": "stop", "id": "uuid", "route_id": "uuid", "gtfs_id": "S2", "name": "Plaza",
+//      "fields": [{"field": "coordinates", "from": "-12.046400,-77.042800", "to": "-12.046500,-77.042800"}]},
+//     {"action": "deactivate", "entity": "stop", ...},
+//     {"action": "reorder", "entity": "route", ...}
+//   ]
+// }
+// 400 Bad Request (invalid_gtfs si el zip no es un feed válido)
+func (h *Handler) ImportGTFS(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var errs []FieldError
+	var opts gtfs.ImportOptions
+	for _, flag := range []struct {
+		field string
+		value *bool
+	}{{"dry_run", &opts.DryRun}, {"activate", &opts.Activate}} {
+		if v := query.Get(flag.field); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, FieldError{Field: flag.field, Code: "invalid", Message: flag.field + " debe ser true o false"})
+			}
+			*flag.value = b
+		}
+	}
+	if v := query.Get("base_price_cents"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs = append(errs, FieldError{Field: "base_price_cents", Code: "invalid", Message: "base_price_cents debe ser un entero no negativo"})
+		}
+		opts.BasePriceCents = n
+	}
+	if v := query.Get("currency"); v != "" {
+		opts.Currency = strings.ToUpper(strings.TrimSpace(v))
+		if !currencyPattern.MatchString(opts.Currency) {
+			errs = append(errs, FieldError{Field: "currency", Code: "invalid", Message: "currency inválida (código ISO 4217, ej: PEN)"})
+		}
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	body, err := readGTFSUpload(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidGTFS, err.Error())
+		return
+	}
+
+	routes, err := gtfs.ReadFeed(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidGTFS, err.Error())
+		return
+	}
+	result, err := gtfs.Import(r.Context(), h.Repositories, routes, opts)
+	if err != nil {
+		writeStoreError(w, err, "Error importando feed GTFS")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// readGTFSUpload lee el zip del cuerpo o del campo "file" de un formulario
+func readGTFSUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxGTFSUpload)
+
+	var src io.Reader = r.Body
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxGTFSUpload); err != nil {
+			return nil, errors.New("formulario inválido o mayor a 20 MB")
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.New("falta el archivo en el campo file")
+		}
+		defer file.Close()
+		src = file
+	}
+
+	body, err := io.ReadAll(src)
+	if err != nil {
+		return nil, errors.New("no se pudo leer el zip (máximo 20 MB)")
+	}
+	if len(body) == 0 {
+		return nil, errors.New("el cuerpo está vacío; envía el zip del feed")
+	}
+	return body, nil
+}
@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luisdev-dark/realgov3.git/gtfs"
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+// upload envía el zip como cuerpo de POST /admin/gtfs/import
+func (s *testServer) upload(token, query string, body []byte) *httptest.ResponseRecorder {
+	s.t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/admin/gtfs/import"+query, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/zip")
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func gtfsZip(t *testing.T, stopTimes string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"routes.txt":     "route_id,route_short_name\nR1,Ruta 1\n",
+		"stops.txt":      "stop_id,stop_name,stop_lat,stop_lon\nA,Centro,-12.0464,-77.0428\nB,Norte,-12.0364,-77.0428\n",
+		"trips.txt":      "route_id,service_id,trip_id\nR1,S,T1\n",
+		"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" + stopTimes,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportGTFS(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	valid := gtfsZip(t, "T1,06:00:00,06:00:00,A,1\nT1,06:05:00,06:05:00,B,2\n")
+
+	dryRun := decode[gtfs.ImportResult](t, s.upload(admin, "?dry_run=true", valid), http.StatusOK)
+	if !dryRun.DryRun || dryRun.Routes != 1 || dryRun.Stops != 2 || len(dryRun.Changes) != 3 {
+		t.Fatalf("dry-run = %+v", dryRun)
+	}
+	if routes := decode[[]models.Route](t, s.do(http.MethodGet, "/routes", "", nil), http.StatusOK); len(routes) != 0 {
+		t.Fatalf("el dry-run creó %d rutas", len(routes))
+	}
+
+	applied := decode[gtfs.ImportResult](t, s.upload(admin, "?activate=true&base_price_cents=300", valid), http.StatusOK)
+	if applied.DryRun || len(applied.Changes) != 3 {
+		t.Fatalf("importación = %+v", applied)
+	}
+	route := decode[models.RouteDetail](t, s.do(http.MethodGet, "/routes/"+applied.Changes[0].ID.String(), "", nil), http.StatusOK)
+	if route.BasePrice != 3 || len(route.Stops) != 2 {
+		t.Fatalf("ruta importada = %+v", route)
+	}
+	if again := decode[gtfs.ImportResult](t, s.upload(admin, "", valid), http.StatusOK); len(again.Changes) != 0 {
+		t.Fatalf("reimportar el mismo feed produjo cambios: %+v", again.Changes)
+	}
+
+	for name, body := range map[string][]byte{
+		"stop_times sin stop_sequence": gtfsZip(t, "T1,06:00:00,06:00:00,A\n"),
+		"stop_sequence no numérico":    gtfsZip(t, "T1,06:00:00,06:00:00,A,uno\n"),
+		"no es un zip":                 []byte("hola"),
+		"cuerpo vacío":                 nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assertError(t, s.upload(admin, "", body), http.StatusBadRequest, handlers.ErrCodeInvalidGTFS)
+		})
+	}
+
+	assertError(t, s.upload(admin, "?dry_run=quizas", valid), http.StatusBadRequest, handlers.ErrCodeValidation)
+	assertError(t, s.upload(passenger, "", valid), http.StatusForbidden, handlers.ErrCodeForbidden)
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/luisdev-dark/realgov3.git/auth"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// Handler agrupa las dependencias de los endpoints HTTP.
+// Los repositorios se inyectan para poder usar Postgres en producción
+// y la implementación en memoria en pruebas.
+type Handler struct {
+	*repository.Repositories
+
+	// SMS envía los códigos de acceso
+	SMS auth.SMSSender
+	// Boarding firma y verifica los pases de abordar
+	Boarding *auth.BoardingSigner
+}
+
+// New crea un Handler; si sms es nil usa auth.LogSender. boarding es
+// obligatorio: una clave generada aquí no valdría entre instancias ni tras
+// reiniciar (ver auth.BoardingSignerFromEnv).
+func New(repos *repository.Repositories, sms auth.SMSSender, boarding *auth.BoardingSigner) *Handler {
+	if sms == nil {
+		sms = auth.LogSender{}
+	}
+	if boarding == nil {
+		panic("handlers.New: falta el firmador de pases de abordar")
+	}
+	return &Handler{Repositories: repos, SMS: sms, Boarding: boarding}
+}
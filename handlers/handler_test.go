@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/auth"
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+	"github.com/luisdev-dark/realgov3.git/routes"
+)
+
+// testServer es el router completo sobre repositorios en memoria
+type testServer struct {
+	t      *testing.T
+	repos  *repository.Repositories
+	router http.Handler
+	phones int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	repos := repository.NewMemory()
+	signer := auth.NewBoardingSigner([]byte("clave-de-pruebas-de-32-caracteres"))
+	h := handlers.New(repos, auth.LogSender{}, signer)
+	return &testServer{t: t, repos: repos, router: routes.SetupRouter(h)}
+}
+
+// login crea un usuario con el rol dado y retorna el usuario y su token
+func (s *testServer) login(role string) (*models.User, string) {
+	s.t.Helper()
+	ctx := context.Background()
+
+	s.phones++
+	user := &models.User{Name: fmt.Sprintf("%s %d", role, s.phones), Phone: fmt.Sprintf("+5190000%04d", s.phones), Role: role}
+	if err := s.repos.Users.Create(ctx, user); err != nil {
+		s.t.Fatalf("creando usuario: %v", err)
+	}
+	token, err := auth.NewToken()
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	session := &models.Session{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := s.repos.Sessions.CreateSession(ctx, session); err != nil {
+		s.t.Fatalf("creando sesión: %v", err)
+	}
+	return user, token
+}
+
+// do envía un request al router; body se codifica como JSON si no es nil
+func (s *testServer) do(method, path, token string, body any, headers ...string) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// route crea una ruta activa con paradas usando la API de administración
+func (s *testServer) route(adminToken string, stops ...string) (models.Route, []models.RouteStop) {
+	s.t.Helper()
+
+	rec := s.do(http.MethodPost, "/admin/routes", adminToken, map[string]any{
+		"name":             "Ruta Centro - Norte",
+		"origin_name":      "Centro",
+		"origin_lat":       -12.0464,
+		"origin_lon":       -77.0428,
+		"destination_name": "Norte",
+		"destination_lat":  -11.9498,
+		"destination_lon":  -77.0622,
+		"base_price_cents": 500,
+	})
+	route := decode[models.Route](s.t, rec, http.StatusCreated)
+
+	created := make([]models.RouteStop, 0, len(stops))
+	for i, name := range stops {
+		rec := s.do(http.MethodPost, "/admin/routes/"+route.ID.String()+"/stops", adminToken, map[string]any{
+			"name":      name,
+			"latitude":  -12.0464 + float64(i)*0.01,
+			"longitude": -77.0428,
+		})
+		created = append(created, decode[models.RouteStop](s.t, rec, http.StatusCreated))
+	}
+	return route, created
+}
+
+// departure programa una salida de la ruta dentro de in
+func (s *testServer) departure(routeID uuid.UUID, capacity int, in time.Duration) models.Departure {
+	s.t.Helper()
+
+	departure := models.Departure{
+		RouteID:   routeID,
+		DepartsAt: time.Now().Add(in).Truncate(time.Second),
+		Capacity:  capacity,
+		Status:    models.DepartureStatusScheduled,
+	}
+	if err := s.repos.Departures.Create(context.Background(), &departure); err != nil {
+		s.t.Fatalf("creando salida: %v", err)
+	}
+	return departure
+}
+
+// bookTrip reserva un viaje en la salida y falla si no responde 200
+func (s *testServer) bookTrip(token string, departure models.Departure) models.Trip {
+	s.t.Helper()
+
+	rec := s.do(http.MethodPost, "/trips", token, map[string]any{
+		"route_id":       departure.RouteID,
+		"departure_id":   departure.ID,
+		"payment_method": "cash",
+	})
+	return decode[models.Trip](s.t, rec, http.StatusOK)
+}
+
+// decode verifica el status de la respuesta y decodifica su cuerpo
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder, status int) T {
+	t.Helper()
+
+	var v T
+	if rec.Code != status {
+		t.Fatalf("status = %d, se esperaba %d: %s", rec.Code, status, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decodificando respuesta: %v: %s", err, rec.Body)
+	}
+	return v
+}
+
+// assertError verifica el status y el código estable de una respuesta de error
+func assertError(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	resp := decode[handlers.ErrorResponse](t, rec, status)
+	if resp.Error.Code != code {
+		t.Fatalf("error.code = %q, se esperaba %q", resp.Error.Code, code)
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/auth"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+const (
+	// IdempotencyKeyHeader es el header con el que el cliente marca un request reintentable
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotencyTTL es cuánto tiempo se guarda la respuesta de una llave
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLease es cuánto se reserva una llave sin respuesta; pasado
+	// ese tiempo se asume que el request original se cayó y un reintento
+	// la toma
+	idempotencyLease = time.Minute
+
+	maxIdempotencyKeyLen = 255
+	maxIdempotentBody    = 1 << 20
+)
+
+// Idempotent permite reintentar un request con el header Idempotency-Key sin
+// repetir su efecto. La primera vez se ejecuta el handler y se guarda su
+// respuesta; un reintento con la misma llave y el mismo cuerpo recibe esa
+// respuesta guardada (con el header Idempotent-Replayed: true).
+//
+// Errores:
+//   - 422 idempotency_key_reused si la llave ya se usó con otro cuerpo
+//   - 409 idempotency_key_in_progress si el request original aún no termina
+//     (por hasta idempotencyLease; después un reintento toma la llave)
+//   - 413 body_too_large si el cuerpo supera 1 MB (no se guarda truncado)
+//
+// Las respuestas 5xx no se guardan para que el cliente pueda reintentar.
+// Sin header el request pasa directo. Debe usarse después de RequireAuth.
+func (h *Handler) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			writeValidationErrors(w, []FieldError{{
+				Field:   IdempotencyKeyHeader,
+				Code:    "invalid",
+				Message: "Idempotency-Key no puede superar 255 caracteres",
+			}})
+			return
+		}
+
+		user, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Autenticación requerida")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, ErrCodeBodyTooLarge, "El cuerpo no puede superar 1 MB")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "No se pudo leer el cuerpo")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		record := &models.IdempotencyKey{
+			UserID:      user.ID,
+			Key:         key,
+			RequestHash: requestHash(r, body),
+			CreatedAt:   now,
+		}
+		existing, err := h.Idempotency.Begin(r.Context(), record, now.Add(-idempotencyTTL), now.Add(-idempotencyLease))
+		if err != nil {
+			writeStoreError(w, err, "Error registrando Idempotency-Key")
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != record.RequestHash:
+				writeError(w, http.StatusUnprocessableEntity, ErrCodeIdempotencyKeyReused,
+					"La Idempotency-Key ya se usó con otro request")
+			case !existing.Completed():
+				writeError(w, http.StatusConflict, ErrCodeIdempotencyKeyInFlight,
+					"El request original con esta Idempotency-Key aún está en proceso")
+			default:
+				if existing.ContentType != "" {
+					w.Header().Set("Content-Type", existing.ContentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(existing.StatusCode)
+				w.Write(existing.ResponseBody)
+			}
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			// Context.Background: la respuesta se guarda aunque el cliente ya se haya ido
+			ctx := context.Background()
+			if p := recover(); p != nil || rec.status >= http.StatusInternalServerError {
+				if err := h.Idempotency.Release(ctx, user.ID, key); err != nil {
+					log.Printf("Error liberando Idempotency-Key: %v", err)
+				}
+				if p != nil {
+					panic(p)
+				}
+				return
+			}
+			completedAt := time.Now()
+			record.StatusCode = rec.status
+			record.ContentType = rec.Header().Get("Content-Type")
+			record.ResponseBody = rec.body.Bytes()
+			record.CompletedAt = &completedAt
+			if err := h.Idempotency.Complete(ctx, record); err != nil {
+				log.Printf("Error guardando respuesta de Idempotency-Key: %v", err)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// requestHash identifica el request por método, ruta y cuerpo
+func requestHash(r *http.Request, body []byte) string {
+	sum := sha256.New()
+	io.WriteString(sum, r.Method)
+	sum.Write([]byte{0})
+	io.WriteString(sum, r.URL.Path)
+	sum.Write([]byte{0})
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// responseRecorder escribe la respuesta al cliente y guarda una copia
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,120 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestIdempotentCreateTrip(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, token := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+	departure := s.departure(route.ID, 10, 24*time.Hour)
+	body := map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "cash"}
+
+	first := s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "k1")
+	trip := decode[models.Trip](t, first, http.StatusOK)
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("el primer request no debe marcarse como repetido")
+	}
+
+	retry := s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "k1")
+	replayed := decode[models.Trip](t, retry, http.StatusOK)
+	if replayed.ID != trip.ID || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("reintento = %s (replayed %q), se esperaba %s", replayed.ID, retry.Header().Get("Idempotent-Replayed"), trip.ID)
+	}
+	got, err := s.repos.Departures.Get(context.Background(), departure.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SeatsBooked != 1 {
+		t.Fatalf("seats_booked = %d, el reintento no debe reservar otro asiento", got.SeatsBooked)
+	}
+
+	// Misma llave con otro cuerpo
+	body["payment_method"] = "yape"
+	assertError(t, s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "k1"),
+		http.StatusUnprocessableEntity, handlers.ErrCodeIdempotencyKeyReused)
+
+	// La llave es por usuario: otro pasajero puede usar la misma
+	_, other := s.login(models.RolePassenger)
+	if id := decode[models.Trip](t, s.do(http.MethodPost, "/trips", other, body, handlers.IdempotencyKeyHeader, "k1"), http.StatusOK).ID; id == trip.ID {
+		t.Fatal("otro usuario recibió el viaje guardado con la misma llave")
+	}
+}
+
+func TestIdempotentReplaysClientErrors(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.login(models.RolePassenger)
+	body := map[string]any{"payment_method": "cash"}
+
+	assertError(t, s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "k2"),
+		http.StatusBadRequest, handlers.ErrCodeValidation)
+	retry := s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "k2")
+	assertError(t, retry, http.StatusBadRequest, handlers.ErrCodeValidation)
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("el 400 guardado no se repitió")
+	}
+}
+
+func TestIdempotentRejectsLargeBody(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, token := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+	departure := s.departure(route.ID, 10, 24*time.Hour)
+	body := map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "cash"}
+
+	large := map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "cash",
+		"padding": strings.Repeat("x", 1<<20)}
+	assertError(t, s.do(http.MethodPost, "/trips", token, large, handlers.IdempotencyKeyHeader, "k3"),
+		http.StatusRequestEntityTooLarge, handlers.ErrCodeBodyTooLarge)
+
+	// El cuerpo rechazado no deja la llave registrada
+	rec := s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "k3")
+	decode[models.Trip](t, rec, http.StatusOK)
+	if rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("la llave del request rechazado quedó guardada")
+	}
+
+	assertError(t, s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, strings.Repeat("k", 256)),
+		http.StatusBadRequest, handlers.ErrCodeValidation)
+}
+
+func TestIdempotentTakesOverStaleKey(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	passenger, token := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+	departure := s.departure(route.ID, 10, 24*time.Hour)
+	body := map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "cash"}
+
+	// Llaves que quedaron sin respuesta, como si el servidor se hubiera caído
+	begin := func(key string, age time.Duration) {
+		t.Helper()
+		now := time.Now()
+		record := &models.IdempotencyKey{UserID: passenger.ID, Key: key, RequestHash: "perdido", CreatedAt: now.Add(-age)}
+		if _, err := s.repos.Idempotency.Begin(context.Background(), record, now.Add(-24*time.Hour), now.Add(-time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	begin("vieja", 2*time.Minute)
+	begin("reciente", time.Second)
+
+	rec := s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "vieja")
+	decode[models.Trip](t, rec, http.StatusOK)
+	if rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("la llave abandonada no se volvió a procesar")
+	}
+
+	// Dentro del plazo la llave sigue reservada por el request original
+	assertError(t, s.do(http.MethodPost, "/trips", token, body, handlers.IdempotencyKeyHeader, "reciente"),
+		http.StatusUnprocessableEntity, handlers.ErrCodeIdempotencyKeyReused)
+}
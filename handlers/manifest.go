@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/auth"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// GetDepartureManifest retorna la lista de pasajeros de una salida para el
+// conductor asignado
+//
+// Request:
+// GET /driver/departures/{id}/manifest
+// GET /driver/departures/{id}/manifest?format=html
+// Authorization: Bearer <token>
+//
+// Los viajes que ocupan asiento se agrupan por parada de recojo en orden de
+// recorrido; los que no tienen parada van al final con "stop": null. Solo
+// los pagos en efectivo suman a cash_to_collect. Con format=html se retorna
+// una página imprimible, sin scripts ni recursos externos.
+//
+// Response:
+// 200 OK
+// {
+//   "departure_id": "uuid",
+//   "departs_at": "2025-01-15T07:30:00-05:00",
+//   "status": "scheduled",
+//   "route": {"id": "uuid", "name": "Ruta Centro - Norte", "origin": "Centro", "destination": "Norte", "base_price": 5.00},
+//   "vehicle": "ABC123",
+//   "capacity": 15,
+//   "passengers": 2,
+//   "cash_to_collect": 5.00,
+//   "currency": "PEN",
+//   "stops": [
+//     {
+//       "stop": {"id": "uuid", "name": "Plaza de Armas"},
+//       "order": 1,
+//       "cash_to_collect": 5.00,
+//       "passengers": [
+//         {"trip_id": "uuid", "name": "Ana", "phone": "+51987654321", "status": "confirmed",
+//          "dropoff": {"id": "uuid", "name": "Terminal Norte"}, "payment_method": "cash",
+//          "price": 5.00, "cash_to_collect": 5.00}
+//       ]
+//     }
+//   ]
+// }
+// 404 Not Found si la salida no existe o no está asignada al conductor
+func (h *Handler) GetDepartureManifest(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+
+	departureID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de salida inválido")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "html" {
+		writeValidationErrors(w, []FieldError{{Field: "format", Code: "invalid", Message: "format debe ser json o html"}})
+		return
+	}
+
+	// Otros conductores no pueden saber si la salida existe
+	departure, err := h.Departures.Get(r.Context(), departureID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && (departure.DriverID == nil || *departure.DriverID != user.ID)) {
+		writeError(w, http.StatusNotFound, ErrCodeDepartureNotFound, "Salida no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando salida")
+		return
+	}
+
+	route, err := h.Routes.Get(r.Context(), departure.RouteID)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+	stops, err := h.Stops.ListByRoute(r.Context(), route.ID, false)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando paradas")
+		return
+	}
+	// Los viajes traen nombre y teléfono del pasajero en la misma consulta
+	trips, err := h.Trips.ListByDeparture(r.Context(), departure.ID)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando viajes")
+		return
+	}
+
+	vehicle := departure.VehicleLabel
+	if departure.VehicleID != nil {
+		assigned, err := h.Vehicles.Get(r.Context(), *departure.VehicleID)
+		if err != nil {
+			writeStoreError(w, err, "Error consultando vehículo")
+			return
+		}
+		vehicle = assigned.Plate
+	}
+
+	manifest := models.NewManifest(departure, route, vehicle, stops, trips)
+
+	if format == "html" {
+		var page bytes.Buffer
+		if err := manifestTemplate.Execute(&page, manifest); err != nil {
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error generando manifiesto")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page.WriteTo(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(manifest)
+}
+
+// manifestTemplate es el manifiesto imprimible. Usa solo HTML y CSS en
+// línea para que se pueda abrir en cualquier navegador e imprimir en blanco
+// y negro.
+var manifestTemplate = template.Must(template.New("manifest").Funcs(template.FuncMap{
+	"money": func(amount float64) string {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	},
+	"departs": func(t time.Time) string {
+		return t.In(models.LocalZone).Format("02/01/2006 15:04")
+	},
+	"payment": func(method string) string {
+		switch method {
+		case models.PaymentMethodCash:
+			return "Efectivo"
+		case models.PaymentMethodYape:
+			return "Yape"
+		case models.PaymentMethodPling:
+			return "Plin"
+		}
+		return method
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Manifiesto {{.Route.Name}} {{departs .DepartsAt}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 16px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 16px; }
+th, td { border: 1px solid #000; padding: 4px 6px; text-align: left; }
+td.amount, th.amount { text-align: right; }
+h2 { font-size: 16px; margin: 16px 0 4px; }
+</style>
+</head>
+<body>
+<h1>{{.Route.Name}}</h1>
+<p>
+Salida: {{departs .DepartsAt}}{{if eq .Status "cancelled"}} (CANCELADA){{end}}<br>
+Vehículo: {{if .Vehicle}}{{.Vehicle}}{{else}}sin asignar{{end}}<br>
+Pasajeros: {{.Passengers}} de {{.Capacity}}<br>
+Total a cobrar en efectivo: {{.Currency}} {{money .CashToCollect}}
+</p>
+{{range .Stops}}
+<h2>{{if .Stop}}{{.Order}}. {{.Stop.Name}}{{else}}Sin parada de recojo{{end}}</h2>
+<table>
+<tr><th>Pasajero</th><th>Teléfono</th><th>Baja en</th><th>Pago</th><th class="amount">Cobrar</th><th>Subió</th></tr>
+{{range .Passengers}}<tr><td>{{.Name}}</td><td>{{.Phone}}</td><td>{{with .Dropoff}}{{.Name}}{{else}}-{{end}}</td><td>{{payment .PaymentMethod}}</td><td class="amount">{{if .CashToCollect}}{{money .CashToCollect}}{{else}}-{{end}}</td><td></td></tr>
+{{end}}<tr><td colspan="4">Total en la parada</td><td class="amount">{{money .CashToCollect}}</td><td></td></tr>
+</table>
+{{else}}
+<p>No hay pasajeros reservados.</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestDepartureManifest(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	driver, driverToken := s.login(models.RoleDriver)
+	_, otherDriver := s.login(models.RoleDriver)
+	ana, anaToken := s.login(models.RolePassenger)
+	luis, luisToken := s.login(models.RolePassenger)
+
+	route, stops := s.route(admin, "A", "B", "C")
+	departure := models.Departure{
+		RouteID:      route.ID,
+		DepartsAt:    time.Now().Add(time.Hour),
+		Capacity:     10,
+		VehicleLabel: "Combi ABC-123",
+		DriverID:     &driver.ID,
+		Status:       models.DepartureStatusScheduled,
+	}
+	if err := s.repos.Departures.Create(context.Background(), &departure); err != nil {
+		t.Fatal(err)
+	}
+	book := func(token string, pickup, dropoff int, payment string) models.Trip {
+		rec := s.do(http.MethodPost, "/trips", token, map[string]any{
+			"route_id": route.ID, "departure_id": departure.ID, "payment_method": payment,
+			"pickup_stop_id": stops[pickup].ID, "dropoff_stop_id": stops[dropoff].ID,
+		})
+		return decode[models.Trip](t, rec, http.StatusOK)
+	}
+	book(luisToken, 1, 2, models.PaymentMethodYape)
+	book(anaToken, 0, 2, models.PaymentMethodCash)
+	cancelled := book(anaToken, 0, 1, models.PaymentMethodCash)
+	decode[models.Trip](t, s.do(http.MethodPost, "/trips/"+cancelled.ID.String()+"/cancel", anaToken, nil), http.StatusOK)
+
+	path := "/driver/departures/" + departure.ID.String() + "/manifest"
+	manifest := decode[models.Manifest](t, s.do(http.MethodGet, path, driverToken, nil), http.StatusOK)
+	if manifest.Passengers != 2 || manifest.CashToCollect != 5 || manifest.Vehicle != "Combi ABC-123" {
+		t.Fatalf("manifiesto = %+v", manifest)
+	}
+	if len(manifest.Stops) != 2 || manifest.Stops[0].Stop.ID != stops[0].ID || manifest.Stops[1].Stop.ID != stops[1].ID {
+		t.Fatalf("paradas = %+v", manifest.Stops)
+	}
+	first, second := manifest.Stops[0].Passengers[0], manifest.Stops[1].Passengers[0]
+	if first.Name != ana.Name || first.Phone != ana.Phone || first.CashToCollect != 5 {
+		t.Fatalf("pasajero en %s = %+v", stops[0].Name, first)
+	}
+	if second.Name != luis.Name || second.Phone != luis.Phone || second.CashToCollect != 0 {
+		t.Fatalf("pasajero en %s = %+v", stops[1].Name, second)
+	}
+
+	rec := s.do(http.MethodGet, path+"?format=html", driverToken, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), strings.TrimPrefix(ana.Phone, "+")) {
+		t.Fatalf("manifiesto HTML = %d: %s", rec.Code, rec.Body)
+	}
+
+	// Otros conductores no ven la salida
+	assertError(t, s.do(http.MethodGet, path, otherDriver, nil), http.StatusNotFound, handlers.ErrCodeDepartureNotFound)
+}
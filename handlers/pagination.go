@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+const (
+	// NextCursorHeader lleva el cursor de la página siguiente; no se envía
+	// en la última página
+	NextCursorHeader = "X-Next-Cursor"
+
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePage lee ?limit= y ?cursor=. Agrega a errs los parámetros inválidos.
+func parsePage(r *http.Request, errs []FieldError) (limit int, cursor *models.Cursor, _ []FieldError) {
+	limit = defaultPageLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageLimit {
+			errs = append(errs, FieldError{Field: "limit", Code: "invalid", Message: "limit debe estar entre 1 y 100"})
+		} else {
+			limit = n
+		}
+	}
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		c, err := models.DecodeCursor(v)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "cursor", Code: "invalid", Message: "cursor inválido"})
+		} else {
+			cursor = &c
+		}
+	}
+	return limit, cursor, errs
+}
+
+// setNextCursor publica el cursor de la página siguiente si hay más resultados
+func setNextCursor(w http.ResponseWriter, next *models.Cursor) {
+	if next != nil {
+		w.Header().Set(NextCursorHeader, next.Encode())
+	}
+}
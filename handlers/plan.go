@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/planner"
+)
+
+const (
+	defaultPlanWalk     = 800.0
+	maxPlanWalk         = 2000.0
+	planTransferWalk    = 300.0
+	planResults         = 3
+	planCandidateStops  = 100
+	defaultPlanTransfer = 1
+)
+
+// PlanTrip sugiere cómo ir de un punto a otro con las rutas existentes
+//
+// Request:
+// GET /plan?from_lat=-12.0464&from_lon=-77.0428&to_lat=-12.1211&to_lon=-77.0297&max_walk_m=800&max_transfers=1
+//
+// from_lat, from_lon, to_lat y to_lon son obligatorios. max_walk_m es la
+// caminata máxima hasta la primera parada y desde la última (por defecto
+// 800, máximo 2000). max_transfers es 0 (solo rutas directas) o 1 (por
+// defecto); el transbordo se permite entre paradas a 300 m o menos.
+//
+// Response:
+// 200 OK (hasta 3 opciones, la mejor primero; [] si ninguna ruta sirve)
+// [
+//   {
+//     "walk_to_pickup_m": 120.4,
+//     "legs": [
+//       {
+//         "route": {"id": "uuid", "name": "Ruta Centro - Norte", ...},
+//         "pickup": {"id": "uuid", "name": "Plaza de Armas"},
+//         "dropoff": {"id": "uuid", "name": "Estación Central"},
+//         "stops": 2,
+//         "ride_m": 3400.2,
+//         "fare": {"price_cents": 350, "currency": "PEN", ...}
+//       }
+//     ],
+//     "transfer_walk_m": 0,
+//     "walk_from_dropoff_m": 80.1,
+//     "total_walk_m": 200.5,
+//     "price_cents": 350,
+//     "price": 3.5,
+//     "currency": "PEN",
+//     "minutes": 12.7
+//   }
+// ]
+func (h *Handler) PlanTrip(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var errs []FieldError
+	from := planner.Point{
+		Lat: parseCoordinate(query.Get("from_lat"), "from_lat", 90, &errs),
+		Lon: parseCoordinate(query.Get("from_lon"), "from_lon", 180, &errs),
+	}
+	to := planner.Point{
+		Lat: parseCoordinate(query.Get("to_lat"), "to_lat", 90, &errs),
+		Lon: parseCoordinate(query.Get("to_lon"), "to_lon", 180, &errs),
+	}
+
+	opts := planner.Options{
+		MaxWalkMeters:      defaultPlanWalk,
+		TransferWalkMeters: planTransferWalk,
+		MaxTransfers:       defaultPlanTransfer,
+		MaxResults:         planResults,
+	}
+	if v := query.Get("max_walk_m"); v != "" {
+		n, err := parseFinite(v)
+		if err != nil || n <= 0 || n > maxPlanWalk {
+			errs = append(errs, FieldError{Field: "max_walk_m", Code: "invalid", Message: "max_walk_m debe estar entre 1 y 2000"})
+		} else {
+			opts.MaxWalkMeters = n
+		}
+	}
+	switch v := query.Get("max_transfers"); v {
+	case "":
+	case "0", "1":
+		opts.MaxTransfers, _ = strconv.Atoi(v)
+	default:
+		errs = append(errs, FieldError{Field: "max_transfers", Code: "invalid", Message: "max_transfers debe ser 0 o 1"})
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	routes, err := h.planRoutes(r.Context(), from, to, opts.MaxWalkMeters)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando rutas")
+		return
+	}
+
+	plans, err := planner.Find(from, to, routes, func(route *models.Route, path []models.RouteStop) (*models.FareQuote, error) {
+		return h.quoteFare(r.Context(), route, path)
+	}, opts)
+	if err != nil {
+		writeStoreError(w, err, "Error calculando tarifas")
+		return
+	}
+	for i := range plans {
+		roundPlan(&plans[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plans)
+}
+
+// planRoutes carga las rutas con alguna parada cerca del origen o del
+// destino. Con un transbordo como máximo, las demás rutas no pueden formar
+// parte de un viaje.
+func (h *Handler) planRoutes(ctx context.Context, from, to planner.Point, maxWalk float64) ([]planner.Route, error) {
+	var ids []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, point := range []planner.Point{from, to} {
+		stops, err := h.Stops.Nearby(ctx, point.Lat, point.Lon, maxWalk, planCandidateStops)
+		if err != nil {
+			return nil, err
+		}
+		for _, stop := range stops {
+			if !seen[stop.Route.ID] {
+				seen[stop.Route.ID] = true
+				ids = append(ids, stop.Route.ID)
+			}
+		}
+	}
+
+	routes := make([]planner.Route, 0, len(ids))
+	for _, id := range ids {
+		route, err := h.Routes.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// Todas las paradas, para cotizar el tramo igual que al reservar
+		stops, err := h.Stops.ListByRoute(ctx, id, false)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, planner.Route{Route: *route, Stops: stops})
+	}
+	return routes, nil
+}
+
+// roundPlan redondea distancias a decímetros y minutos a décimas
+func roundPlan(plan *planner.Plan) {
+	plan.WalkToPickupMeters = roundMeters(plan.WalkToPickupMeters)
+	plan.TransferWalkMeters = roundMeters(plan.TransferWalkMeters)
+	plan.WalkFromDropoffMeters = roundMeters(plan.WalkFromDropoffMeters)
+	plan.TotalWalkMeters = roundMeters(plan.TotalWalkMeters)
+	plan.Minutes = math.Round(plan.Minutes*10) / 10
+	for i := range plan.Legs {
+		plan.Legs[i].RideMeters = roundMeters(plan.Legs[i].RideMeters)
+	}
+}
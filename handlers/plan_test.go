@@ -0,0 +1,46 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/planner"
+)
+
+func TestPlanTripDirect(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, stops := s.route(admin, "A", "B", "C")
+
+	plans := decode[[]planner.Plan](t, s.do(http.MethodGet,
+		"/plan?from_lat=-12.0464&from_lon=-77.0428&to_lat=-12.0264&to_lon=-77.0428&max_transfers=0", "", nil), http.StatusOK)
+	if len(plans) == 0 {
+		t.Fatal("no se encontró ningún plan")
+	}
+	best := plans[0]
+	if len(best.Legs) != 1 || best.Legs[0].Pickup.ID != stops[0].ID || best.Legs[0].Dropoff.ID != stops[2].ID {
+		t.Fatalf("mejor plan = %+v", best)
+	}
+	if best.PriceCents != 500 || best.TotalWalkMeters != 0 {
+		t.Fatalf("precio = %d, caminata = %v", best.PriceCents, best.TotalWalkMeters)
+	}
+}
+
+func TestPlanTripValidation(t *testing.T) {
+	s := newTestServer(t)
+	const to = "&to_lat=-12.0&to_lon=-77.0"
+
+	for _, query := range []string{
+		"from_lon=-77.0" + to,
+		"from_lat=-91&from_lon=-77.0" + to,
+		"from_lat=NaN&from_lon=-77.0" + to,
+		"from_lat=-12.0&from_lon=Inf" + to,
+		"from_lat=-12.0&from_lon=-77.0&to_lat=-Inf&to_lon=-77.0",
+		"from_lat=-12.0&from_lon=-77.0" + to + "&max_walk_m=NaN",
+		"from_lat=-12.0&from_lon=-77.0" + to + "&max_transfers=2",
+	} {
+		assertError(t, s.do(http.MethodGet, "/plan?"+query, "", nil), http.StatusBadRequest, handlers.ErrCodeValidation)
+	}
+}
@@ -1,23 +1,30 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"github.com/luisdev-dark/realgov3.git/db"
 	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
 )
 
-// GetRoutes retorna todas las rutas activas
+// GetRoutes retorna las rutas activas, paginadas
 //
 // Request:
-// GET /routes
+// GET /routes?q=centro&currency=PEN&min_price_cents=300&max_price_cents=800&sort=price&limit=20&cursor=...
+//
+// Todos los parámetros son opcionales. q busca en name, origin_name y
+// destination_name. sort es created_at, name o price, con "-" para orden
+// descendente (por defecto -created_at). Si hay más resultados la respuesta
+// trae el header X-Next-Cursor; se pasa como ?cursor= con los mismos filtros.
 //
 // Response:
-// 200 OK
+// 200 OK ([] si no hay rutas)
 // [
 //   {
 //     "id": "uuid",
@@ -29,47 +36,70 @@ import (
 //     "is_active": true
 //   }
 // ]
-func GetRoutes(w http.ResponseWriter, r *http.Request) {
-	pool := db.GetDB()
+func (h *Handler) GetRoutes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var errs []FieldError
+	filter := repository.RouteFilter{
+		Search:   strings.TrimSpace(query.Get("q")),
+		Currency: strings.ToUpper(strings.TrimSpace(query.Get("currency"))),
+		Sort:     models.RouteSortCreatedAt,
+		Desc:     true,
+	}
+	if filter.Currency != "" && !currencyPattern.MatchString(filter.Currency) {
+		errs = append(errs, FieldError{Field: "currency", Code: "invalid", Message: "currency debe ser un código ISO de 3 letras"})
+	}
+	for _, param := range []struct {
+		field string
+		dst   **int
+	}{
+		{"min_price_cents", &filter.MinPriceCents},
+		{"max_price_cents", &filter.MaxPriceCents},
+	} {
+		field := param.field
+		v := query.Get(field)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs = append(errs, FieldError{Field: field, Code: "invalid", Message: field + " debe ser un entero mayor o igual a 0"})
+			continue
+		}
+		*param.dst = &n
+	}
+	if filter.MinPriceCents != nil && filter.MaxPriceCents != nil && *filter.MinPriceCents > *filter.MaxPriceCents {
+		errs = append(errs, FieldError{Field: "max_price_cents", Code: "invalid", Message: "max_price_cents no puede ser menor que min_price_cents"})
+	}
+	if v := query.Get("sort"); v != "" {
+		filter.Sort, filter.Desc = strings.TrimPrefix(v, "-"), strings.HasPrefix(v, "-")
+		if !models.ValidRouteSort(filter.Sort) {
+			errs = append(errs, FieldError{Field: "sort", Code: "invalid", Message: "sort inválido (created_at, name, price, con - para descendente)"})
+		}
+	}
 
-	query := `
-		SELECT id, name, is_active, origin_name, origin_lat, origin_lon,
-		       destination_name, destination_lat, destination_lon,
-		       base_price_cents, currency, created_at, updated_at
-		FROM app.routes
-		WHERE is_active = true
-		ORDER BY created_at DESC
-	`
+	limit, cursor, errs := parsePage(r, errs)
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
 
-	rows, err := pool.Query(r.Context(), query)
+	// Se pide una más para saber si hay página siguiente
+	filter.After = cursor
+	filter.Limit = limit + 1
+	routes, err := h.Routes.ListActive(r.Context(), filter)
+	if errors.Is(err, models.ErrInvalidCursor) {
+		writeValidationErrors(w, []FieldError{{Field: "cursor", Code: "invalid", Message: "cursor inválido"}})
+		return
+	}
 	if err != nil {
-		http.Error(w, "Error consultando rutas", http.StatusInternalServerError)
+		writeStoreError(w, err, "Error consultando rutas")
 		return
 	}
-	defer rows.Close()
-
-	var routes []models.Route
-	for rows.Next() {
-		var route models.Route
-		if err := rows.Scan(
-			&route.ID,
-			&route.Name,
-			&route.IsActive,
-			&route.OriginName,
-			&route.OriginLat,
-			&route.OriginLon,
-			&route.DestinationName,
-			&route.DestinationLat,
-			&route.DestinationLon,
-			&route.BasePriceCents,
-			&route.Currency,
-			&route.CreatedAt,
-			&route.UpdatedAt,
-		); err != nil {
-			http.Error(w, "Error escaneando rutas", http.StatusInternalServerError)
-			return
-		}
-		routes = append(routes, route)
+	if len(routes) > limit {
+		routes = routes[:limit]
+		last := routes[limit-1]
+		setNextCursor(w, &models.Cursor{Key: last.SortKey(filter.Sort), ID: last.ID})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -94,80 +124,41 @@ func GetRoutes(w http.ResponseWriter, r *http.Request) {
 //     {"id": "uuid2", "name": "Parada B"}
 //   ]
 // }
-func GetRouteByID(w http.ResponseWriter, r *http.Request) {
-	pool := db.GetDB()
-
+func (h *Handler) GetRouteByID(w http.ResponseWriter, r *http.Request) {
 	routeID := chi.URLParam(r, "id")
 	if routeID == "" {
-		http.Error(w, "ID de ruta requerido", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta requerido")
 		return
 	}
 
 	// Validar UUID
-	_, err := uuid.Parse(routeID)
+	id, err := uuid.Parse(routeID)
 	if err != nil {
-		http.Error(w, "ID de ruta inválido", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
 		return
 	}
 
 	// Consultar ruta
-	routeQuery := `
-		SELECT id, name, is_active, origin_name, origin_lat, origin_lon,
-		       destination_name, destination_lat, destination_lon,
-		       base_price_cents, currency, created_at, updated_at
-		FROM app.routes
-		WHERE id = $1
-	`
-
-	var route models.Route
-	err = pool.QueryRow(r.Context(), routeQuery, routeID).Scan(
-		&route.ID,
-		&route.Name,
-		&route.IsActive,
-		&route.OriginName,
-		&route.OriginLat,
-		&route.OriginLon,
-		&route.DestinationName,
-		&route.DestinationLat,
-		&route.DestinationLon,
-		&route.BasePriceCents,
-		&route.Currency,
-		&route.CreatedAt,
-		&route.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Ruta no encontrada", http.StatusNotFound)
+	route, err := h.Routes.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
 		return
 	}
 	if err != nil {
-		http.Error(w, "Error consultando ruta", http.StatusInternalServerError)
+		writeStoreError(w, err, "Error consultando ruta")
 		return
 	}
 
-	// Consultar paradas de la ruta
-	stopsQuery := `
-		SELECT id, name
-		FROM app.route_stops
-		WHERE route_id = $1 AND is_active = true
-		ORDER BY stop_order ASC
-	`
-
-	rows, err := pool.Query(r.Context(), stopsQuery, routeID)
+	// Consultar paradas activas de la ruta
+	routeStops, err := h.Stops.ListByRoute(r.Context(), id, true)
 	if err != nil {
-		http.Error(w, "Error consultando paradas", http.StatusInternalServerError)
+		writeStoreError(w, err, "Error consultando paradas")
 		return
 	}
-	defer rows.Close()
 
-	var stops []models.StopInfo
-	for rows.Next() {
-		var stop models.StopInfo
-		if err := rows.Scan(&stop.ID, &stop.Name); err != nil {
-			http.Error(w, "Error escaneando paradas", http.StatusInternalServerError)
-			return
-		}
-		stops = append(stops, stop)
+	stops := make([]models.StopInfo, 0, len(routeStops))
+	for _, stop := range routeStops {
+		stops = append(stops, models.StopInfo{ID: stop.ID, Name: stop.Name})
 	}
 
 	// Construir respuesta
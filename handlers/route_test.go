@@ -0,0 +1,162 @@
+package handlers_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestRouteCRUD(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+
+	route, stops := s.route(admin, "Plaza de Armas", "Parque Kennedy", "Estación Central")
+	if !route.IsActive || route.Currency != "PEN" {
+		t.Fatalf("ruta creada = %+v, se esperaba activa y en PEN", route)
+	}
+	path := "/routes/" + route.ID.String()
+
+	detail := decode[models.RouteDetail](t, s.do(http.MethodGet, path, "", nil), http.StatusOK)
+	if detail.Name != "Ruta Centro - Norte" || detail.BasePrice != 5 || len(detail.Stops) != 3 {
+		t.Fatalf("detalle = %+v", detail)
+	}
+	for i, stop := range detail.Stops {
+		if stop.ID != stops[i].ID {
+			t.Fatalf("parada %d = %s, se esperaba %s", i, stop.ID, stops[i].ID)
+		}
+	}
+
+	rec := s.do(http.MethodPut, "/admin/routes/"+route.ID.String(), admin, map[string]any{
+		"name":             "Ruta Centro - Callao",
+		"origin_name":      "Centro",
+		"origin_lat":       -12.0464,
+		"origin_lon":       -77.0428,
+		"destination_name": "Callao",
+		"destination_lat":  -12.0566,
+		"destination_lon":  -77.1181,
+		"base_price_cents": 650,
+	})
+	updated := decode[models.Route](t, rec, http.StatusOK)
+	if updated.Name != "Ruta Centro - Callao" || updated.BasePriceCents != 650 {
+		t.Fatalf("ruta actualizada = %+v", updated)
+	}
+
+	rec = s.do(http.MethodPost, "/admin/routes/"+route.ID.String()+"/deactivate", admin, nil)
+	if got := decode[models.Route](t, rec, http.StatusOK); got.IsActive {
+		t.Fatal("la ruta sigue activa después de desactivarla")
+	}
+	listed := decode[[]models.Route](t, s.do(http.MethodGet, "/routes", "", nil), http.StatusOK)
+	if len(listed) != 0 {
+		t.Fatalf("GET /routes lista %d rutas desactivadas", len(listed))
+	}
+
+	rec = s.do(http.MethodDelete, "/admin/routes/"+route.ID.String(), admin, nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d: %s", rec.Code, rec.Body)
+	}
+	assertError(t, s.do(http.MethodGet, path, "", nil), http.StatusNotFound, handlers.ErrCodeRouteNotFound)
+}
+
+func TestRouteErrors(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	missing := uuid.NewString()
+
+	assertError(t, s.do(http.MethodGet, "/routes/no-es-uuid", "", nil), http.StatusBadRequest, handlers.ErrCodeInvalidID)
+	assertError(t, s.do(http.MethodGet, "/routes/"+missing, "", nil), http.StatusNotFound, handlers.ErrCodeRouteNotFound)
+	assertError(t, s.do(http.MethodDelete, "/admin/routes/"+missing, admin, nil), http.StatusNotFound, handlers.ErrCodeRouteNotFound)
+	assertError(t, s.do(http.MethodPost, "/admin/routes", admin, map[string]any{"name": ""}), http.StatusBadRequest, handlers.ErrCodeValidation)
+	assertError(t, s.do(http.MethodPost, "/admin/routes", "", nil), http.StatusUnauthorized, handlers.ErrCodeUnauthorized)
+	assertError(t, s.do(http.MethodPost, "/admin/routes", passenger, nil), http.StatusForbidden, handlers.ErrCodeForbidden)
+}
+
+func TestDeleteRouteWithTrips(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+
+	route, _ := s.route(admin, "A", "B")
+	s.bookTrip(passenger, s.departure(route.ID, 10, 24*time.Hour))
+
+	assertError(t, s.do(http.MethodDelete, "/admin/routes/"+route.ID.String(), admin, nil), http.StatusConflict, handlers.ErrCodeRouteHasTrips)
+}
+
+func TestListRoutes(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+
+	create := func(name string, price int, currency string) models.Route {
+		rec := s.do(http.MethodPost, "/admin/routes", admin, map[string]any{
+			"name": name, "origin_name": "Origen", "origin_lat": -12.0, "origin_lon": -77.0,
+			"destination_name": "Destino", "destination_lat": -11.9, "destination_lon": -77.05,
+			"base_price_cents": price, "currency": currency,
+		})
+		return decode[models.Route](t, rec, http.StatusCreated)
+	}
+	callao := create("Callao - Centro", 400, "PEN")
+	surco := create("Surco 100%", 300, "USD")
+	chorrillos := create("Chorrillos_Este", 600, "PEN")
+	lince := create("Lince", 500, "PEN")
+	s.do(http.MethodPost, "/admin/routes/"+lince.ID.String()+"/deactivate", admin, nil)
+
+	names := func(path string) ([]string, string) {
+		t.Helper()
+		rec := s.do(http.MethodGet, path, "", nil)
+		var got []string
+		for _, route := range decode[[]models.Route](t, rec, http.StatusOK) {
+			got = append(got, route.Name)
+		}
+		return got, rec.Header().Get(handlers.NextCursorHeader)
+	}
+
+	// Recorrer por nombre de a dos con el cursor
+	var all []string
+	path := "/routes?sort=name&limit=2"
+	for path != "" {
+		page, cursor := names(path)
+		all = append(all, page...)
+		path = ""
+		if cursor != "" {
+			path = "/routes?sort=name&limit=2&cursor=" + cursor
+		}
+	}
+	if want := []string{callao.Name, chorrillos.Name, surco.Name}; !slices.Equal(all, want) {
+		t.Fatalf("rutas por nombre = %v, se esperaba %v", all, want)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"sort=-price", []string{chorrillos.Name, callao.Name, surco.Name}},
+		{"q=CENTRO", []string{callao.Name}},
+		{"q=100%25", []string{surco.Name}},
+		{"q=_", []string{chorrillos.Name}},
+		{"currency=usd", []string{surco.Name}},
+		{"min_price_cents=350&max_price_cents=600&sort=price", []string{callao.Name, chorrillos.Name}},
+		{"q=lince", nil},
+	}
+	for _, tt := range tests {
+		if got, _ := names("/routes?" + tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: rutas = %v, se esperaba %v", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{
+		"sort=fecha",
+		"limit=101",
+		"min_price_cents=-1",
+		"min_price_cents=500&max_price_cents=100",
+		"currency=soles",
+		"cursor=no-es-un-cursor",
+		"sort=price&cursor=" + (models.Cursor{Key: "abc", ID: uuid.New()}).Encode(),
+	} {
+		assertError(t, s.do(http.MethodGet, "/routes?"+query, "", nil), http.StatusBadRequest, handlers.ErrCodeValidation)
+	}
+}
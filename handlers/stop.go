@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultNearbyRadius = 500.0
+	maxNearbyRadius     = 5000.0
+	defaultNearbyLimit  = 20
+	maxNearbyLimit      = 100
+)
+
+// NearbyStops retorna las paradas activas más cercanas a un punto
+//
+// Request:
+// GET /stops/nearby?lat=-12.0464&lon=-77.0428&radius_m=500&limit=20
+//
+// lat y lon son obligatorios. radius_m es el radio en metros (por defecto
+// 500, máximo 5000) y limit la cantidad máxima de paradas (por defecto 20,
+// máximo 100).
+//
+// Response:
+// 200 OK
+// [
+//   {
+//     "id": "uuid",
+//     "name": "Plaza de Armas",
+//     "latitude": -12.0464,
+//     "longitude": -77.0428,
+//     "route": {"id": "uuid", "name": "Ruta Centro - Norte", ...},
+//     "distance_m": 35.2
+//   }
+// ]
+func (h *Handler) NearbyStops(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var errs []FieldError
+	lat := parseCoordinate(query.Get("lat"), "lat", 90, &errs)
+	lon := parseCoordinate(query.Get("lon"), "lon", 180, &errs)
+
+	radius := defaultNearbyRadius
+	if v := query.Get("radius_m"); v != "" {
+		n, err := parseFinite(v)
+		if err != nil || n <= 0 || n > maxNearbyRadius {
+			errs = append(errs, FieldError{Field: "radius_m", Code: "invalid", Message: "radius_m debe estar entre 1 y 5000"})
+		} else {
+			radius = n
+		}
+	}
+	limit := defaultNearbyLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNearbyLimit {
+			errs = append(errs, FieldError{Field: "limit", Code: "invalid", Message: "limit debe estar entre 1 y 100"})
+		} else {
+			limit = n
+		}
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	stops, err := h.Stops.Nearby(r.Context(), lat, lon, radius, limit)
+	if err != nil {
+		writeStoreError(w, err, "Error buscando paradas")
+		return
+	}
+	for i := range stops {
+		stops[i].DistanceMeters = roundMeters(stops[i].DistanceMeters)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stops)
+}
+
+// errNotFinite indica que un número es NaN o infinito
+var errNotFinite = errors.New("el número debe ser finito")
+
+// parseFinite lee un float64 como strconv.ParseFloat pero rechaza "NaN" e
+// "Inf", que pasarían cualquier comparación de rango
+func parseFinite(v string) (float64, error) {
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, errNotFinite
+	}
+	return n, nil
+}
+
+// parseCoordinate lee un parámetro obligatorio entre -limit y limit; NaN e
+// Inf se rechazan como fuera de rango
+func parseCoordinate(v, field string, limit float64, errs *[]FieldError) float64 {
+	if v == "" {
+		*errs = append(*errs, FieldError{Field: field, Code: "required", Message: field + " es requerido"})
+		return 0
+	}
+	n, err := parseFinite(v)
+	if err != nil || n < -limit || n > limit {
+		*errs = append(*errs, FieldError{
+			Field:   field,
+			Code:    "invalid",
+			Message: field + " debe estar entre -" + strconv.Itoa(int(limit)) + " y " + strconv.Itoa(int(limit)),
+		})
+		return 0
+	}
+	return n
+}
+
+// roundMeters redondea una distancia a decímetros
+func roundMeters(m float64) float64 {
+	return math.Round(m*10) / 10
+}
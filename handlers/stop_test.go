@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestNearbyStops(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	// Las paradas quedan a ~1.1 km una de otra hacia el norte
+	_, stops := s.route(admin, "A", "B", "C")
+
+	got := decode[[]models.NearbyStop](t, s.do(http.MethodGet, "/stops/nearby?lat=-12.0264&lon=-77.0428&radius_m=1500", "", nil), http.StatusOK)
+	if len(got) != 2 || got[0].ID != stops[2].ID || got[1].ID != stops[1].ID {
+		t.Fatalf("paradas cercanas = %+v", got)
+	}
+	if got[0].DistanceMeters != 0 || got[0].Route.Name != "Ruta Centro - Norte" {
+		t.Fatalf("parada más cercana = %+v", got[0])
+	}
+}
+
+func TestNearbyStopsValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, query := range []string{
+		"lon=-77.0",
+		"lat=-12.0",
+		"lat=95&lon=-77.0",
+		"lat=NaN&lon=-77.0",
+		"lat=-12.0&lon=NaN",
+		"lat=Inf&lon=-77.0",
+		"lat=-12.0&lon=-Inf",
+		"lat=-12.0&lon=-77.0&radius_m=NaN",
+		"lat=-12.0&lon=-77.0&radius_m=+Inf",
+		"lat=-12.0&lon=-77.0&limit=0",
+	} {
+		assertError(t, s.do(http.MethodGet, "/stops/nearby?"+query, "", nil), http.StatusBadRequest, handlers.ErrCodeValidation)
+	}
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// TimetableRequest estructura para crear o editar un horario.
+// IsActive es opcional; por defecto el horario queda activo.
+type TimetableRequest struct {
+	Weekdays []int    `json:"weekdays"` // 1 = lunes ... 7 = domingo
+	Times    []string `json:"times"`    // "HH:MM", hora de Lima
+	Capacity int      `json:"capacity"`
+	Vehicle  string   `json:"vehicle"`
+	IsActive *bool    `json:"is_active"`
+}
+
+// timetable valida el request y lo convierte en el modelo a guardar
+func (req *TimetableRequest) timetable(id, routeID uuid.UUID) (*models.Timetable, string) {
+	if req.Capacity < 1 {
+		return nil, "capacity debe ser mayor o igual a 1"
+	}
+	timetable := &models.Timetable{
+		ID:           id,
+		RouteID:      routeID,
+		Weekdays:     req.Weekdays,
+		Times:        req.Times,
+		Capacity:     req.Capacity,
+		VehicleLabel: strings.TrimSpace(req.Vehicle),
+		IsActive:     req.IsActive == nil || *req.IsActive,
+	}
+	if err := timetable.Normalize(); err != nil {
+		return nil, err.Error()
+	}
+	return timetable, ""
+}
+
+// ListRouteTimetables retorna los horarios de una ruta, incluidos los inactivos
+//
+// Request:
+// GET /admin/routes/{id}/timetables
+//
+// Response:
+// 200 OK
+// [
+//   {"id": "uuid", "route_id": "uuid", "weekdays": [1, 2, 3, 4, 5],
+//    "times": ["06:00", "06:30", "07:00"], "capacity": 15, "vehicle": "Combi ABC-123",
+//    "is_active": true, ...}
+// ]
+func (h *Handler) ListRouteTimetables(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	timetables, err := h.Timetables.ListByRoute(r.Context(), routeID)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando horarios")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timetables)
+}
+
+// CreateRouteTimetable agrega un horario recurrente a la ruta. Si la ruta
+// está activa genera de inmediato sus salidas de los próximos
+// models.ScheduleDays días; el worker de salidas completa los días siguientes.
+//
+// Request:
+// POST /admin/routes/{id}/timetables
+// {
+//   "weekdays": [1, 2, 3, 4, 5],
+//   "times": ["06:00", "06:30", "07:00"],
+//   "capacity": 15,
+//   "vehicle": "Combi ABC-123"
+// }
+//
+// Response:
+// 201 Created (horario creado)
+func (h *Handler) CreateRouteTimetable(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+
+	var req TimetableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	timetable, msg := req.timetable(uuid.Nil, routeID)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, msg)
+		return
+	}
+
+	route, err := h.Routes.Get(r.Context(), routeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
+		return
+	}
+
+	if err := h.Timetables.Create(r.Context(), timetable); err != nil {
+		writeStoreError(w, err, "Error creando horario")
+		return
+	}
+	h.scheduleTimetable(r.Context(), route, timetable)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(timetable)
+}
+
+// UpdateRouteTimetable reemplaza un horario. Las salidas ya generadas
+// (y sus reservas) no cambian; para quitarlas se cancelan una por una. Las
+// horas nuevas se generan como al crear el horario.
+//
+// Request:
+// PUT /admin/routes/{id}/timetables/{timetableID}
+// (mismo cuerpo que al crear)
+//
+// Response:
+// 200 OK (horario actualizado)
+func (h *Handler) UpdateRouteTimetable(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	timetableID, err := uuid.Parse(chi.URLParam(r, "timetableID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de horario inválido")
+		return
+	}
+
+	var req TimetableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
+		return
+	}
+	timetable, msg := req.timetable(timetableID, routeID)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, ErrCodeValidation, msg)
+		return
+	}
+
+	err = h.Timetables.Update(r.Context(), timetable)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeTimetableNotFound, "Horario no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando horario")
+		return
+	}
+	if route, err := h.Routes.Get(r.Context(), routeID); err == nil {
+		h.scheduleTimetable(r.Context(), route, timetable)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timetable)
+}
+
+// scheduleTimetable genera las salidas del horario si la ruta está activa.
+// Un error solo se registra: el worker de salidas las vuelve a generar.
+func (h *Handler) scheduleTimetable(ctx context.Context, route *models.Route, timetable *models.Timetable) {
+	if !route.IsActive {
+		return
+	}
+	if err := h.Departures.Materialize(ctx, timetable.ScheduledDepartures(time.Now())); err != nil {
+		log.Printf("Error generando salidas del horario %s: %v", timetable.ID, err)
+	}
+}
+
+// DeleteRouteTimetable borra un horario. Las salidas ya generadas se conservan.
+//
+// Request:
+// DELETE /admin/routes/{id}/timetables/{timetableID}
+//
+// Response:
+// 204 No Content
+func (h *Handler) DeleteRouteTimetable(w http.ResponseWriter, r *http.Request) {
+	routeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de ruta inválido")
+		return
+	}
+	timetableID, err := uuid.Parse(chi.URLParam(r, "timetableID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de horario inválido")
+		return
+	}
+
+	timetable, err := h.Timetables.Get(r.Context(), timetableID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && timetable.RouteID != routeID) {
+		writeError(w, http.StatusNotFound, ErrCodeTimetableNotFound, "Horario no encontrado")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando horario")
+		return
+	}
+
+	if err := h.Timetables.Delete(r.Context(), timetableID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		writeStoreError(w, err, "Error borrando horario")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,34 +1,65 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/auth"
 	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
 )
 
-// UUID dummy para el usuario (TODO: reemplazar con autenticación real)
-const dummyUserID = "00000000-0000-0000-0000-000000000001"
-
 // CreateTripRequest estructura para crear un viaje
 type CreateTripRequest struct {
-	RouteID        uuid.UUID  `json:"route_id"`
-	PickupStopID   *uuid.UUID `json:"pickup_stop_id"`
-	DropoffStopID  *uuid.UUID `json:"dropoff_stop_id"`
-	PaymentMethod  string      `json:"payment_method"` // cash, yape, pling
+	RouteID       uuid.UUID  `json:"route_id"`
+	DepartureID   uuid.UUID  `json:"departure_id"`
+	PickupStopID  *uuid.UUID `json:"pickup_stop_id"`
+	DropoffStopID *uuid.UUID `json:"dropoff_stop_id"`
+	PaymentMethod string     `json:"payment_method"` // cash, yape, pling
+}
+
+// validPaymentMethods son los medios de pago aceptados
+var validPaymentMethods = map[string]bool{
+	models.PaymentMethodCash:  true,
+	models.PaymentMethodYape:  true,
+	models.PaymentMethodPling: true,
+}
+
+// validate retorna los errores de todos los campos inválidos del request
+func (req *CreateTripRequest) validate() []FieldError {
+	var fields []FieldError
+	if req.RouteID == uuid.Nil {
+		fields = append(fields, FieldError{Field: "route_id", Code: "required", Message: "route_id es requerido"})
+	}
+	if req.DepartureID == uuid.Nil {
+		fields = append(fields, FieldError{Field: "departure_id", Code: "required", Message: "departure_id es requerido"})
+	}
+	switch {
+	case req.PaymentMethod == "":
+		fields = append(fields, FieldError{Field: "payment_method", Code: "required", Message: "payment_method es requerido"})
+	case !validPaymentMethods[req.PaymentMethod]:
+		fields = append(fields, FieldError{Field: "payment_method", Code: "invalid", Message: "payment_method inválido (cash, yape, pling)"})
+	}
+	return fields
 }
 
-// CreateTrip crea un nuevo viaje
+// CreateTrip crea un nuevo viaje y reserva un asiento en la salida elegida.
+// La reserva es atómica: reservas simultáneas nunca superan la capacidad.
+// El precio se calcula por tramo con la tarifa vigente de la ruta y queda
+// guardado en el viaje junto con la versión de la tarifa.
 //
 // Request:
 // POST /trips
+// Authorization: Bearer <token>
 // {
 //   "route_id": "uuid-de-la-ruta",
+//   "departure_id": "uuid-de-la-salida",
 //   "pickup_stop_id": "uuid-parada-recogida | null",
 //   "dropoff_stop_id": "uuid-parada-dejada | null",
 //   "payment_method": "cash"
@@ -39,6 +70,7 @@ type CreateTripRequest struct {
 // {
 //   "id": "uuid-del-viaje",
 //   "route_id": "uuid-de-la-ruta",
+//   "departure_id": "uuid-de-la-salida",
 //   "passenger_id": "00000000-0000-0000-0000-000000000001",
 //   "pickup_stop_id": "uuid-parada-recogida | null",
 //   "dropoff_stop_id": "uuid-parada-dejada | null",
@@ -46,112 +78,114 @@ type CreateTripRequest struct {
 //   "payment_method": "cash",
 //   "price_cents": 500,
 //   "currency": "PEN",
+//   "fare_rule_id": "uuid | null",
+//   "fare_rule_version": 1,
 //   "scheduled_at": "2026-01-10T10:00:00Z",
 //   "created_at": "2026-01-09T15:30:00Z",
 //   "updated_at": "2026-01-09T15:30:00Z"
 // }
-func CreateTrip(w http.ResponseWriter, r *http.Request) {
-	pool := db.GetDB()
+//
+// Errores (ver ErrorResponse):
+// 400 validation_failed con details por campo:
+//   {"error": {"code": "validation_failed", "message": "Datos inválidos",
+//              "details": [{"field": "route_id", "code": "required", "message": "route_id es requerido"}]}}
+// 400 stop_not_on_route | zero_length_segment | stop_order_reversed | stop_inactive si el tramo es inválido
+// 404 route_not_found si la ruta no existe
+// 409 route_inactive si la ruta está desactivada
+// 409 departure_full si la salida no tiene asientos
+// 409 departure_closed si la salida ya partió o fue cancelada
+func (h *Handler) CreateTrip(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
 
 	var req CreateTripRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Error decodificando request", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Error decodificando request")
 		return
 	}
 
-	// Validar campos requeridos
-	if req.RouteID == uuid.Nil {
-		http.Error(w, "route_id es requerido", http.StatusBadRequest)
+	if fields := req.validate(); len(fields) > 0 {
+		writeValidationErrors(w, fields)
 		return
 	}
-	if req.PaymentMethod == "" {
-		http.Error(w, "payment_method es requerido", http.StatusBadRequest)
+
+	// Verificar que la ruta existe y obtener precio
+	route, err := h.Routes.Get(r.Context(), req.RouteID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeRouteNotFound, "Ruta no encontrada")
 		return
 	}
-
-	// Validar payment_method
-	validMethods := map[string]bool{"cash": true, "yape": true, "pling": true}
-	if !validMethods[req.PaymentMethod] {
-		http.Error(w, "payment_method inválido (cash, yape, pling)", http.StatusBadRequest)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando ruta")
 		return
 	}
-
-	// Verificar que la ruta existe y obtener precio
-	var routeExists bool
-	var basePriceCents int
-	var currency string
-	err := pool.QueryRow(r.Context(),
-		"SELECT EXISTS(SELECT 1 FROM app.routes WHERE id = $1), base_price_cents, currency FROM app.routes WHERE id = $1",
-		req.RouteID).Scan(&routeExists, &basePriceCents, &currency)
-	if err != nil || !routeExists {
-		http.Error(w, "Ruta no encontrada", http.StatusNotFound)
+	if !route.IsActive {
+		writeError(w, http.StatusConflict, ErrCodeRouteInactive, "La ruta está desactivada")
 		return
 	}
 
-	// Si se proporcionan paradas, verificar que existen y pertenecen a la ruta
-	if req.PickupStopID != nil {
-		var stopExists bool
-		err := pool.QueryRow(r.Context(),
-			"SELECT EXISTS(SELECT 1 FROM app.route_stops WHERE id = $1 AND route_id = $2)",
-			*req.PickupStopID, req.RouteID).Scan(&stopExists)
-		if err != nil || !stopExists {
-			http.Error(w, "Parada de recogida no encontrada en esta ruta", http.StatusBadRequest)
-			return
-		}
+	// Verificar que la salida es de la ruta y aún no parte
+	departure, err := h.Departures.Get(r.Context(), req.DepartureID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && departure.RouteID != req.RouteID) {
+		writeError(w, http.StatusBadRequest, ErrCodeDepartureNotFound, "Salida no encontrada en esta ruta")
+		return
 	}
-
-	if req.DropoffStopID != nil {
-		var stopExists bool
-		err := pool.QueryRow(r.Context(),
-			"SELECT EXISTS(SELECT 1 FROM app.route_stops WHERE id = $1 AND route_id = $2)",
-			*req.DropoffStopID, req.RouteID).Scan(&stopExists)
-		if err != nil || !stopExists {
-			http.Error(w, "Parada de dejada no encontrada en esta ruta", http.StatusBadRequest)
-			return
-		}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando salida")
+		return
+	}
+	if !departure.Bookable(time.Now()) {
+		writeError(w, http.StatusConflict, ErrCodeDepartureClosed, "La salida ya partió o fue cancelada")
+		return
 	}
 
-	// Crear el viaje
-	tripID := uuid.New()
-	passengerID := uuid.MustParse(dummyUserID)
-	now := time.Now()
-	scheduledAt := now.Add(24 * time.Hour) // Programar para mañana por defecto
-
-	query := `
-		INSERT INTO app.trips (id, route_id, passenger_id, pickup_stop_id, dropoff_stop_id, status, payment_method, price_cents, currency, scheduled_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, 'requested', $6, $7, $8, $9, $10, $10)
-		RETURNING id, route_id, passenger_id, pickup_stop_id, dropoff_stop_id, status, payment_method, price_cents, currency, scheduled_at, created_at, updated_at
-	`
-
-	var trip models.Trip
-	err = pool.QueryRow(r.Context(), query,
-		tripID,
-		req.RouteID,
-		passengerID,
-		req.PickupStopID,
-		req.DropoffStopID,
-		req.PaymentMethod,
-		basePriceCents,
-		currency,
-		scheduledAt,
-		now,
-	).Scan(
-		&trip.ID,
-		&trip.RouteID,
-		&trip.PassengerID,
-		&trip.PickupStopID,
-		&trip.DropoffStopID,
-		&trip.Status,
-		&trip.PaymentMethod,
-		&trip.PriceCents,
-		&trip.Currency,
-		&trip.ScheduledAt,
-		&trip.CreatedAt,
-		&trip.UpdatedAt,
-	)
+	// Verificar que las paradas son de la ruta y están en orden, y
+	// calcular el precio del tramo
+	stops, err := h.Stops.ListByRoute(r.Context(), req.RouteID, false)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando paradas")
+		return
+	}
+	path, err := models.Segment(stops, req.PickupStopID, req.DropoffStopID)
+	if err != nil {
+		writeSegmentError(w, err)
+		return
+	}
+	quote, err := h.quoteFare(r.Context(), route, path)
+	if errors.Is(err, models.ErrNoFare) {
+		writeError(w, http.StatusBadRequest, ErrCodeNoFare, "No hay tarifa para este tramo")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error calculando tarifa")
+		return
+	}
 
+	// Crear el viaje reservando el asiento
+	trip := models.Trip{
+		RouteID:         req.RouteID,
+		DepartureID:     &departure.ID,
+		PassengerID:     user.ID,
+		PickupStopID:    req.PickupStopID,
+		DropoffStopID:   req.DropoffStopID,
+		Status:          models.TripStatusRequested,
+		PaymentMethod:   req.PaymentMethod,
+		PriceCents:      quote.PriceCents,
+		Currency:        quote.Currency,
+		FareRuleID:      quote.FareRuleID,
+		FareRuleVersion: quote.FareRuleVersion,
+		ScheduledAt:     &departure.DepartsAt,
+	}
+	err = h.Trips.Create(r.Context(), &trip)
+	if errors.Is(err, repository.ErrDepartureFull) {
+		writeError(w, http.StatusConflict, ErrCodeDepartureFull, "La salida no tiene asientos disponibles")
+		return
+	}
+	if errors.Is(err, repository.ErrDepartureCancelled) {
+		writeError(w, http.StatusConflict, ErrCodeDepartureClosed, "La salida ya partió o fue cancelada")
+		return
+	}
 	if err != nil {
-		http.Error(w, "Error creando viaje", http.StatusInternalServerError)
+		writeStoreError(w, err, "Error creando viaje")
 		return
 	}
 
@@ -160,10 +194,43 @@ func CreateTrip(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(trip)
 }
 
+// canAccessTrip aplica la visibilidad de viajes por rol: los pasajeros solo
+// ven sus propios viajes, los conductores los de las rutas que tienen
+// asignadas y los administradores todos. Vale para leer y cancelar; las
+// demás transiciones usan canOperateTrip.
+func (h *Handler) canAccessTrip(ctx context.Context, user *models.User, trip *models.Trip) (bool, error) {
+	switch {
+	case user.Role == models.RoleAdmin:
+		return true, nil
+	case trip.PassengerID == user.ID:
+		return true, nil
+	case user.Role == models.RoleDriver:
+		return h.Routes.HasDriver(ctx, trip.RouteID, user.ID)
+	}
+	return false, nil
+}
+
+// canOperateTrip indica si el usuario puede confirmar, iniciar o completar
+// el viaje: solo los administradores y los conductores asignados a la ruta.
+// Ser el pasajero no basta, aunque el usuario tenga rol de conductor.
+func (h *Handler) canOperateTrip(ctx context.Context, user *models.User, trip *models.Trip) (bool, error) {
+	switch user.Role {
+	case models.RoleAdmin:
+		return true, nil
+	case models.RoleDriver:
+		return h.Routes.HasDriver(ctx, trip.RouteID, user.ID)
+	}
+	return false, nil
+}
+
 // GetTripByID retorna el estado completo de un viaje
 //
 // Request:
 // GET /trips/{id}
+// Authorization: Bearer <token>
+//
+// Los pasajeros solo ven sus propios viajes, los conductores los viajes de
+// las rutas que tienen asignadas y los administradores todos.
 //
 // Response:
 // 200 OK
@@ -192,120 +259,170 @@ func CreateTrip(w http.ResponseWriter, r *http.Request) {
 //   "scheduled_at": "2026-01-10T10:00:00Z",
 //   "created_at": "2026-01-09T15:30:00Z"
 // }
-func GetTripByID(w http.ResponseWriter, r *http.Request) {
-	pool := db.GetDB()
+func (h *Handler) GetTripByID(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
 
 	tripID := chi.URLParam(r, "id")
 	if tripID == "" {
-		http.Error(w, "ID de viaje requerido", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de viaje requerido")
 		return
 	}
 
 	// Validar UUID
-	_, err := uuid.Parse(tripID)
+	id, err := uuid.Parse(tripID)
 	if err != nil {
-		http.Error(w, "ID de viaje inválido", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de viaje inválido")
 		return
 	}
 
-	// Consultar viaje
-	tripQuery := `
-		SELECT id, route_id, passenger_id, pickup_stop_id, dropoff_stop_id, status, payment_method, price_cents, currency, scheduled_at, created_at, updated_at
-		FROM app.trips
-		WHERE id = $1
-	`
-
-	var trip models.Trip
-	err = pool.QueryRow(r.Context(), tripQuery, tripID).Scan(
-		&trip.ID,
-		&trip.RouteID,
-		&trip.PassengerID,
-		&trip.PickupStopID,
-		&trip.DropoffStopID,
-		&trip.Status,
-		&trip.PaymentMethod,
-		&trip.PriceCents,
-		&trip.Currency,
-		&trip.ScheduledAt,
-		&trip.CreatedAt,
-		&trip.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Viaje no encontrado", http.StatusNotFound)
+	// Consultar viaje; los viajes ajenos se reportan como no encontrados
+	trip, err := h.Trips.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeTripNotFound, "Viaje no encontrado")
 		return
 	}
 	if err != nil {
-		http.Error(w, "Error consultando viaje", http.StatusInternalServerError)
+		writeStoreError(w, err, "Error consultando viaje")
+		return
+	}
+	allowed, err := h.canAccessTrip(r.Context(), user, trip)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando viaje")
+		return
+	}
+	if !allowed {
+		writeError(w, http.StatusNotFound, ErrCodeTripNotFound, "Viaje no encontrado")
 		return
 	}
 
 	// Consultar ruta
-	routeQuery := `
-		SELECT id, name, origin_name, destination_name, base_price_cents
-		FROM app.routes
-		WHERE id = $1
-	`
-
-	var routeID uuid.UUID
-	var routeName, originName, destName string
-	var basePriceCents int
-	err = pool.QueryRow(r.Context(), routeQuery, trip.RouteID).Scan(
-		&routeID,
-		&routeName,
-		&originName,
-		&destName,
-		&basePriceCents,
-	)
-
+	route, err := h.Routes.Get(r.Context(), trip.RouteID)
 	if err != nil {
-		http.Error(w, "Error consultando ruta", http.StatusInternalServerError)
+		writeStoreError(w, err, "Error consultando ruta")
 		return
 	}
 
 	// Consultar paradas (si existen)
 	var pickupInfo *models.StopInfo
 	if trip.PickupStopID != nil {
-		pickupQuery := `SELECT id, name FROM app.route_stops WHERE id = $1`
-		var stop models.StopInfo
-		err = pool.QueryRow(r.Context(), pickupQuery, *trip.PickupStopID).Scan(&stop.ID, &stop.Name)
-		if err == nil {
-			pickupInfo = &stop
+		if stop, err := h.Stops.Get(r.Context(), *trip.PickupStopID); err == nil {
+			pickupInfo = &models.StopInfo{ID: stop.ID, Name: stop.Name}
 		}
 	}
 
 	var dropoffInfo *models.StopInfo
 	if trip.DropoffStopID != nil {
-		dropoffQuery := `SELECT id, name FROM app.route_stops WHERE id = $1`
-		var stop models.StopInfo
-		err = pool.QueryRow(r.Context(), dropoffQuery, *trip.DropoffStopID).Scan(&stop.ID, &stop.Name)
-		if err == nil {
-			dropoffInfo = &stop
+		if stop, err := h.Stops.Get(r.Context(), *trip.DropoffStopID); err == nil {
+			dropoffInfo = &models.StopInfo{ID: stop.ID, Name: stop.Name}
 		}
 	}
 
 	// Construir respuesta
-	price := float64(trip.PriceCents) / 100.0
-	tripDetail := models.TripDetail{
-		ID:            trip.ID,
-		PassengerID:   trip.PassengerID,
-		Route: models.RouteInfo{
-			ID:          routeID,
-			Name:        routeName,
-			Origin:      originName,
-			Destination: destName,
-			BasePrice:   float64(basePriceCents) / 100.0,
-		},
-		Pickup:        pickupInfo,
-		Dropoff:       dropoffInfo,
-		Status:        trip.Status,
-		PaymentMethod: trip.PaymentMethod,
-		Price:         price,
-		Currency:      trip.Currency,
-		ScheduledAt:   trip.ScheduledAt,
-		CreatedAt:     trip.CreatedAt,
-	}
+	tripDetail := models.NewTripDetail(trip, route, pickupInfo, dropoffInfo)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(tripDetail)
 }
+
+// ListMyTrips retorna el historial de viajes del pasajero autenticado
+//
+// Request:
+// GET /me/trips?status=requested,confirmed&route_id=uuid&from=2026-01-01&to=2026-01-31&sort=-scheduled_at&limit=20&cursor=...
+// Authorization: Bearer <token>
+//
+// Todos los parámetros son opcionales. from y to son fechas en hora de Lima
+// (ambas incluidas) y se comparan con scheduled_at (o created_at si el viaje
+// no tiene salida). sort es scheduled_at o -scheduled_at (por defecto, los
+// más recientes primero). Si hay más resultados la respuesta trae el header
+// X-Next-Cursor; se pasa como ?cursor= con los mismos filtros.
+//
+// Response:
+// 200 OK
+// [
+//   {"id": "uuid", "route": {...}, "pickup": {...}, "dropoff": {...}, "status": "requested", ...}
+// ]
+func (h *Handler) ListMyTrips(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	query := r.URL.Query()
+
+	var errs []FieldError
+	filter := repository.TripFilter{PassengerID: user.ID, Desc: true}
+
+	if v := query.Get("status"); v != "" {
+		for _, status := range strings.Split(v, ",") {
+			status = strings.TrimSpace(status)
+			if !models.ValidTripStatus(status) {
+				errs = append(errs, FieldError{Field: "status", Code: "invalid", Message: "status inválido: " + status})
+				continue
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if v := query.Get("route_id"); v != "" {
+		routeID, err := uuid.Parse(v)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "route_id", Code: "invalid", Message: "route_id inválido"})
+		} else {
+			filter.RouteID = &routeID
+		}
+	}
+	if v := query.Get("from"); v != "" {
+		from, err := time.ParseInLocation(time.DateOnly, v, models.LocalZone)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "from", Code: "invalid", Message: "from inválido (formato YYYY-MM-DD)"})
+		} else {
+			filter.From = &from
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		to, err := time.ParseInLocation(time.DateOnly, v, models.LocalZone)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "to", Code: "invalid", Message: "to inválido (formato YYYY-MM-DD)"})
+		} else {
+			// to se incluye completo
+			to = to.AddDate(0, 0, 1)
+			filter.To = &to
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		errs = append(errs, FieldError{Field: "to", Code: "invalid", Message: "to no puede ser anterior a from"})
+	}
+	switch query.Get("sort") {
+	case "", "-scheduled_at":
+	case "scheduled_at":
+		filter.Desc = false
+	default:
+		errs = append(errs, FieldError{Field: "sort", Code: "invalid", Message: "sort inválido (scheduled_at, -scheduled_at)"})
+	}
+
+	limit, cursor, errs := parsePage(r, errs)
+	if cursor != nil {
+		after, err := time.Parse(time.RFC3339Nano, cursor.Key)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "cursor", Code: "invalid", Message: "cursor inválido"})
+		} else {
+			filter.AfterTime = &after
+			filter.AfterID = cursor.ID
+		}
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	// Se pide uno más para saber si hay página siguiente
+	filter.Limit = limit + 1
+	trips, err := h.Trips.ListDetails(r.Context(), filter)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando viajes")
+		return
+	}
+	if len(trips) > limit {
+		trips = trips[:limit]
+		last := trips[limit-1]
+		setNextCursor(w, &models.Cursor{Key: last.SortTime().Format(time.RFC3339Nano), ID: last.ID})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trips)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/auth"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// ConfirmTrip confirma un viaje solicitado (conductor asignado o admin)
+//
+// Request:
+// POST /trips/{id}/confirm
+//
+// Response:
+// 200 OK (viaje con status "confirmed")
+// 403 Forbidden si el usuario no es admin ni conductor asignado a la ruta
+// 409 Conflict si el viaje no está en estado "requested"
+func (h *Handler) ConfirmTrip(w http.ResponseWriter, r *http.Request) {
+	h.transitionTrip(w, r, models.TripStatusConfirmed)
+}
+
+// StartTrip marca el inicio de un viaje confirmado y registra started_at
+// (conductor asignado o admin)
+//
+// Request:
+// POST /trips/{id}/start
+//
+// Response:
+// 200 OK (viaje con status "started")
+// 403 Forbidden si el usuario no es admin ni conductor asignado a la ruta
+// 409 Conflict si el viaje no está en estado "confirmed"
+func (h *Handler) StartTrip(w http.ResponseWriter, r *http.Request) {
+	h.transitionTrip(w, r, models.TripStatusStarted)
+}
+
+// CompleteTrip finaliza un viaje iniciado y registra finished_at
+// (conductor asignado o admin)
+//
+// Request:
+// POST /trips/{id}/complete
+//
+// Response:
+// 200 OK (viaje con status "completed")
+// 403 Forbidden si el usuario no es admin ni conductor asignado a la ruta
+// 409 Conflict si el viaje no está en estado "started"
+func (h *Handler) CompleteTrip(w http.ResponseWriter, r *http.Request) {
+	h.transitionTrip(w, r, models.TripStatusCompleted)
+}
+
+// CancelTrip cancela un viaje que aún no ha iniciado y registra cancelled_at
+// (pasajero dueño, conductor asignado o admin)
+//
+// Request:
+// POST /trips/{id}/cancel
+//
+// Si cancela el pasajero se aplica la política de cancelación de la ruta
+// (ver GET /trips/{id}/cancellation); si cancela el conductor o un admin no
+// hay cargo. El cargo y el reembolso quedan guardados en el viaje.
+//
+// Response:
+// 200 OK (viaje con status "cancelled", cancellation_fee_cents y refund_cents)
+// 409 Conflict si el viaje ya inició o terminó
+func (h *Handler) CancelTrip(w http.ResponseWriter, r *http.Request) {
+	user, trip, ok := h.visibleTrip(w, r)
+	if !ok {
+		return
+	}
+	policy, err := h.cancellationPolicyFor(r.Context(), user, trip)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando política de cancelación")
+		return
+	}
+
+	var from string
+	trip, err = h.Trips.Update(r.Context(), trip.ID, func(t *models.Trip) error {
+		from = t.Status
+		now := time.Now()
+		quote, err := cancellationQuote(policy, t, now)
+		if err != nil {
+			return err
+		}
+		return t.Cancel(quote, now)
+	})
+	if errors.Is(err, models.ErrInvalidTransition) {
+		writeError(w, http.StatusConflict, ErrCodeInvalidTransition, "Transición de estado no permitida: "+from+" → "+models.TripStatusCancelled)
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando viaje")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trip)
+}
+
+// transitionTrip mueve un viaje al estado to según la tabla de transiciones.
+// Solo lo puede hacer un admin o un conductor asignado a la ruta del viaje,
+// aunque el usuario sea el pasajero (ver canOperateTrip).
+// La lectura del estado actual y la actualización ocurren en una sola
+// operación atómica del repositorio, para evitar transiciones concurrentes.
+func (h *Handler) transitionTrip(w http.ResponseWriter, r *http.Request, to string) {
+	user, trip, ok := h.visibleTrip(w, r)
+	if !ok {
+		return
+	}
+	allowed, err := h.canOperateTrip(r.Context(), user, trip)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando viaje")
+		return
+	}
+	if !allowed {
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, "Solo el conductor asignado o un admin puede operar este viaje")
+		return
+	}
+
+	var from string
+	trip, err = h.Trips.Update(r.Context(), trip.ID, func(t *models.Trip) error {
+		from = t.Status
+		return t.Transition(to, time.Now())
+	})
+	if errors.Is(err, models.ErrInvalidTransition) {
+		writeError(w, http.StatusConflict, ErrCodeInvalidTransition, "Transición de estado no permitida: "+from+" → "+to)
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error actualizando viaje")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trip)
+}
+
+// visibleTrip lee el viaje {id} de la URL si el usuario puede verlo (ver
+// canAccessTrip). Si no, responde el error y retorna ok en false.
+func (h *Handler) visibleTrip(w http.ResponseWriter, r *http.Request) (*models.User, *models.Trip, bool) {
+	user, _ := auth.UserFromContext(r.Context())
+
+	tripID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "ID de viaje inválido")
+		return nil, nil, false
+	}
+
+	// Solo se puede actuar sobre viajes visibles para el usuario
+	trip, err := h.Trips.Get(r.Context(), tripID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeTripNotFound, "Viaje no encontrado")
+		return nil, nil, false
+	}
+	if err != nil {
+		writeStoreError(w, err, "Error consultando viaje")
+		return nil, nil, false
+	}
+	allowed, err := h.canAccessTrip(r.Context(), user, trip)
+	if err != nil {
+		writeStoreError(w, err, "Error consultando viaje")
+		return nil, nil, false
+	}
+	if !allowed {
+		writeError(w, http.StatusNotFound, ErrCodeTripNotFound, "Viaje no encontrado")
+		return nil, nil, false
+	}
+	return user, trip, true
+}
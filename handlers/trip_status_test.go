@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestTripLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	driver, driverToken := s.login(models.RoleDriver)
+
+	route, _ := s.route(admin, "A", "B")
+	if err := s.repos.Routes.AssignDriver(context.Background(), route.ID, driver.ID); err != nil {
+		t.Fatal(err)
+	}
+	trip := s.bookTrip(passenger, s.departure(route.ID, 10, 24*time.Hour))
+	path := "/trips/" + trip.ID.String()
+
+	// completar antes de iniciar no está permitido
+	assertError(t, s.do(http.MethodPost, path+"/complete", driverToken, nil), http.StatusConflict, handlers.ErrCodeInvalidTransition)
+
+	steps := []struct {
+		action string
+		status string
+	}{
+		{"confirm", models.TripStatusConfirmed},
+		{"start", models.TripStatusStarted},
+		{"complete", models.TripStatusCompleted},
+	}
+	for _, step := range steps {
+		got := decode[models.Trip](t, s.do(http.MethodPost, path+"/"+step.action, driverToken, nil), http.StatusOK)
+		if got.Status != step.status {
+			t.Fatalf("%s: status = %q, se esperaba %q", step.action, got.Status, step.status)
+		}
+	}
+
+	got, err := s.repos.Trips.Get(context.Background(), trip.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.StartedAt == nil || got.FinishedAt == nil {
+		t.Fatalf("started_at = %v, finished_at = %v", got.StartedAt, got.FinishedAt)
+	}
+
+	// completed es final
+	assertError(t, s.do(http.MethodPost, path+"/cancel", passenger, nil), http.StatusConflict, handlers.ErrCodeInvalidTransition)
+}
+
+func TestCancelTrip(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	_, stranger := s.login(models.RolePassenger)
+
+	route, _ := s.route(admin, "A", "B")
+	departure := s.departure(route.ID, 1, 24*time.Hour)
+	trip := s.bookTrip(passenger, departure)
+	path := "/trips/" + trip.ID.String() + "/cancel"
+
+	assertError(t, s.do(http.MethodPost, path, stranger, nil), http.StatusNotFound, handlers.ErrCodeTripNotFound)
+
+	got := decode[models.Trip](t, s.do(http.MethodPost, path, passenger, nil), http.StatusOK)
+	if got.Status != models.TripStatusCancelled || got.CancelledAt == nil {
+		t.Fatalf("viaje cancelado = %+v", got)
+	}
+	assertError(t, s.do(http.MethodPost, path, passenger, nil), http.StatusConflict, handlers.ErrCodeInvalidTransition)
+
+	// cancelar libera el único asiento
+	s.bookTrip(stranger, departure)
+}
+
+func TestTripTransitionPermissions(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, passenger := s.login(models.RolePassenger)
+	assigned, assignedToken := s.login(models.RoleDriver)
+	_, selfBookingDriver := s.login(models.RoleDriver)
+
+	route, _ := s.route(admin, "A", "B")
+	if err := s.repos.Routes.AssignDriver(context.Background(), route.ID, assigned.ID); err != nil {
+		t.Fatal(err)
+	}
+	departure := s.departure(route.ID, 10, 24*time.Hour)
+
+	// un pasajero no llega a los endpoints de operación
+	trip := s.bookTrip(passenger, departure)
+	assertError(t, s.do(http.MethodPost, "/trips/"+trip.ID.String()+"/confirm", passenger, nil),
+		http.StatusForbidden, handlers.ErrCodeForbidden)
+
+	// un conductor sin la ruta asignada no puede operar su propio viaje
+	own := s.bookTrip(selfBookingDriver, departure)
+	path := "/trips/" + own.ID.String()
+	assertError(t, s.do(http.MethodPost, path+"/confirm", selfBookingDriver, nil), http.StatusForbidden, handlers.ErrCodeForbidden)
+
+	// pero sí puede verlo
+	decode[models.TripDetail](t, s.do(http.MethodGet, path, selfBookingDriver, nil), http.StatusOK)
+
+	// el conductor asignado y el admin sí lo operan
+	decode[models.Trip](t, s.do(http.MethodPost, path+"/confirm", assignedToken, nil), http.StatusOK)
+	decode[models.Trip](t, s.do(http.MethodPost, path+"/start", admin, nil), http.StatusOK)
+}
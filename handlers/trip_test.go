@@ -0,0 +1,228 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+func TestCreateTrip(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	passenger, token := s.login(models.RolePassenger)
+
+	route, stops := s.route(admin, "A", "B", "C")
+	departure := s.departure(route.ID, 10, 24*time.Hour)
+
+	rec := s.do(http.MethodPost, "/trips", token, map[string]any{
+		"route_id":        route.ID,
+		"departure_id":    departure.ID,
+		"pickup_stop_id":  stops[0].ID,
+		"dropoff_stop_id": stops[2].ID,
+		"payment_method":  "yape",
+	})
+	trip := decode[models.Trip](t, rec, http.StatusOK)
+	if trip.Status != models.TripStatusRequested || trip.PassengerID != passenger.ID {
+		t.Fatalf("viaje = %+v", trip)
+	}
+	if trip.PriceCents != 500 || trip.Currency != "PEN" {
+		t.Fatalf("precio = %d %s, se esperaba 500 PEN", trip.PriceCents, trip.Currency)
+	}
+	if trip.ScheduledAt == nil || !trip.ScheduledAt.Equal(departure.DepartsAt) {
+		t.Fatalf("scheduled_at = %v, se esperaba %v", trip.ScheduledAt, departure.DepartsAt)
+	}
+
+	detail := decode[models.TripDetail](t, s.do(http.MethodGet, "/trips/"+trip.ID.String(), token, nil), http.StatusOK)
+	if detail.Pickup == nil || detail.Pickup.Name != "A" || detail.Dropoff == nil || detail.Dropoff.Name != "C" {
+		t.Fatalf("detalle = %+v", detail)
+	}
+}
+
+func TestCreateTripErrors(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, token := s.login(models.RolePassenger)
+
+	route, stops := s.route(admin, "A", "B", "C")
+	other, otherStops := s.route(admin, "X", "Y")
+	departure := s.departure(route.ID, 10, 24*time.Hour)
+
+	tests := []struct {
+		name   string
+		body   map[string]any
+		status int
+		code   string
+	}{
+		{"sin datos", map[string]any{}, http.StatusBadRequest, handlers.ErrCodeValidation},
+		{"pago inválido", map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "tarjeta"},
+			http.StatusBadRequest, handlers.ErrCodeValidation},
+		{"ruta inexistente", map[string]any{"route_id": uuid.New(), "departure_id": departure.ID, "payment_method": "cash"},
+			http.StatusNotFound, handlers.ErrCodeRouteNotFound},
+		{"salida de otra ruta", map[string]any{"route_id": other.ID, "departure_id": departure.ID, "payment_method": "cash"},
+			http.StatusBadRequest, handlers.ErrCodeDepartureNotFound},
+		{"tramo invertido", map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "cash",
+			"pickup_stop_id": stops[2].ID, "dropoff_stop_id": stops[0].ID},
+			http.StatusBadRequest, handlers.ErrCodeStopOrderReversed},
+		{"parada de otra ruta", map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "cash",
+			"pickup_stop_id": otherStops[0].ID},
+			http.StatusBadRequest, handlers.ErrCodeStopNotOnRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertError(t, s.do(http.MethodPost, "/trips", token, tt.body), tt.status, tt.code)
+		})
+	}
+
+	assertError(t, s.do(http.MethodPost, "/trips", "", nil), http.StatusUnauthorized, handlers.ErrCodeUnauthorized)
+}
+
+func TestCreateTripConflicts(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, token := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+	body := func(departure models.Departure) map[string]any {
+		return map[string]any{"route_id": route.ID, "departure_id": departure.ID, "payment_method": "cash"}
+	}
+
+	t.Run("salida llena", func(t *testing.T) {
+		departure := s.departure(route.ID, 1, 2*time.Hour)
+		s.bookTrip(token, departure)
+		assertError(t, s.do(http.MethodPost, "/trips", token, body(departure)), http.StatusConflict, handlers.ErrCodeDepartureFull)
+	})
+
+	t.Run("salida cancelada", func(t *testing.T) {
+		departure := s.departure(route.ID, 10, 3*time.Hour)
+		if _, err := s.repos.Departures.Cancel(context.Background(), departure.ID); err != nil {
+			t.Fatal(err)
+		}
+		assertError(t, s.do(http.MethodPost, "/trips", token, body(departure)), http.StatusConflict, handlers.ErrCodeDepartureClosed)
+	})
+
+	t.Run("salida pasada", func(t *testing.T) {
+		departure := s.departure(route.ID, 10, -time.Hour)
+		assertError(t, s.do(http.MethodPost, "/trips", token, body(departure)), http.StatusConflict, handlers.ErrCodeDepartureClosed)
+	})
+
+	t.Run("ruta desactivada", func(t *testing.T) {
+		departure := s.departure(route.ID, 10, 4*time.Hour)
+		s.do(http.MethodPost, "/admin/routes/"+route.ID.String()+"/deactivate", admin, nil)
+		assertError(t, s.do(http.MethodPost, "/trips", token, body(departure)), http.StatusConflict, handlers.ErrCodeRouteInactive)
+
+		got, err := s.repos.Departures.Get(context.Background(), departure.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.SeatsBooked != 0 {
+			t.Fatalf("seats_booked = %d, la reserva rechazada no debe ocupar asiento", got.SeatsBooked)
+		}
+	})
+}
+
+func TestGetTripVisibility(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, owner := s.login(models.RolePassenger)
+	_, stranger := s.login(models.RolePassenger)
+	driver, driverToken := s.login(models.RoleDriver)
+	_, otherDriver := s.login(models.RoleDriver)
+
+	route, _ := s.route(admin, "A", "B")
+	if err := s.repos.Routes.AssignDriver(context.Background(), route.ID, driver.ID); err != nil {
+		t.Fatal(err)
+	}
+	trip := s.bookTrip(owner, s.departure(route.ID, 10, 24*time.Hour))
+	path := "/trips/" + trip.ID.String()
+
+	for _, token := range []string{owner, admin, driverToken} {
+		decode[models.TripDetail](t, s.do(http.MethodGet, path, token, nil), http.StatusOK)
+	}
+	// Los viajes ajenos se reportan como no encontrados
+	for _, token := range []string{stranger, otherDriver} {
+		assertError(t, s.do(http.MethodGet, path, token, nil), http.StatusNotFound, handlers.ErrCodeTripNotFound)
+	}
+	assertError(t, s.do(http.MethodGet, "/trips/"+uuid.NewString(), owner, nil), http.StatusNotFound, handlers.ErrCodeTripNotFound)
+	assertError(t, s.do(http.MethodGet, "/trips/123", owner, nil), http.StatusBadRequest, handlers.ErrCodeInvalidID)
+}
+
+func TestListMyTrips(t *testing.T) {
+	s := newTestServer(t)
+	_, admin := s.login(models.RoleAdmin)
+	_, token := s.login(models.RolePassenger)
+	_, other := s.login(models.RolePassenger)
+	route, _ := s.route(admin, "A", "B")
+	otherRoute, _ := s.route(admin, "X", "Y")
+
+	var booked []models.Trip
+	for i := 1; i <= 4; i++ {
+		booked = append(booked, s.bookTrip(token, s.departure(route.ID, 10, time.Duration(i)*time.Hour)))
+	}
+	far := s.bookTrip(token, s.departure(otherRoute.ID, 10, 10*24*time.Hour))
+	s.bookTrip(other, s.departure(route.ID, 10, 5*time.Hour))
+	decode[models.Trip](t, s.do(http.MethodPost, "/trips/"+booked[0].ID.String()+"/cancel", token, nil), http.StatusOK)
+
+	// Por defecto los más recientes primero, paginando con el cursor
+	var ids []uuid.UUID
+	path := "/me/trips?limit=2"
+	for pages := 0; path != ""; pages++ {
+		if pages > 3 {
+			t.Fatal("la paginación no termina")
+		}
+		rec := s.do(http.MethodGet, path, token, nil)
+		for _, trip := range decode[[]models.TripDetail](t, rec, http.StatusOK) {
+			ids = append(ids, trip.ID)
+		}
+		path = ""
+		if cursor := rec.Header().Get(handlers.NextCursorHeader); cursor != "" {
+			path = "/me/trips?limit=2&cursor=" + cursor
+		}
+	}
+	want := []uuid.UUID{far.ID, booked[3].ID, booked[2].ID, booked[1].ID, booked[0].ID}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("viajes = %v, se esperaba %v", ids, want)
+	}
+
+	filters := []struct {
+		query string
+		want  []uuid.UUID
+	}{
+		{"sort=scheduled_at&limit=2", []uuid.UUID{booked[0].ID, booked[1].ID}},
+		{"status=cancelled", []uuid.UUID{booked[0].ID}},
+		{"status=requested&route_id=" + otherRoute.ID.String(), []uuid.UUID{far.ID}},
+		{"from=" + far.ScheduledAt.In(models.LocalZone).Format(time.DateOnly) +
+			"&to=" + far.ScheduledAt.In(models.LocalZone).Format(time.DateOnly), []uuid.UUID{far.ID}},
+	}
+	for _, f := range filters {
+		var got []uuid.UUID
+		for _, trip := range decode[[]models.TripDetail](t, s.do(http.MethodGet, "/me/trips?"+f.query, token, nil), http.StatusOK) {
+			got = append(got, trip.ID)
+		}
+		if !slices.Equal(got, f.want) {
+			t.Errorf("%s: viajes = %v, se esperaba %v", f.query, got, f.want)
+		}
+	}
+}
+
+func TestListMyTripsValidation(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.login(models.RolePassenger)
+
+	rec := s.do(http.MethodGet, "/me/trips?status=volando&from=ayer&sort=precio&limit=0&cursor=zz", token, nil)
+	resp := decode[handlers.ErrorResponse](t, rec, http.StatusBadRequest)
+	if resp.Error.Code != handlers.ErrCodeValidation {
+		t.Fatalf("error.code = %q", resp.Error.Code)
+	}
+	details, _ := resp.Error.Details.([]any)
+	if len(details) != 5 {
+		t.Fatalf("details = %v, se esperaba un error por cada parámetro inválido", resp.Error.Details)
+	}
+
+	assertError(t, s.do(http.MethodGet, "/me/trips?from=2026-02-01&to=2026-01-01", token, nil),
+		http.StatusBadRequest, handlers.ErrCodeValidation)
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 
 	"github.com/joho/godotenv"
+	"github.com/luisdev-dark/realgov3.git/auth"
 	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/repository"
 	"github.com/luisdev-dark/realgov3.git/routes"
+	"github.com/luisdev-dark/realgov3.git/worker"
 )
 
 func main() {
@@ -16,14 +22,69 @@ func main() {
 		log.Println("No se encontró archivo .env, usando variables del sistema")
 	}
 
+	// Subcomandos (ej: go run . migrate up)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "gtfs" {
+		runGTFS(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "trips" {
+		runTrips(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "departures" {
+		runDepartures(os.Args[2:])
+		return
+	}
+
+	autoMigrate := flag.Bool("migrate", db.AutoMigrateFromEnv(),
+		"aplica las migraciones pendientes antes de iniciar (también AUTO_MIGRATE=true)")
+	flag.Parse()
+
 	// Conectar a Postgres
-	if err := db.InitDB(); err != nil {
+	var dbOpts []db.Option
+	if *autoMigrate {
+		dbOpts = append(dbOpts, db.WithMigrations())
+	}
+	if err := db.InitDB(dbOpts...); err != nil {
 		log.Fatalf("Error conectando a la base de datos: %v", err)
 	}
 	defer db.CloseDB()
 
-	// Configurar rutas
-	r := routes.SetupRouter()
+	// Proveedor de SMS para los códigos de acceso
+	sender, err := auth.SenderFromEnv()
+	if err != nil {
+		log.Fatalf("Error configurando SMS: %v", err)
+	}
+
+	// Clave de los pases de abordar
+	boarding, err := auth.BoardingSignerFromEnv()
+	if err != nil {
+		log.Fatalf("Error configurando pases de abordar: %v", err)
+	}
+
+	// Vencimiento periódico de viajes (TRIP_EXPIRY_INTERVAL=0 lo desactiva)
+	expiry, err := worker.ExpiryConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Error configurando vencimiento de viajes: %v", err)
+	}
+	repos := repository.NewPostgres(db.GetDB())
+	go worker.RunExpiry(context.Background(), repos.Trips, expiry)
+
+	// Generación periódica de salidas de los horarios
+	// (DEPARTURE_SCHEDULE_INTERVAL=0 la desactiva)
+	schedule, err := worker.ScheduleIntervalFromEnv()
+	if err != nil {
+		log.Fatalf("Error configurando generación de salidas: %v", err)
+	}
+	go worker.RunSchedule(context.Background(), repos, schedule)
+
+	// Configurar rutas con los repositorios de Postgres
+	h := handlers.New(repos, sender, boarding)
+	r := routes.SetupRouter(h)
 
 	// Iniciar servidor
 	port := os.Getenv("PORT")
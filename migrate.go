@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/luisdev-dark/realgov3.git/db"
+)
+
+const migrateUsage = `Uso: go run . migrate <comando>
+
+Comandos:
+  up         aplica todas las migraciones pendientes
+  down [n]   revierte las últimas n migraciones (por defecto 1)
+  status     lista las migraciones y si están aplicadas`
+
+// runMigrate ejecuta el subcomando migrate up|down|status
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("Error conectando a la base de datos: %v", err)
+	}
+	defer db.CloseDB()
+
+	ctx := context.Background()
+	pool := db.GetDB()
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(ctx, pool)
+		for _, m := range applied {
+			fmt.Printf("↑ %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error aplicando migraciones: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No hay migraciones pendientes")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Cantidad inválida: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := db.MigrateDown(ctx, pool, steps)
+		for _, m := range reverted {
+			fmt.Printf("↓ %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error revirtiendo migraciones: %v", err)
+		}
+
+	case "status":
+		status, err := db.GetMigrationStatus(ctx, pool)
+		if err != nil {
+			log.Fatalf("Error consultando migraciones: %v", err)
+		}
+		for _, s := range status {
+			state := "pendiente"
+			if s.AppliedAt != nil {
+				state = "aplicada " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// MaxCancellationFreeMinutes limita la ventana de cancelación gratuita (30 días)
+const MaxCancellationFreeMinutes = 30 * 24 * 60
+
+// CancellationPolicy es la regla de cancelación de una ruta: gratis hasta
+// FreeUntilMinutes antes de la salida y con un cargo de FeePercent del
+// precio después. Los viajes ya iniciados no se cancelan. La política por
+// defecto (0, 0) permite cancelar gratis siempre.
+type CancellationPolicy struct {
+	FreeUntilMinutes int `json:"free_until_minutes"`
+	FeePercent       int `json:"fee_percent"` // 0..100
+}
+
+// CancellationQuote es el cargo y el reembolso de cancelar un viaje en un
+// momento dado
+type CancellationQuote struct {
+	Free bool `json:"free"`
+	// FreeUntil es el límite para cancelar gratis; nil si el viaje no tiene
+	// hora de salida (siempre gratis)
+	FreeUntil   *time.Time `json:"free_until"`
+	FeePercent  int        `json:"fee_percent"`
+	FeeCents    int        `json:"fee_cents"`
+	RefundCents int        `json:"refund_cents"`
+	Fee         float64    `json:"fee"`
+	Refund      float64    `json:"refund"`
+	Currency    string     `json:"currency"`
+}
+
+// Quote calcula cuánto cuesta cancelar el viaje en now. Retorna
+// ErrInvalidTransition si el viaje ya no se puede cancelar. El cargo se
+// redondea al céntimo más cercano y nunca supera el precio.
+func (p CancellationPolicy) Quote(trip *Trip, now time.Time) (*CancellationQuote, error) {
+	if !CanTransition(trip.Status, TripStatusCancelled) {
+		return nil, ErrInvalidTransition
+	}
+
+	quote := &CancellationQuote{Free: true, Currency: trip.Currency}
+	if trip.ScheduledAt != nil {
+		freeUntil := trip.ScheduledAt.Add(-time.Duration(p.FreeUntilMinutes) * time.Minute)
+		quote.FreeUntil = &freeUntil
+		quote.Free = now.Before(freeUntil)
+	}
+	if !quote.Free {
+		quote.FeePercent = p.FeePercent
+		quote.FeeCents = min((trip.PriceCents*p.FeePercent+50)/100, trip.PriceCents)
+	}
+	quote.RefundCents = trip.PriceCents - quote.FeeCents
+	quote.Fee = float64(quote.FeeCents) / 100.0
+	quote.Refund = float64(quote.RefundCents) / 100.0
+	return quote, nil
+}
+
+// WaivedCancellation retorna una cotización sin cargo, para cancelaciones
+// que no decide el pasajero (conductor, administrador o salida cancelada)
+func WaivedCancellation(trip *Trip) *CancellationQuote {
+	return &CancellationQuote{
+		Free:        true,
+		RefundCents: trip.PriceCents,
+		Refund:      float64(trip.PriceCents) / 100.0,
+		Currency:    trip.Currency,
+	}
+}
+
+// Cancel cancela el viaje y guarda el cargo y el reembolso de quote
+func (t *Trip) Cancel(quote *CancellationQuote, now time.Time) error {
+	if err := t.Transition(TripStatusCancelled, now); err != nil {
+		return err
+	}
+	fee, refund := quote.FeeCents, quote.RefundCents
+	t.CancellationFeeCents = &fee
+	t.RefundCents = &refund
+	return nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor indica que el cursor de paginación no se pudo leer
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// Cursor marca la posición después del último elemento de una página.
+// Key es el valor de la columna de orden y ID desempata filas iguales.
+// Los clientes lo reciben como texto opaco.
+type Cursor struct {
+	Key string    `json:"k"`
+	ID  uuid.UUID `json:"id"`
+}
+
+// Encode retorna el cursor como texto seguro para URLs
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor lee un cursor generado por Encode
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
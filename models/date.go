@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Date es una fecha sin hora, como los vencimientos de documentos. En JSON
+// se escribe YYYY-MM-DD y en Postgres se guarda como date.
+type Date struct {
+	time.Time
+}
+
+// ParseDate lee una fecha YYYY-MM-DD
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t}, nil
+}
+
+// DateOf retorna el día de t en LocalZone
+func DateOf(t time.Time) Date {
+	t = t.In(LocalZone)
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func (d Date) String() string {
+	return d.Format(time.DateOnly)
+}
+
+// Before indica si d es un día anterior a other
+func (d Date) Before(other Date) bool {
+	return d.Time.Before(other.Time)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return fmt.Errorf("fecha inválida %q (formato YYYY-MM-DD)", s)
+	}
+	*d = parsed
+	return nil
+}
+
+// Scan implementa sql.Scanner para columnas date
+func (d *Date) Scan(src any) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("no se puede leer %T como fecha", src)
+	}
+	*d = Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+	return nil
+}
+
+// Value implementa driver.Valuer para columnas date
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
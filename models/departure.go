@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados posibles de una salida
+const (
+	DepartureStatusScheduled = "scheduled"
+	DepartureStatusCancelled = "cancelled"
+)
+
+// DepartureDuration es el tiempo que conductor y vehículo quedan ocupados por
+// una salida: no se les puede asignar otra salida que parta a menos de
+// DepartureDuration de distancia
+const DepartureDuration = 2 * time.Hour
+
+// Departure es una salida programada de una ruta con su capacidad de asientos.
+// SeatsBooked cuenta los viajes activos (no cancelados) de la salida.
+// Las salidas creadas a partir de un horario guardan su TimetableID.
+// DriverID y VehicleID son el conductor y el vehículo asignados, si los hay.
+type Departure struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	RouteID      uuid.UUID  `json:"route_id" db:"route_id"`
+	TimetableID  *uuid.UUID `json:"timetable_id" db:"timetable_id"`
+	DepartsAt    time.Time  `json:"departs_at" db:"departs_at"`
+	Capacity     int        `json:"capacity" db:"capacity"`
+	SeatsBooked  int        `json:"seats_booked" db:"seats_booked"`
+	VehicleLabel string     `json:"vehicle" db:"vehicle_label"`
+	DriverID     *uuid.UUID `json:"driver_id" db:"driver_id"`
+	VehicleID    *uuid.UUID `json:"vehicle_id" db:"vehicle_id"`
+	Status       string     `json:"status" db:"status"` // scheduled, cancelled
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SeatsAvailable retorna los asientos libres de la salida
+func (d *Departure) SeatsAvailable() int {
+	if d.Status != DepartureStatusScheduled || d.SeatsBooked >= d.Capacity {
+		return 0
+	}
+	return d.Capacity - d.SeatsBooked
+}
+
+// Bookable indica si la salida acepta reservas en el instante now
+func (d *Departure) Bookable(now time.Time) bool {
+	return d.Status == DepartureStatusScheduled && d.DepartsAt.After(now)
+}
+
+// Overlaps indica si la salida ocupa al conductor o vehículo en el mismo
+// rango que other
+func (d *Departure) Overlaps(other *Departure) bool {
+	gap := d.DepartsAt.Sub(other.DepartsAt)
+	return gap > -DepartureDuration && gap < DepartureDuration
+}
+
+// MarshalJSON agrega seats_available a la respuesta
+func (d Departure) MarshalJSON() ([]byte, error) {
+	type departure Departure
+	return json.Marshal(struct {
+		departure
+		SeatsAvailable int `json:"seats_available"`
+	}{departure(d), d.SeatsAvailable()})
+}
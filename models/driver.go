@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Driver es el perfil operativo de un usuario con rol driver. Name y Phone
+// vienen del usuario y no se editan aquí.
+type Driver struct {
+	UserID           uuid.UUID `json:"user_id" db:"user_id"`
+	Name             string    `json:"name" db:"name"`
+	Phone            string    `json:"phone" db:"phone"`
+	LicenseNumber    string    `json:"license_number" db:"license_number"`
+	LicenseExpiresOn Date      `json:"license_expires_on" db:"license_expires_on"`
+	IsActive         bool      `json:"is_active" db:"is_active"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// LicenseValidOn indica si la licencia sigue vigente el día day (vale hasta
+// su fecha de vencimiento inclusive)
+func (d *Driver) LicenseValidOn(day Date) bool {
+	return !d.LicenseExpiresOn.Before(day)
+}
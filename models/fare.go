@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/geo"
+)
+
+// Tipos de regla de tarifa
+const (
+	FareKindFlat     = "flat"     // base_cents por cualquier tramo
+	FareKindPerStop  = "per_stop" // base_cents + per_stop_cents por tramo entre paradas
+	FareKindDistance = "distance" // base_cents + per_km_cents por kilómetro recorrido
+	FareKindMatrix   = "matrix"   // precio fijo por par de paradas
+)
+
+// ErrNoFare indica que la regla no tiene precio para el tramo pedido
+var ErrNoFare = errors.New("no hay tarifa para este tramo")
+
+// FareRule es una versión de la tarifa de una ruta. Cada cambio crea una
+// versión nueva; los viajes guardan la versión con la que se cobraron.
+type FareRule struct {
+	ID           uuid.UUID         `json:"id" db:"id"`
+	RouteID      uuid.UUID         `json:"route_id" db:"route_id"`
+	Version      int               `json:"version" db:"version"`
+	Kind         string            `json:"kind" db:"kind"` // flat, per_stop, distance, matrix
+	BaseCents    int               `json:"base_cents" db:"base_cents"`
+	PerStopCents int               `json:"per_stop_cents" db:"per_stop_cents"`
+	PerKmCents   int               `json:"per_km_cents" db:"per_km_cents"`
+	Matrix       []FareMatrixEntry `json:"matrix" db:"matrix"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+}
+
+// FareMatrixEntry es el precio entre dos paradas de una tarifa matrix
+type FareMatrixEntry struct {
+	FromStopID uuid.UUID `json:"from_stop_id"`
+	ToStopID   uuid.UUID `json:"to_stop_id"`
+	PriceCents int       `json:"price_cents"`
+}
+
+// Validate verifica que la regla tenga los campos que su tipo necesita
+func (f *FareRule) Validate() error {
+	if f.BaseCents < 0 || f.PerStopCents < 0 || f.PerKmCents < 0 {
+		return fmt.Errorf("los montos no pueden ser negativos")
+	}
+
+	switch f.Kind {
+	case FareKindFlat, FareKindPerStop, FareKindDistance:
+		return nil
+	case FareKindMatrix:
+		if len(f.Matrix) == 0 {
+			return fmt.Errorf("matrix es requerido para kind matrix")
+		}
+		seen := map[[2]uuid.UUID]bool{}
+		for _, e := range f.Matrix {
+			pair := [2]uuid.UUID{e.FromStopID, e.ToStopID}
+			switch {
+			case e.FromStopID == uuid.Nil || e.ToStopID == uuid.Nil:
+				return fmt.Errorf("matrix: from_stop_id y to_stop_id son requeridos")
+			case e.FromStopID == e.ToStopID:
+				return fmt.Errorf("matrix: from_stop_id y to_stop_id deben ser distintos")
+			case e.PriceCents < 0:
+				return fmt.Errorf("matrix: price_cents no puede ser negativo")
+			case seen[pair]:
+				return fmt.Errorf("matrix: par de paradas repetido")
+			}
+			seen[pair] = true
+		}
+		return nil
+	}
+	return fmt.Errorf("kind inválido (flat, per_stop, distance, matrix)")
+}
+
+// Price calcula el precio del tramo. path son las paradas recorridas en
+// orden, desde la de recogida hasta la de bajada (al menos dos).
+// La tarifa flat no depende del tramo y acepta un path vacío.
+func (f *FareRule) Price(path []RouteStop) (int, error) {
+	if f.Kind == FareKindFlat {
+		return f.BaseCents, nil
+	}
+	if len(path) < 2 {
+		return 0, ErrNoFare
+	}
+
+	switch f.Kind {
+	case FareKindPerStop:
+		return f.BaseCents + f.PerStopCents*(len(path)-1), nil
+	case FareKindDistance:
+		meters := 0.0
+		for i := 1; i < len(path); i++ {
+			meters += geo.Distance(path[i-1].Latitude, path[i-1].Longitude, path[i].Latitude, path[i].Longitude)
+		}
+		return f.BaseCents + int(math.Round(meters/1000*float64(f.PerKmCents))), nil
+	case FareKindMatrix:
+		from, to := path[0].ID, path[len(path)-1].ID
+		for _, e := range f.Matrix {
+			if e.FromStopID == from && e.ToStopID == to {
+				return e.PriceCents, nil
+			}
+		}
+		return 0, ErrNoFare
+	}
+	return 0, ErrNoFare
+}
+
+// FareQuote es el precio de un tramo y la regla con la que se calculó
+type FareQuote struct {
+	RouteID         uuid.UUID  `json:"route_id"`
+	PickupStopID    *uuid.UUID `json:"pickup_stop_id"`
+	DropoffStopID   *uuid.UUID `json:"dropoff_stop_id"`
+	PriceCents      int        `json:"price_cents"`
+	Price           float64    `json:"price"`
+	Currency        string     `json:"currency"`
+	FareRuleID      *uuid.UUID `json:"fare_rule_id"`
+	FareRuleVersion *int       `json:"fare_rule_version"`
+	Kind            string     `json:"kind"` // flat, per_stop, distance, matrix o "base" sin regla
+}
@@ -0,0 +1,42 @@
+package models
+
+// Tipos mínimos de GeoJSON (RFC 7946). Las coordenadas van en orden
+// [longitud, latitud].
+
+// FeatureCollection es la raíz de una respuesta GeoJSON
+type FeatureCollection struct {
+	Type     string    `json:"type"` // siempre "FeatureCollection"
+	Features []Feature `json:"features"`
+}
+
+// Feature es una geometría con sus propiedades
+type Feature struct {
+	Type       string         `json:"type"` // siempre "Feature"
+	ID         string         `json:"id,omitempty"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry es un Point ([lon, lat]) o un LineString ([[lon, lat], ...])
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// NewFeatureCollection arma una colección con las features dadas
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// PointGeometry retorna un Point GeoJSON
+func PointGeometry(lat, lon float64) Geometry {
+	return Geometry{Type: "Point", Coordinates: [2]float64{lon, lat}}
+}
+
+// LineStringGeometry retorna un LineString GeoJSON; coords ya vienen como [lon, lat]
+func LineStringGeometry(coords [][2]float64) Geometry {
+	return Geometry{Type: "LineString", Coordinates: coords}
+}
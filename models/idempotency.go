@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey guarda el resultado de un request con Idempotency-Key para
+// devolverlo igual si el cliente reintenta. Mientras el request original
+// se procesa, CompletedAt es nil.
+type IdempotencyKey struct {
+	UserID       uuid.UUID  `db:"user_id"`
+	Key          string     `db:"key"`
+	RequestHash  string     `db:"request_hash"`
+	StatusCode   int        `db:"status_code"`
+	ContentType  string     `db:"content_type"`
+	ResponseBody []byte     `db:"response_body"`
+	CreatedAt    time.Time  `db:"created_at"`
+	CompletedAt  *time.Time `db:"completed_at"`
+}
+
+// Completed indica si ya se guardó la respuesta del request original
+func (k *IdempotencyKey) Completed() bool {
+	return k.CompletedAt != nil
+}
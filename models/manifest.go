@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Manifest es la lista de pasajeros de una salida para el conductor, con
+// los viajes agrupados por parada de recojo en orden de recorrido
+type Manifest struct {
+	DepartureID uuid.UUID `json:"departure_id"`
+	DepartsAt   time.Time `json:"departs_at"`
+	Status      string    `json:"status"`
+	Route       RouteInfo `json:"route"`
+	Vehicle     string    `json:"vehicle"` // placa del vehículo asignado o etiqueta de la salida
+	Capacity    int       `json:"capacity"`
+	Passengers  int       `json:"passengers"`
+	// CashToCollect suma lo que el conductor debe cobrar en efectivo
+	CashToCollect float64        `json:"cash_to_collect"`
+	Currency      string         `json:"currency"`
+	Stops         []ManifestStop `json:"stops"`
+}
+
+// ManifestStop es una parada de recojo con los pasajeros que suben en ella.
+// Los viajes sin parada de recojo van en un grupo final con Stop nil.
+type ManifestStop struct {
+	Stop          *StopInfo           `json:"stop"`
+	Order         int                 `json:"order"`
+	CashToCollect float64             `json:"cash_to_collect"`
+	Passengers    []ManifestPassenger `json:"passengers"`
+}
+
+// ManifestPassenger es un viaje del manifiesto
+type ManifestPassenger struct {
+	TripID        uuid.UUID `json:"trip_id"`
+	Name          string    `json:"name"`
+	Phone         string    `json:"phone"`
+	Status        string    `json:"status"`
+	Dropoff       *StopInfo `json:"dropoff"`
+	PaymentMethod string    `json:"payment_method"`
+	Price         float64   `json:"price"`
+	// CashToCollect es el precio si se paga en efectivo y 0 si no
+	CashToCollect float64 `json:"cash_to_collect"`
+}
+
+// DepartureTrip es un viaje de una salida con los datos del pasajero que
+// necesita el manifiesto
+type DepartureTrip struct {
+	Trip
+	PassengerName  string
+	PassengerPhone string
+}
+
+// NewManifest arma el manifiesto de la salida. stops son todas las paradas
+// de la ruta en orden (incluidas las inactivas, que pueden tener viajes
+// antiguos) y trips los viajes que ocupan asiento. Solo aparecen las
+// paradas con pasajeros.
+func NewManifest(departure *Departure, route *Route, vehicle string, stops []RouteStop, trips []DepartureTrip) *Manifest {
+	manifest := &Manifest{
+		DepartureID: departure.ID,
+		DepartsAt:   departure.DepartsAt,
+		Status:      departure.Status,
+		Route: RouteInfo{
+			ID:          route.ID,
+			Name:        route.Name,
+			Origin:      route.OriginName,
+			Destination: route.DestinationName,
+			BasePrice:   float64(route.BasePriceCents) / 100.0,
+		},
+		Vehicle:  vehicle,
+		Capacity: departure.Capacity,
+		Currency: route.Currency,
+		Stops:    []ManifestStop{},
+	}
+
+	names := map[uuid.UUID]string{}
+	for _, stop := range stops {
+		names[stop.ID] = stop.Name
+	}
+	stopInfo := func(id *uuid.UUID) *StopInfo {
+		if id == nil {
+			return nil
+		}
+		return &StopInfo{ID: *id, Name: names[*id]}
+	}
+
+	byStop := map[uuid.UUID][]DepartureTrip{}
+	var noStop []DepartureTrip
+	for _, trip := range trips {
+		if trip.PickupStopID == nil {
+			noStop = append(noStop, trip)
+			continue
+		}
+		byStop[*trip.PickupStopID] = append(byStop[*trip.PickupStopID], trip)
+	}
+
+	var totalCents int
+	group := func(stop *StopInfo, order int, trips []DepartureTrip) {
+		item := ManifestStop{Stop: stop, Order: order, Passengers: []ManifestPassenger{}}
+		var cashCents int
+		for _, trip := range trips {
+			passenger := ManifestPassenger{
+				TripID:        trip.ID,
+				Name:          trip.PassengerName,
+				Phone:         trip.PassengerPhone,
+				Status:        trip.Status,
+				Dropoff:       stopInfo(trip.DropoffStopID),
+				PaymentMethod: trip.PaymentMethod,
+				Price:         float64(trip.PriceCents) / 100.0,
+			}
+			if trip.PaymentMethod == PaymentMethodCash {
+				cashCents += trip.PriceCents
+				passenger.CashToCollect = passenger.Price
+			}
+			item.Passengers = append(item.Passengers, passenger)
+		}
+		item.CashToCollect = float64(cashCents) / 100.0
+		totalCents += cashCents
+		manifest.Passengers += len(trips)
+		manifest.Stops = append(manifest.Stops, item)
+	}
+	for _, stop := range stops {
+		if trips := byStop[stop.ID]; len(trips) > 0 {
+			group(&StopInfo{ID: stop.ID, Name: stop.Name}, stop.Order, trips)
+		}
+	}
+	if len(noStop) > 0 {
+		group(nil, 0, noStop)
+	}
+	manifest.CashToCollect = float64(totalCents) / 100.0
+	return manifest
+}
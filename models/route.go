@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,34 @@ type Route struct {
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Órdenes de GET /routes; el empate siempre se resuelve por id
+const (
+	RouteSortCreatedAt = "created_at"
+	RouteSortName      = "name"
+	RouteSortPrice     = "price"
+)
+
+// ValidRouteSort indica si sort es un orden de rutas conocido
+func ValidRouteSort(sort string) bool {
+	switch sort {
+	case RouteSortCreatedAt, RouteSortName, RouteSortPrice:
+		return true
+	}
+	return false
+}
+
+// SortKey retorna el valor de la ruta para el orden dado, usado como Cursor.Key
+func (r *Route) SortKey(sort string) string {
+	switch sort {
+	case RouteSortName:
+		return r.Name
+	case RouteSortPrice:
+		return strconv.Itoa(r.BasePriceCents)
+	default:
+		return r.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
 // RouteDetail es la respuesta completa de GET /routes/{id}
 type RouteDetail struct {
 	ID          uuid.UUID  `json:"id"`
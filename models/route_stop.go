@@ -10,8 +10,25 @@ type RouteStop struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	RouteID   uuid.UUID `json:"route_id" db:"route_id"`
 	Name      string    `json:"name" db:"name"`
-	Order     int       `json:"order" db:"order"`
+	Order     int       `json:"order" db:"stop_order"`
 	Latitude  float64   `json:"latitude" db:"latitude"`
 	Longitude float64   `json:"longitude" db:"longitude"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
+
+// ValidCoordinates indica si lat/lon están dentro de los rangos geográficos
+func ValidCoordinates(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// NearbyStop es una parada cercana a un punto, con su ruta y la distancia
+type NearbyStop struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Route     RouteInfo `json:"route"`
+	// DistanceMeters es la distancia en línea recta (haversine), en metros
+	DistanceMeters float64 `json:"distance_m"`
+}
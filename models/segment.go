@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Errores de un tramo pickup → dropoff inválido
+var (
+	ErrStopNotOnRoute    = errors.New("la parada no pertenece a la ruta")
+	ErrStopInactive      = errors.New("la parada no está activa")
+	ErrZeroLengthSegment = errors.New("la parada de recogida y la de bajada son la misma")
+	ErrStopOrderReversed = errors.New("la parada de bajada está antes que la de recogida")
+	ErrRouteWithoutStops = errors.New("la ruta no tiene paradas activas")
+)
+
+// Segment valida el tramo pickup → dropoff según stop_order y retorna las
+// paradas recorridas, sin las inactivas intermedias. stops son todas las
+// paradas de la ruta ordenadas por stop_order. Si falta alguna parada se usa
+// la primera o la última activa. Retorna nil sin error si no se pidió
+// ninguna parada y la ruta tiene menos de dos paradas activas.
+//
+// Los errores se revisan en este orden: parada de otra ruta, tramo de
+// longitud cero, sentido invertido y parada inactiva.
+func Segment(stops []RouteStop, pickupID, dropoffID *uuid.UUID) ([]RouteStop, error) {
+	var active []RouteStop
+	for _, s := range stops {
+		if s.IsActive {
+			active = append(active, s)
+		}
+	}
+	if pickupID == nil && dropoffID == nil && len(active) < 2 {
+		return nil, nil
+	}
+
+	find := func(id *uuid.UUID, which string, fallback func() *RouteStop) (*RouteStop, error) {
+		if id == nil {
+			if stop := fallback(); stop != nil {
+				return stop, nil
+			}
+			return nil, ErrRouteWithoutStops
+		}
+		for i := range stops {
+			if stops[i].ID == *id {
+				return &stops[i], nil
+			}
+		}
+		return nil, fmt.Errorf("%w: parada de %s", ErrStopNotOnRoute, which)
+	}
+	pickup, err := find(pickupID, "recogida", func() *RouteStop {
+		if len(active) == 0 {
+			return nil
+		}
+		return &active[0]
+	})
+	if err != nil {
+		return nil, err
+	}
+	dropoff, err := find(dropoffID, "bajada", func() *RouteStop {
+		if len(active) == 0 {
+			return nil
+		}
+		return &active[len(active)-1]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case pickup.Order == dropoff.Order:
+		return nil, ErrZeroLengthSegment
+	case dropoff.Order < pickup.Order:
+		return nil, ErrStopOrderReversed
+	case !pickup.IsActive:
+		return nil, fmt.Errorf("%w: parada de recogida", ErrStopInactive)
+	case !dropoff.IsActive:
+		return nil, fmt.Errorf("%w: parada de bajada", ErrStopInactive)
+	}
+
+	path := []RouteStop{}
+	for _, s := range stops {
+		if s.Order >= pickup.Order && s.Order <= dropoff.Order && s.IsActive {
+			path = append(path, s)
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthCode es un código de acceso de un solo uso enviado por SMS.
+// Solo se guarda el hash del código.
+type AuthCode struct {
+	Phone     string    `db:"phone"`
+	CodeHash  string    `db:"code_hash"`
+	Attempts  int       `db:"attempts"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Session es una sesión abierta con un token Bearer opaco.
+// Solo se guarda el hash del token.
+type Session struct {
+	TokenHash string    `db:"token_hash"`
+	UserID    uuid.UUID `db:"user_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
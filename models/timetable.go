@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocalZone es la zona horaria de operación. Las horas de los horarios y
+// el parámetro date de las salidas se interpretan en esta zona.
+var LocalZone = loadLocalZone()
+
+func loadLocalZone() *time.Location {
+	loc, err := time.LoadLocation("America/Lima")
+	if err != nil {
+		// Sin base de datos de zonas horarias: Lima no tiene horario de verano
+		return time.FixedZone("PET", -5*60*60)
+	}
+	return loc
+}
+
+// ScheduleDays es hasta cuántos días después de hoy se generan (y se pueden
+// consultar) las salidas de los horarios
+const ScheduleDays = 60
+
+// Timetable es una regla de salidas recurrentes de una ruta,
+// por ejemplo "lunes a viernes a las 06:00, 06:30 y 07:00"
+type Timetable struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	RouteID      uuid.UUID `json:"route_id" db:"route_id"`
+	Weekdays     []int     `json:"weekdays" db:"weekdays"` // ISO 8601: 1 = lunes ... 7 = domingo
+	Times        []string  `json:"times" db:"times"`       // "HH:MM" en LocalZone
+	Capacity     int       `json:"capacity" db:"capacity"`
+	VehicleLabel string    `json:"vehicle" db:"vehicle_label"`
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Normalize ordena y quita duplicados de días y horas, y valida sus valores
+func (t *Timetable) Normalize() error {
+	if len(t.Weekdays) == 0 {
+		return fmt.Errorf("weekdays es requerido")
+	}
+	if len(t.Times) == 0 {
+		return fmt.Errorf("times es requerido")
+	}
+
+	days := map[int]bool{}
+	for _, d := range t.Weekdays {
+		if d < 1 || d > 7 {
+			return fmt.Errorf("weekdays inválido: %d (1 = lunes ... 7 = domingo)", d)
+		}
+		days[d] = true
+	}
+	t.Weekdays = t.Weekdays[:0]
+	for d := range days {
+		t.Weekdays = append(t.Weekdays, d)
+	}
+	sort.Ints(t.Weekdays)
+
+	clocks := map[string]bool{}
+	for _, v := range t.Times {
+		clock, err := time.Parse("15:04", v)
+		if err != nil {
+			return fmt.Errorf("times inválido: %q (formato HH:MM)", v)
+		}
+		clocks[clock.Format("15:04")] = true
+	}
+	t.Times = t.Times[:0]
+	for v := range clocks {
+		t.Times = append(t.Times, v)
+	}
+	sort.Strings(t.Times)
+	return nil
+}
+
+// isoWeekday convierte time.Weekday (domingo = 0) a ISO 8601 (domingo = 7)
+func isoWeekday(d time.Weekday) int {
+	if d == time.Sunday {
+		return 7
+	}
+	return int(d)
+}
+
+// DeparturesOn retorna las salidas que la regla genera en la fecha dada
+// (solo se usan año, mes y día, en LocalZone)
+func (t *Timetable) DeparturesOn(date time.Time) []Departure {
+	if !t.IsActive {
+		return nil
+	}
+
+	runs := false
+	for _, d := range t.Weekdays {
+		if d == isoWeekday(date.Weekday()) {
+			runs = true
+		}
+	}
+	if !runs {
+		return nil
+	}
+
+	year, month, day := date.Date()
+	departures := make([]Departure, 0, len(t.Times))
+	for _, v := range t.Times {
+		clock, err := time.Parse("15:04", v)
+		if err != nil {
+			continue
+		}
+		timetableID := t.ID
+		departures = append(departures, Departure{
+			RouteID:      t.RouteID,
+			TimetableID:  &timetableID,
+			DepartsAt:    time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, LocalZone),
+			Capacity:     t.Capacity,
+			VehicleLabel: t.VehicleLabel,
+			Status:       DepartureStatusScheduled,
+		})
+	}
+	return departures
+}
+
+// ScheduledDepartures retorna las salidas que la regla genera desde la
+// fecha de now hasta ScheduleDays días después
+func (t *Timetable) ScheduledDepartures(now time.Time) []Departure {
+	today := now.In(LocalZone)
+	var departures []Departure
+	for i := 0; i <= ScheduleDays; i++ {
+		departures = append(departures, t.DeparturesOn(today.AddDate(0, 0, i))...)
+	}
+	return departures
+}
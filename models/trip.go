@@ -1,42 +1,230 @@
 package models
 
 import (
+	"errors"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Estados posibles de un viaje
+const (
+	TripStatusRequested = "requested"
+	TripStatusConfirmed = "confirmed"
+	TripStatusStarted   = "started"
+	TripStatusCompleted = "completed"
+	TripStatusCancelled = "cancelled"
+	TripStatusNoShow    = "no_show"
+	TripStatusExpired   = "expired"
+)
+
+// Motivos de los cambios de estado automáticos (Trip.StatusReason)
+const (
+	TripReasonNotConfirmed = "not_confirmed_before_departure"
+	TripReasonNotBoarded   = "not_boarded"
+)
+
+// TripStatusesWithoutSeat son los estados en los que el viaje ya no ocupa
+// asiento en su salida
+var TripStatusesWithoutSeat = []string{TripStatusCancelled, TripStatusNoShow, TripStatusExpired}
+
+// Medios de pago de un viaje
+const (
+	PaymentMethodCash  = "cash"
+	PaymentMethodYape  = "yape"
+	PaymentMethodPling = "pling"
+)
+
+// tripTransitions es la tabla central de transiciones permitidas.
+// Los estados completed, cancelled, no_show y expired son finales.
+var tripTransitions = map[string][]string{
+	TripStatusRequested: {TripStatusConfirmed, TripStatusCancelled, TripStatusExpired},
+	TripStatusConfirmed: {TripStatusStarted, TripStatusCancelled, TripStatusNoShow},
+	TripStatusStarted:   {TripStatusCompleted},
+}
+
+// CanTransition indica si un viaje puede pasar del estado from al estado to
+func CanTransition(from, to string) bool {
+	for _, next := range tripTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusesAllowing retorna, ordenados, los estados desde los que se puede
+// pasar al estado to
+func StatusesAllowing(to string) []string {
+	var from []string
+	for status := range tripTransitions {
+		if CanTransition(status, to) {
+			from = append(from, status)
+		}
+	}
+	sort.Strings(from)
+	return from
+}
+
+// ErrInvalidTransition indica un cambio de estado no permitido por tripTransitions
+var ErrInvalidTransition = errors.New("transición de estado no permitida")
+
+// Transition mueve el viaje al estado to y registra el timestamp que
+// corresponde (started_at, finished_at o cancelled_at)
+func (t *Trip) Transition(to string, now time.Time) error {
+	if !CanTransition(t.Status, to) {
+		return ErrInvalidTransition
+	}
+
+	switch to {
+	case TripStatusStarted:
+		t.StartedAt = &now
+	case TripStatusCompleted:
+		t.FinishedAt = &now
+	case TripStatusCancelled:
+		t.CancelledAt = &now
+	}
+	t.Status = to
+	t.UpdatedAt = now
+	return nil
+}
+
+// Errores de Trip.Board
+var (
+	ErrTripNotConfirmed   = errors.New("el viaje no está confirmado")
+	ErrTripAlreadyBoarded = errors.New("el pasajero ya abordó este viaje")
+)
+
+// Board registra que el pasajero subió al vehículo: el viaje confirmado
+// pasa a started. Retorna ErrTripAlreadyBoarded si ya inició o terminó y
+// ErrTripNotConfirmed si está en otro estado.
+func (t *Trip) Board(now time.Time) error {
+	switch t.Status {
+	case TripStatusConfirmed:
+		return t.Transition(TripStatusStarted, now)
+	case TripStatusStarted, TripStatusCompleted:
+		return ErrTripAlreadyBoarded
+	}
+	return ErrTripNotConfirmed
+}
+
+// ValidTripStatus indica si status es un estado de viaje conocido
+func ValidTripStatus(status string) bool {
+	switch status {
+	case TripStatusRequested, TripStatusConfirmed, TripStatusStarted, TripStatusCompleted, TripStatusCancelled,
+		TripStatusNoShow, TripStatusExpired:
+		return true
+	}
+	return false
+}
+
+// SortTime es la fecha por la que se ordenan los viajes: la hora de salida
+// o, si no tiene, la fecha de creación
+func (t *Trip) SortTime() time.Time {
+	if t.ScheduledAt != nil {
+		return *t.ScheduledAt
+	}
+	return t.CreatedAt
+}
+
+// HoldsSeat indica si el viaje ocupa un asiento de su salida
+func (t *Trip) HoldsSeat() bool {
+	return t.DepartureID != nil && !slices.Contains(TripStatusesWithoutSeat, t.Status)
+}
+
 type Trip struct {
 	ID            uuid.UUID  `json:"id" db:"id"`
 	RouteID       uuid.UUID  `json:"route_id" db:"route_id"`
+	DepartureID   *uuid.UUID `json:"departure_id" db:"departure_id"`
 	PassengerID   uuid.UUID  `json:"passenger_id" db:"passenger_id"`
 	PickupStopID  *uuid.UUID `json:"pickup_stop_id" db:"pickup_stop_id"`
 	DropoffStopID *uuid.UUID `json:"dropoff_stop_id" db:"dropoff_stop_id"`
-	Status        string     `json:"status" db:"status"` // requested, confirmed, completed, cancelled
+	Status        string     `json:"status" db:"status"`                 // requested, confirmed, started, completed, cancelled, no_show, expired
+	StatusReason  *string    `json:"status_reason" db:"status_reason"`   // models.TripReason*; nil si el cambio fue manual
 	PaymentMethod string     `json:"payment_method" db:"payment_method"` // cash, yape, pling
 	PriceCents    int        `json:"price_cents" db:"price_cents"`
 	Currency      string     `json:"currency" db:"currency"`
-	ScheduledAt   *time.Time `json:"scheduled_at" db:"scheduled_at"`
-	StartedAt     *time.Time `json:"started_at" db:"started_at"`
-	FinishedAt    *time.Time `json:"finished_at" db:"finished_at"`
-	CancelledAt   *time.Time `json:"cancelled_at" db:"cancelled_at"`
-	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	// Regla de tarifa con la que se calculó PriceCents (nil: base de la ruta)
+	FareRuleID      *uuid.UUID `json:"fare_rule_id" db:"fare_rule_id"`
+	FareRuleVersion *int       `json:"fare_rule_version" db:"fare_rule_version"`
+	ScheduledAt     *time.Time `json:"scheduled_at" db:"scheduled_at"`
+	StartedAt       *time.Time `json:"started_at" db:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at" db:"finished_at"`
+	CancelledAt     *time.Time `json:"cancelled_at" db:"cancelled_at"`
+	// Cargo y reembolso calculados al cancelar (nil si no está cancelado)
+	CancellationFeeCents *int      `json:"cancellation_fee_cents" db:"cancellation_fee_cents"`
+	RefundCents          *int      `json:"refund_cents" db:"refund_cents"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// TripDetail es la respuesta completa de GET /trips/{id}
+// TripDetail es la respuesta completa de GET /trips/{id} y GET /me/trips
 type TripDetail struct {
 	ID            uuid.UUID  `json:"id"`
 	PassengerID   uuid.UUID  `json:"passenger_id"`
+	DepartureID   *uuid.UUID `json:"departure_id"`
 	Route         RouteInfo  `json:"route"`
 	Pickup        *StopInfo  `json:"pickup"`
 	Dropoff       *StopInfo  `json:"dropoff"`
 	Status        string     `json:"status"`
+	StatusReason  *string    `json:"status_reason"`
 	PaymentMethod string     `json:"payment_method"`
 	Price         float64    `json:"price"`
 	Currency      string     `json:"currency"`
-	ScheduledAt   *time.Time `json:"scheduled_at"`
-	CreatedAt     time.Time  `json:"created_at"`
+	// CancellationFee y Refund solo están en viajes cancelados
+	CancellationFee *float64   `json:"cancellation_fee"`
+	Refund          *float64   `json:"refund"`
+	FareRuleVersion *int       `json:"fare_rule_version"`
+	ScheduledAt     *time.Time `json:"scheduled_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// NewTripDetail arma el TripDetail de un viaje con su ruta y paradas.
+// pickup y dropoff pueden ser nil.
+func NewTripDetail(trip *Trip, route *Route, pickup, dropoff *StopInfo) TripDetail {
+	return TripDetail{
+		ID:          trip.ID,
+		PassengerID: trip.PassengerID,
+		DepartureID: trip.DepartureID,
+		Route: RouteInfo{
+			ID:          route.ID,
+			Name:        route.Name,
+			Origin:      route.OriginName,
+			Destination: route.DestinationName,
+			BasePrice:   float64(route.BasePriceCents) / 100.0,
+		},
+		Pickup:          pickup,
+		Dropoff:         dropoff,
+		Status:          trip.Status,
+		StatusReason:    trip.StatusReason,
+		PaymentMethod:   trip.PaymentMethod,
+		Price:           float64(trip.PriceCents) / 100.0,
+		Currency:        trip.Currency,
+		CancellationFee: centsToAmount(trip.CancellationFeeCents),
+		Refund:          centsToAmount(trip.RefundCents),
+		FareRuleVersion: trip.FareRuleVersion,
+		ScheduledAt:     trip.ScheduledAt,
+		CreatedAt:       trip.CreatedAt,
+	}
+}
+
+// centsToAmount convierte céntimos opcionales a monto
+func centsToAmount(cents *int) *float64 {
+	if cents == nil {
+		return nil
+	}
+	amount := float64(*cents) / 100.0
+	return &amount
+}
+
+// SortTime es la fecha por la que se ordenan los viajes (ver Trip.SortTime)
+func (d *TripDetail) SortTime() time.Time {
+	if d.ScheduledAt != nil {
+		return *d.ScheduledAt
+	}
+	return d.CreatedAt
 }
 
 type RouteInfo struct {
@@ -6,11 +6,34 @@ import (
 	"github.com/google/uuid"
 )
 
+// Roles de usuario
+const (
+	RolePassenger = "passenger"
+	RoleDriver    = "driver"
+	RoleAdmin     = "admin"
+)
+
+// ValidRole indica si role es uno de los roles conocidos
+func ValidRole(role string) bool {
+	return role == RolePassenger || role == RoleDriver || role == RoleAdmin
+}
+
 type User struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
 	Email     string    `json:"email" db:"email"`
 	Phone     string    `json:"phone" db:"phone"`
+	Role      string    `json:"role" db:"role"` // passenger, driver, admin
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// HasRole indica si el usuario tiene alguno de los roles dados
+func (u *User) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
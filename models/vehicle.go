@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Vehicle es un vehículo de la flota. Para asignarlo a una salida debe estar
+// activo y con el SOAT y la revisión técnica vigentes el día de la salida.
+type Vehicle struct {
+	ID                  uuid.UUID `json:"id" db:"id"`
+	Plate               string    `json:"plate" db:"plate"` // placa en mayúsculas, sin espacios ni guiones (ej: ABC123)
+	Model               string    `json:"model" db:"model"`
+	Capacity            int       `json:"capacity" db:"capacity"` // asientos para pasajeros
+	SOATExpiresOn       Date      `json:"soat_expires_on" db:"soat_expires_on"`
+	InspectionExpiresOn Date      `json:"inspection_expires_on" db:"inspection_expires_on"`
+	IsActive            bool      `json:"is_active" db:"is_active"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ExpiredDocuments retorna los documentos vencidos el día day
+// ("soat", "inspection"); los documentos valen hasta su fecha de vencimiento
+// inclusive
+func (v *Vehicle) ExpiredDocuments(day Date) []string {
+	var expired []string
+	if v.SOATExpiresOn.Before(day) {
+		expired = append(expired, "soat")
+	}
+	if v.InspectionExpiresOn.Before(day) {
+		expired = append(expired, "inspection")
+	}
+	return expired
+}
@@ -0,0 +1,340 @@
+// Package planner arma viajes de un punto de origen a uno de destino usando
+// las rutas y paradas existentes: caminata hasta una parada, uno o dos tramos
+// en ruta (con a lo sumo un transbordo) y caminata final.
+//
+// Las opciones se comparan por un costo en minutos que suma caminata, tiempo
+// en ruta, una penalidad por transbordo y el precio convertido a minutos.
+//
+// El precio solo ordena las opciones completas. Dentro de cada ruta (o par
+// de rutas) las paradas de subida y bajada se eligen solo por tiempo, y a
+// igual tiempo gana la que viene antes en la ruta: cada tramo se cotiza una
+// vez, ya elegido, porque cotizar consulta las tarifas vigentes. Un par de
+// paradas un poco más lento pero más barato en la misma ruta no se ofrece.
+package planner
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/luisdev-dark/realgov3.git/geo"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+const (
+	// walkMetersPerMinute es la velocidad de caminata (~4.8 km/h)
+	walkMetersPerMinute = 80.0
+	// rideMetersPerMinute es la velocidad media en ruta (~20 km/h en Lima)
+	rideMetersPerMinute = 333.0
+	// transferPenaltyMinutes es el costo de bajarse y esperar otra ruta
+	transferPenaltyMinutes = 10.0
+	// minutesPerCent convierte precio en minutos: S/ 1.00 equivale a 3 minutos
+	minutesPerCent = 0.03
+)
+
+// Point es una coordenada WGS84
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Route es una ruta activa con todas sus paradas ordenadas por stop_order.
+// Las paradas inactivas se mantienen para calcular el tramo igual que al
+// reservar, pero no se usan para subir, bajar ni transbordar.
+type Route struct {
+	Route models.Route
+	Stops []models.RouteStop
+}
+
+// FareFunc cotiza el tramo path de la ruta. Si retorna models.ErrNoFare la
+// opción se descarta; cualquier otro error corta la búsqueda.
+type FareFunc func(route *models.Route, path []models.RouteStop) (*models.FareQuote, error)
+
+// Options limita la búsqueda
+type Options struct {
+	// MaxWalkMeters es la caminata máxima hasta la primera parada y desde la última
+	MaxWalkMeters float64
+	// TransferWalkMeters es la caminata máxima entre las paradas de un transbordo
+	TransferWalkMeters float64
+	// MaxTransfers es 0 (solo rutas directas) o 1
+	MaxTransfers int
+	// MaxResults es la cantidad de opciones a retornar
+	MaxResults int
+}
+
+// Leg es un tramo en una ruta
+type Leg struct {
+	Route      models.RouteInfo `json:"route"`
+	Pickup     models.StopInfo  `json:"pickup"`
+	Dropoff    models.StopInfo  `json:"dropoff"`
+	Stops      int              `json:"stops"` // paradas recorridas
+	RideMeters float64          `json:"ride_m"`
+	Fare       models.FareQuote `json:"fare"`
+}
+
+// Plan es una opción completa de origen a destino
+type Plan struct {
+	WalkToPickupMeters    float64 `json:"walk_to_pickup_m"`
+	Legs                  []Leg   `json:"legs"`
+	TransferWalkMeters    float64 `json:"transfer_walk_m"`
+	WalkFromDropoffMeters float64 `json:"walk_from_dropoff_m"`
+	TotalWalkMeters       float64 `json:"total_walk_m"`
+	PriceCents            int     `json:"price_cents"`
+	Price                 float64 `json:"price"`
+	Currency              string  `json:"currency"`
+	// Minutes es el tiempo estimado de puerta a puerta
+	Minutes float64 `json:"minutes"`
+
+	cost float64
+}
+
+// segment es un tramo candidato dentro de una ruta: subir en from y bajar en to
+// (índices en Route.Stops)
+type segment struct {
+	route    *Route
+	from, to int
+	minutes  float64 // caminata previa + tiempo en ruta (o ruta + caminata final)
+	walk     float64
+}
+
+// Find retorna las mejores opciones de from a to, de menor a mayor costo.
+// Cada ruta aporta a lo sumo una opción directa y cada par de rutas una con
+// transbordo (ver el comentario del paquete). Si ninguna ruta sirve retorna
+// un slice vacío.
+func Find(from, to Point, routes []Route, fare FareFunc, opts Options) ([]Plan, error) {
+	plans := []Plan{}
+
+	// Mejor forma de llegar a cada parada desde el origen (subiendo cerca
+	// del origen) y de llegar al destino desde cada parada (bajando cerca
+	// del destino), por ruta
+	boards := make([][]*segment, len(routes))
+	alights := make([][]*segment, len(routes))
+	for i := range routes {
+		boards[i] = bestBoardings(&routes[i], from, opts.MaxWalkMeters)
+		alights[i] = bestAlightings(&routes[i], to, opts.MaxWalkMeters)
+	}
+
+	// Rutas directas: subir cerca del origen y bajar cerca del destino
+	for i := range routes {
+		route := &routes[i]
+		var best *segment
+		var bestCost float64
+		for j, board := range boards[i] {
+			if board == nil {
+				continue
+			}
+			walk := geo.Distance(route.Stops[j].Latitude, route.Stops[j].Longitude, to.Lat, to.Lon)
+			if walk > opts.MaxWalkMeters {
+				continue
+			}
+			// Solo tiempo: el precio se cotiza después, para la parada elegida
+			cost := board.minutes + walk/walkMetersPerMinute
+			if best == nil || cost < bestCost {
+				best = &segment{route: route, from: board.from, to: j, minutes: cost, walk: board.walk + walk}
+				bestCost = cost
+			}
+		}
+		if best == nil {
+			continue
+		}
+		leg, err := buildLeg(best.route, best.from, best.to, fare)
+		if errors.Is(err, models.ErrNoFare) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, newPlan(from, to, []Leg{*leg}, []*segment{best}, 0))
+	}
+
+	// Un transbordo: bajar de la ruta A en una parada cercana a una de la ruta B
+	if opts.MaxTransfers > 0 {
+		for a := range routes {
+			for b := range routes {
+				if a == b || routes[a].Route.Currency != routes[b].Route.Currency {
+					continue
+				}
+				plan, ok, err := bestTransfer(&routes[a], &routes[b], boards[a], alights[b], from, to, fare, opts)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					plans = append(plans, plan)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(plans, func(i, j int) bool {
+		return plans[i].cost < plans[j].cost
+	})
+	if opts.MaxResults > 0 && len(plans) > opts.MaxResults {
+		plans = plans[:opts.MaxResults]
+	}
+	return plans, nil
+}
+
+// bestBoardings retorna, para cada parada j de la ruta, la mejor forma de
+// llegar a ella en la ruta subiendo en una parada i < j cercana al origen:
+// minutes = caminata hasta i + ruta i → j. nil si no se puede.
+func bestBoardings(route *Route, from Point, maxWalk float64) []*segment {
+	best := make([]*segment, len(route.Stops))
+	var current *segment // mejor subida hasta la parada anterior
+	for j, stop := range route.Stops {
+		if current != nil {
+			prev := route.Stops[j-1]
+			current = &segment{
+				route:   route,
+				from:    current.from,
+				to:      j,
+				minutes: current.minutes + geo.Distance(prev.Latitude, prev.Longitude, stop.Latitude, stop.Longitude)/rideMetersPerMinute,
+				walk:    current.walk,
+			}
+			if stop.IsActive {
+				best[j] = current
+			}
+		}
+		if !stop.IsActive {
+			continue
+		}
+		// Subir en j puede ser mejor que venir de antes
+		walk := geo.Distance(from.Lat, from.Lon, stop.Latitude, stop.Longitude)
+		if walk <= maxWalk && (current == nil || walk/walkMetersPerMinute < current.minutes) {
+			current = &segment{route: route, from: j, to: j, minutes: walk / walkMetersPerMinute, walk: walk}
+		}
+	}
+	return best
+}
+
+// bestAlightings es el simétrico de bestBoardings: para cada parada k, la
+// mejor forma de llegar al destino subiendo en k y bajando en una parada
+// l > k cercana al destino
+func bestAlightings(route *Route, to Point, maxWalk float64) []*segment {
+	best := make([]*segment, len(route.Stops))
+	var current *segment // mejor bajada desde la parada siguiente
+	for k := len(route.Stops) - 1; k >= 0; k-- {
+		stop := route.Stops[k]
+		if current != nil {
+			next := route.Stops[k+1]
+			current = &segment{
+				route:   route,
+				from:    k,
+				to:      current.to,
+				minutes: current.minutes + geo.Distance(stop.Latitude, stop.Longitude, next.Latitude, next.Longitude)/rideMetersPerMinute,
+				walk:    current.walk,
+			}
+			if stop.IsActive {
+				best[k] = current
+			}
+		}
+		if !stop.IsActive {
+			continue
+		}
+		walk := geo.Distance(stop.Latitude, stop.Longitude, to.Lat, to.Lon)
+		if walk <= maxWalk && (current == nil || walk/walkMetersPerMinute < current.minutes) {
+			current = &segment{route: route, from: k, to: k, minutes: walk / walkMetersPerMinute, walk: walk}
+		}
+	}
+	return best
+}
+
+// bestTransfer busca el transbordo más rápido de la ruta a a la ruta b; el
+// precio de sus dos tramos se cotiza al final y no cambia la elección
+func bestTransfer(a, b *Route, boards, alights []*segment, from, to Point, fare FareFunc, opts Options) (Plan, bool, error) {
+	var first, second *segment
+	var bestCost, bestWalk float64
+	for j, board := range boards {
+		if board == nil {
+			continue
+		}
+		for k, alight := range alights {
+			if alight == nil {
+				continue
+			}
+			walk := geo.Distance(a.Stops[j].Latitude, a.Stops[j].Longitude, b.Stops[k].Latitude, b.Stops[k].Longitude)
+			if walk > opts.TransferWalkMeters {
+				continue
+			}
+			cost := board.minutes + walk/walkMetersPerMinute + transferPenaltyMinutes + alight.minutes
+			if first == nil || cost < bestCost {
+				first, second, bestCost, bestWalk = board, alight, cost, walk
+			}
+		}
+	}
+	if first == nil {
+		return Plan{}, false, nil
+	}
+
+	legA, err := buildLeg(a, first.from, first.to, fare)
+	if errors.Is(err, models.ErrNoFare) {
+		return Plan{}, false, nil
+	}
+	if err != nil {
+		return Plan{}, false, err
+	}
+	legB, err := buildLeg(b, second.from, second.to, fare)
+	if errors.Is(err, models.ErrNoFare) {
+		return Plan{}, false, nil
+	}
+	if err != nil {
+		return Plan{}, false, err
+	}
+	return newPlan(from, to, []Leg{*legA, *legB}, []*segment{first, second}, bestWalk), true, nil
+}
+
+// buildLeg arma el tramo de la parada i a la j de la ruta y lo cotiza
+func buildLeg(route *Route, i, j int, fare FareFunc) (*Leg, error) {
+	path := route.Stops[i : j+1]
+	quote, err := fare(&route.Route, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var meters float64
+	for n := 1; n < len(path); n++ {
+		meters += geo.Distance(path[n-1].Latitude, path[n-1].Longitude, path[n].Latitude, path[n].Longitude)
+	}
+	pickup, dropoff := path[0], path[len(path)-1]
+	return &Leg{
+		Route: models.RouteInfo{
+			ID:          route.Route.ID,
+			Name:        route.Route.Name,
+			Origin:      route.Route.OriginName,
+			Destination: route.Route.DestinationName,
+			BasePrice:   float64(route.Route.BasePriceCents) / 100.0,
+		},
+		Pickup:     models.StopInfo{ID: pickup.ID, Name: pickup.Name},
+		Dropoff:    models.StopInfo{ID: dropoff.ID, Name: dropoff.Name},
+		Stops:      len(path) - 1,
+		RideMeters: meters,
+		Fare:       *quote,
+	}, nil
+}
+
+// newPlan calcula caminatas, precio total y costo de una opción
+func newPlan(from, to Point, legs []Leg, segments []*segment, transferWalk float64) Plan {
+	first := segments[0].route.Stops[segments[0].from]
+	lastSeg := segments[len(segments)-1]
+	last := lastSeg.route.Stops[lastSeg.to]
+
+	plan := Plan{
+		WalkToPickupMeters:    geo.Distance(from.Lat, from.Lon, first.Latitude, first.Longitude),
+		Legs:                  legs,
+		TransferWalkMeters:    transferWalk,
+		WalkFromDropoffMeters: geo.Distance(last.Latitude, last.Longitude, to.Lat, to.Lon),
+		Currency:              legs[0].Fare.Currency,
+	}
+	plan.TotalWalkMeters = plan.WalkToPickupMeters + plan.TransferWalkMeters + plan.WalkFromDropoffMeters
+
+	var rideMeters float64
+	for _, leg := range legs {
+		plan.PriceCents += leg.Fare.PriceCents
+		rideMeters += leg.RideMeters
+	}
+	plan.Price = float64(plan.PriceCents) / 100.0
+	plan.Minutes = plan.TotalWalkMeters/walkMetersPerMinute + rideMeters/rideMetersPerMinute
+	if len(legs) > 1 {
+		plan.Minutes += transferPenaltyMinutes
+	}
+	plan.cost = plan.Minutes + float64(plan.PriceCents)*minutesPerCent
+	return plan
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+// memoryStore guarda todas las entidades en mapas protegidos por un mutex.
+// Los repositorios en memoria siempre copian los valores al entrar y al
+// salir, para que los llamadores no compartan estado con el store.
+type memoryStore struct {
+	mu sync.RWMutex
+
+	routes       map[uuid.UUID]models.Route
+	paths        map[uuid.UUID]string                    // polilínea por ruta
+	policies     map[uuid.UUID]models.CancellationPolicy // política de cancelación por ruta
+	stops        map[uuid.UUID]models.RouteStop
+	departures   map[uuid.UUID]models.Departure
+	timetables   map[uuid.UUID]models.Timetable
+	fares        map[uuid.UUID]models.FareRule
+	trips        map[uuid.UUID]models.Trip
+	users        map[uuid.UUID]models.User
+	routeDrivers map[routeDriver]bool
+	codes        map[string]models.AuthCode
+	sessions     map[string]models.Session
+	idempotency  map[idempotencyKey]models.IdempotencyKey
+	drivers      map[uuid.UUID]models.Driver
+	vehicles     map[uuid.UUID]models.Vehicle
+}
+
+type idempotencyKey struct {
+	userID uuid.UUID
+	key    string
+}
+
+type routeDriver struct {
+	routeID  uuid.UUID
+	driverID uuid.UUID
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		routes:       map[uuid.UUID]models.Route{},
+		paths:        map[uuid.UUID]string{},
+		policies:     map[uuid.UUID]models.CancellationPolicy{},
+		stops:        map[uuid.UUID]models.RouteStop{},
+		departures:   map[uuid.UUID]models.Departure{},
+		timetables:   map[uuid.UUID]models.Timetable{},
+		fares:        map[uuid.UUID]models.FareRule{},
+		trips:        map[uuid.UUID]models.Trip{},
+		users:        map[uuid.UUID]models.User{},
+		routeDrivers: map[routeDriver]bool{},
+		codes:        map[string]models.AuthCode{},
+		sessions:     map[string]models.Session{},
+		idempotency:  map[idempotencyKey]models.IdempotencyKey{},
+		drivers:      map[uuid.UUID]models.Driver{},
+		vehicles:     map[uuid.UUID]models.Vehicle{},
+	}
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryDepartures struct {
+	*memoryStore
+}
+
+func (m *memoryDepartures) ListByRoute(ctx context.Context, routeID uuid.UUID, from, to time.Time) ([]models.Departure, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	departures := []models.Departure{}
+	for _, d := range m.departures {
+		if d.RouteID == routeID && !d.DepartsAt.Before(from) && d.DepartsAt.Before(to) {
+			departures = append(departures, d)
+		}
+	}
+	sort.Slice(departures, func(i, j int) bool {
+		return departures[i].DepartsAt.Before(departures[j].DepartsAt)
+	})
+	return departures, nil
+}
+
+func (m *memoryDepartures) Get(ctx context.Context, id uuid.UUID) (*models.Departure, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	departure, ok := m.departures[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &departure, nil
+}
+
+func (m *memoryDepartures) Create(ctx context.Context, departure *models.Departure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if departure.ID == uuid.Nil {
+		departure.ID = uuid.New()
+	}
+	if _, exists := m.departures[departure.ID]; exists {
+		return ErrConflict
+	}
+	if _, ok := m.routes[departure.RouteID]; !ok {
+		return ErrConflict
+	}
+	for _, d := range m.departures {
+		if d.RouteID == departure.RouteID && d.DepartsAt.Equal(departure.DepartsAt) {
+			return ErrConflict
+		}
+	}
+
+	now := time.Now()
+	if departure.Status == "" {
+		departure.Status = models.DepartureStatusScheduled
+	}
+	departure.SeatsBooked = 0
+	departure.CreatedAt = now
+	departure.UpdatedAt = now
+	m.departures[departure.ID] = *departure
+	return nil
+}
+
+func (m *memoryDepartures) Materialize(ctx context.Context, departures []models.Departure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := map[routeDeparture]bool{}
+	for _, d := range m.departures {
+		existing[routeDeparture{d.RouteID, d.DepartsAt.UnixNano()}] = true
+	}
+
+	now := time.Now()
+	for _, d := range departures {
+		key := routeDeparture{d.RouteID, d.DepartsAt.UnixNano()}
+		if existing[key] {
+			continue
+		}
+		if _, ok := m.routes[d.RouteID]; !ok {
+			return ErrConflict
+		}
+		existing[key] = true
+
+		d.ID = uuid.New()
+		d.SeatsBooked = 0
+		d.Status = models.DepartureStatusScheduled
+		d.CreatedAt = now
+		d.UpdatedAt = now
+		m.departures[d.ID] = d
+	}
+	return nil
+}
+
+// routeDeparture identifica una salida por ruta y hora
+type routeDeparture struct {
+	routeID   uuid.UUID
+	departsAt int64
+}
+
+func (m *memoryDepartures) SetCapacity(ctx context.Context, id uuid.UUID, capacity int) (*models.Departure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	departure, ok := m.departures[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if departure.SeatsBooked > capacity {
+		return nil, ErrConflict
+	}
+	departure.Capacity = capacity
+	departure.UpdatedAt = time.Now()
+	m.departures[id] = departure
+	return &departure, nil
+}
+
+func (m *memoryDepartures) Cancel(ctx context.Context, id uuid.UUID) (*models.Departure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	departure, ok := m.departures[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	now := time.Now()
+	for tripID, trip := range m.trips {
+		if trip.DepartureID == nil || *trip.DepartureID != id {
+			continue
+		}
+		// La cancelación de la salida no tiene cargo para el pasajero
+		if trip.Cancel(models.WaivedCancellation(&trip), now) == nil {
+			m.trips[tripID] = trip
+		}
+	}
+
+	departure.Status = models.DepartureStatusCancelled
+	departure.SeatsBooked = 0
+	departure.UpdatedAt = now
+	m.departures[id] = departure
+	return &departure, nil
+}
+
+// bookSeat ocupa un asiento de la salida; requiere m.mu tomado
+func (m *memoryStore) bookSeat(departureID uuid.UUID) error {
+	departure, ok := m.departures[departureID]
+	if !ok {
+		return ErrNotFound
+	}
+	if departure.Status != models.DepartureStatusScheduled {
+		return ErrDepartureCancelled
+	}
+	if departure.SeatsBooked >= departure.Capacity {
+		return ErrDepartureFull
+	}
+	departure.SeatsBooked++
+	departure.UpdatedAt = time.Now()
+	m.departures[departureID] = departure
+	return nil
+}
+
+// releaseSeat libera un asiento de la salida; requiere m.mu tomado
+func (m *memoryStore) releaseSeat(departureID uuid.UUID) {
+	departure, ok := m.departures[departureID]
+	if !ok || departure.SeatsBooked == 0 {
+		return
+	}
+	departure.SeatsBooked--
+	departure.UpdatedAt = time.Now()
+	m.departures[departureID] = departure
+}
+
+func (m *memoryDepartures) Assign(ctx context.Context, id uuid.UUID, driverID, vehicleID *uuid.UUID) (*models.Departure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	departure, ok := m.departures[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if driverID != nil {
+		if _, ok := m.drivers[*driverID]; !ok {
+			return nil, ErrConflict
+		}
+	}
+	if vehicleID != nil {
+		if _, ok := m.vehicles[*vehicleID]; !ok {
+			return nil, ErrConflict
+		}
+	}
+
+	busy := func(assigned func(d *models.Departure) *uuid.UUID, owner *uuid.UUID) bool {
+		if owner == nil {
+			return false
+		}
+		for _, d := range m.departures {
+			current := assigned(&d)
+			if d.ID != id && d.Status == models.DepartureStatusScheduled && current != nil &&
+				*current == *owner && d.Overlaps(&departure) {
+				return true
+			}
+		}
+		return false
+	}
+	if busy(func(d *models.Departure) *uuid.UUID { return d.DriverID }, driverID) {
+		return nil, ErrDriverBusy
+	}
+	if busy(func(d *models.Departure) *uuid.UUID { return d.VehicleID }, vehicleID) {
+		return nil, ErrVehicleBusy
+	}
+
+	departure.DriverID = driverID
+	departure.VehicleID = vehicleID
+	departure.UpdatedAt = time.Now()
+	m.departures[id] = departure
+	return &departure, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryDrivers struct {
+	*memoryStore
+}
+
+// withUser completa nombre y teléfono desde el usuario; requiere m.mu tomado
+func (m *memoryDrivers) withUser(driver models.Driver) models.Driver {
+	user := m.users[driver.UserID]
+	driver.Name = user.Name
+	driver.Phone = user.Phone
+	return driver
+}
+
+func (m *memoryDrivers) List(ctx context.Context) ([]models.Driver, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	drivers := make([]models.Driver, 0, len(m.drivers))
+	for _, driver := range m.drivers {
+		drivers = append(drivers, m.withUser(driver))
+	}
+	sort.Slice(drivers, func(i, j int) bool {
+		if drivers[i].Name != drivers[j].Name {
+			return drivers[i].Name < drivers[j].Name
+		}
+		return drivers[i].UserID.String() < drivers[j].UserID.String()
+	})
+	return drivers, nil
+}
+
+func (m *memoryDrivers) Get(ctx context.Context, userID uuid.UUID) (*models.Driver, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	driver, ok := m.drivers[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	driver = m.withUser(driver)
+	return &driver, nil
+}
+
+func (m *memoryDrivers) Create(ctx context.Context, driver *models.Driver) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[driver.UserID]; !ok {
+		return ErrConflict
+	}
+	if _, exists := m.drivers[driver.UserID]; exists {
+		return ErrConflict
+	}
+	now := time.Now()
+	driver.CreatedAt = now
+	driver.UpdatedAt = now
+	m.drivers[driver.UserID] = *driver
+	*driver = m.withUser(*driver)
+	return nil
+}
+
+func (m *memoryDrivers) Update(ctx context.Context, driver *models.Driver) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.drivers[driver.UserID]
+	if !ok {
+		return ErrNotFound
+	}
+	current.LicenseNumber = driver.LicenseNumber
+	current.LicenseExpiresOn = driver.LicenseExpiresOn
+	current.IsActive = driver.IsActive
+	current.UpdatedAt = time.Now()
+	m.drivers[driver.UserID] = current
+	*driver = m.withUser(current)
+	return nil
+}
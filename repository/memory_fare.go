@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryFares struct {
+	*memoryStore
+}
+
+// copyFare evita compartir la matriz con el store
+func copyFare(f models.FareRule) models.FareRule {
+	f.Matrix = append([]models.FareMatrixEntry{}, f.Matrix...)
+	return f
+}
+
+func (m *memoryFares) Current(ctx context.Context, routeID uuid.UUID) (*models.FareRule, error) {
+	rules, _ := m.ListByRoute(ctx, routeID)
+	if len(rules) == 0 {
+		return nil, ErrNotFound
+	}
+	return &rules[0], nil
+}
+
+func (m *memoryFares) ListByRoute(ctx context.Context, routeID uuid.UUID) ([]models.FareRule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rules := []models.FareRule{}
+	for _, f := range m.fares {
+		if f.RouteID == routeID {
+			rules = append(rules, copyFare(f))
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Version > rules[j].Version
+	})
+	return rules, nil
+}
+
+func (m *memoryFares) Create(ctx context.Context, rule *models.FareRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	if _, exists := m.fares[rule.ID]; exists {
+		return ErrConflict
+	}
+	if _, ok := m.routes[rule.RouteID]; !ok {
+		return ErrNotFound
+	}
+
+	version := 0
+	for _, f := range m.fares {
+		if f.RouteID == rule.RouteID && f.Version > version {
+			version = f.Version
+		}
+	}
+	rule.Version = version + 1
+	rule.CreatedAt = time.Now()
+	*rule = copyFare(*rule)
+	m.fares[rule.ID] = copyFare(*rule)
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryIdempotency struct {
+	*memoryStore
+}
+
+func (m *memoryIdempotency) Begin(ctx context.Context, key *models.IdempotencyKey, notBefore, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := idempotencyKey{userID: key.UserID, key: key.Key}
+	current, ok := m.idempotency[id]
+	stale := ok && !current.Completed() && current.CreatedAt.Before(staleBefore)
+	if ok && !stale && !current.CreatedAt.Before(notBefore) {
+		current.ResponseBody = append([]byte(nil), current.ResponseBody...)
+		return &current, nil
+	}
+	saved := *key
+	saved.StatusCode = 0
+	saved.ContentType = ""
+	saved.ResponseBody = nil
+	saved.CompletedAt = nil
+	m.idempotency[id] = saved
+	return nil, nil
+}
+
+func (m *memoryIdempotency) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := idempotencyKey{userID: key.UserID, key: key.Key}
+	current, ok := m.idempotency[id]
+	if !ok || current.RequestHash != key.RequestHash {
+		return ErrNotFound
+	}
+	current.StatusCode = key.StatusCode
+	current.ContentType = key.ContentType
+	current.ResponseBody = append([]byte(nil), key.ResponseBody...)
+	current.CompletedAt = key.CompletedAt
+	m.idempotency[id] = current
+	return nil
+}
+
+func (m *memoryIdempotency) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := idempotencyKey{userID: userID, key: key}
+	if current, ok := m.idempotency[id]; ok && !current.Completed() {
+		delete(m.idempotency, id)
+	}
+	return nil
+}
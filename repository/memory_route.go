@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryRoutes struct {
+	*memoryStore
+}
+
+func (m *memoryRoutes) ListActive(ctx context.Context, filter RouteFilter) ([]models.Route, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cursor, err := filter.afterRoute()
+	if err != nil {
+		return nil, err
+	}
+	search := strings.ToLower(filter.Search)
+
+	routes := []models.Route{}
+	for _, route := range m.routes {
+		if !route.IsActive {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(route.Name), search) &&
+			!strings.Contains(strings.ToLower(route.OriginName), search) &&
+			!strings.Contains(strings.ToLower(route.DestinationName), search) {
+			continue
+		}
+		if filter.Currency != "" && route.Currency != filter.Currency {
+			continue
+		}
+		if filter.MinPriceCents != nil && route.BasePriceCents < *filter.MinPriceCents {
+			continue
+		}
+		if filter.MaxPriceCents != nil && route.BasePriceCents > *filter.MaxPriceCents {
+			continue
+		}
+		if cursor != nil {
+			cmp := compareRoute(&route, cursor, filter.Sort)
+			if (filter.Desc && cmp >= 0) || (!filter.Desc && cmp <= 0) {
+				continue
+			}
+		}
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		cmp := compareRoute(&routes[i], &routes[j], filter.Sort)
+		if filter.Desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	if filter.Limit > 0 && len(routes) > filter.Limit {
+		routes = routes[:filter.Limit]
+	}
+	return routes, nil
+}
+
+// compareRoute ordena por la columna de sort y luego por id, igual que Postgres
+func compareRoute(a, b *models.Route, sort string) int {
+	var cmp int
+	switch sort {
+	case models.RouteSortName:
+		cmp = strings.Compare(a.Name, b.Name)
+	case models.RouteSortPrice:
+		cmp = a.BasePriceCents - b.BasePriceCents
+	default:
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if cmp != 0 {
+		return cmp
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+func (m *memoryRoutes) Get(ctx context.Context, id uuid.UUID) (*models.Route, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	route, ok := m.routes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &route, nil
+}
+
+func (m *memoryRoutes) Create(ctx context.Context, route *models.Route) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if route.ID == uuid.Nil {
+		route.ID = uuid.New()
+	}
+	if _, exists := m.routes[route.ID]; exists {
+		return ErrConflict
+	}
+	now := time.Now()
+	route.CreatedAt = now
+	route.UpdatedAt = now
+	m.routes[route.ID] = *route
+	return nil
+}
+
+func (m *memoryRoutes) Update(ctx context.Context, route *models.Route) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.routes[route.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	route.IsActive = current.IsActive
+	route.CreatedAt = current.CreatedAt
+	route.UpdatedAt = time.Now()
+	m.routes[route.ID] = *route
+	return nil
+}
+
+func (m *memoryRoutes) SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.Route, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	route, ok := m.routes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	route.IsActive = active
+	route.UpdatedAt = time.Now()
+	m.routes[id] = route
+	return &route, nil
+}
+
+func (m *memoryRoutes) Delete(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.routes[id]; !ok {
+		return ErrNotFound
+	}
+	for _, trip := range m.trips {
+		if trip.RouteID == id {
+			return ErrConflict
+		}
+	}
+
+	for stopID, stop := range m.stops {
+		if stop.RouteID == id {
+			delete(m.stops, stopID)
+		}
+	}
+	for departureID, departure := range m.departures {
+		if departure.RouteID == id {
+			delete(m.departures, departureID)
+		}
+	}
+	for timetableID, timetable := range m.timetables {
+		if timetable.RouteID == id {
+			delete(m.timetables, timetableID)
+		}
+	}
+	for fareID, fare := range m.fares {
+		if fare.RouteID == id {
+			delete(m.fares, fareID)
+		}
+	}
+	for key := range m.routeDrivers {
+		if key.routeID == id {
+			delete(m.routeDrivers, key)
+		}
+	}
+	delete(m.paths, id)
+	delete(m.policies, id)
+	delete(m.routes, id)
+	return nil
+}
+
+func (m *memoryRoutes) Path(ctx context.Context, id uuid.UUID) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.routes[id]; !ok {
+		return "", ErrNotFound
+	}
+	return m.paths[id], nil
+}
+
+func (m *memoryRoutes) SetPath(ctx context.Context, id uuid.UUID, polyline string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	route, ok := m.routes[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if polyline == "" {
+		delete(m.paths, id)
+	} else {
+		m.paths[id] = polyline
+	}
+	route.UpdatedAt = time.Now()
+	m.routes[id] = route
+	return nil
+}
+
+func (m *memoryRoutes) CancellationPolicy(ctx context.Context, id uuid.UUID) (*models.CancellationPolicy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.routes[id]; !ok {
+		return nil, ErrNotFound
+	}
+	policy := m.policies[id]
+	return &policy, nil
+}
+
+func (m *memoryRoutes) SetCancellationPolicy(ctx context.Context, id uuid.UUID, policy models.CancellationPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	route, ok := m.routes[id]
+	if !ok {
+		return ErrNotFound
+	}
+	m.policies[id] = policy
+	route.UpdatedAt = time.Now()
+	m.routes[id] = route
+	return nil
+}
+
+func (m *memoryRoutes) AssignDriver(ctx context.Context, routeID, driverID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.routes[routeID]; !ok {
+		return ErrConflict
+	}
+	if _, ok := m.users[driverID]; !ok {
+		return ErrConflict
+	}
+	m.routeDrivers[routeDriver{routeID, driverID}] = true
+	return nil
+}
+
+func (m *memoryRoutes) UnassignDriver(ctx context.Context, routeID, driverID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := routeDriver{routeID, driverID}
+	if !m.routeDrivers[key] {
+		return ErrNotFound
+	}
+	delete(m.routeDrivers, key)
+	return nil
+}
+
+func (m *memoryRoutes) HasDriver(ctx context.Context, routeID, driverID uuid.UUID) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.routeDrivers[routeDriver{routeID, driverID}], nil
+}
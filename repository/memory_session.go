@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memorySessions struct {
+	*memoryStore
+}
+
+func (m *memorySessions) SaveCode(ctx context.Context, code *models.AuthCode, notBefore time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if current, ok := m.codes[code.Phone]; ok && !current.CreatedAt.Before(notBefore) {
+		return false, nil
+	}
+	saved := *code
+	saved.Attempts = 0
+	m.codes[code.Phone] = saved
+	return true, nil
+}
+
+func (m *memorySessions) ConsumeCode(ctx context.Context, phone, codeHash string, now time.Time, maxAttempts int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	code, ok := m.codes[phone]
+	if !ok || now.After(code.ExpiresAt) || code.Attempts >= maxAttempts {
+		return false, nil
+	}
+	if code.CodeHash != codeHash {
+		code.Attempts++
+		m.codes[phone] = code
+		return false, nil
+	}
+	delete(m.codes, phone)
+	return true, nil
+}
+
+func (m *memorySessions) CreateSession(ctx context.Context, session *models.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[session.UserID]; !ok {
+		return ErrConflict
+	}
+	if _, exists := m.sessions[session.TokenHash]; exists {
+		return ErrConflict
+	}
+	m.sessions[session.TokenHash] = *session
+	return nil
+}
+
+func (m *memorySessions) UserBySession(ctx context.Context, tokenHash string, now time.Time) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[tokenHash]
+	if !ok || !session.ExpiresAt.After(now) {
+		return nil, ErrNotFound
+	}
+	user, ok := m.users[session.UserID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (m *memorySessions) DeleteSession(ctx context.Context, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, tokenHash)
+	return nil
+}
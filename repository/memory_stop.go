@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/geo"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryStops struct {
+	*memoryStore
+}
+
+func (m *memoryStops) ListByRoute(ctx context.Context, routeID uuid.UUID, activeOnly bool) ([]models.RouteStop, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.routeStops(routeID, activeOnly), nil
+}
+
+func (m *memoryStops) Get(ctx context.Context, id uuid.UUID) (*models.RouteStop, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stop, ok := m.stops[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &stop, nil
+}
+
+func (m *memoryStops) Create(ctx context.Context, stop *models.RouteStop) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.routes[stop.RouteID]; !ok {
+		return ErrNotFound
+	}
+
+	stops := m.routeStops(stop.RouteID, false)
+	position := stop.Order
+	if position == 0 {
+		position = len(stops) + 1
+	}
+	if position < 1 || position > len(stops)+1 {
+		return fmt.Errorf("%w: order debe estar entre 1 y %d", ErrInvalidStopOrder, len(stops)+1)
+	}
+
+	// Desplazar las paradas desde la posición pedida
+	for _, s := range stops {
+		if s.Order >= position {
+			s.Order++
+			m.stops[s.ID] = s
+		}
+	}
+
+	if stop.ID == uuid.Nil {
+		stop.ID = uuid.New()
+	}
+	stop.Order = position
+	stop.IsActive = true
+	stop.CreatedAt = time.Now()
+	m.stops[stop.ID] = *stop
+	return nil
+}
+
+func (m *memoryStops) Update(ctx context.Context, stop *models.RouteStop) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.stops[stop.ID]
+	if !ok || current.RouteID != stop.RouteID {
+		return ErrNotFound
+	}
+	current.Name = stop.Name
+	current.Latitude = stop.Latitude
+	current.Longitude = stop.Longitude
+	m.stops[stop.ID] = current
+	*stop = current
+	return nil
+}
+
+func (m *memoryStops) Reorder(ctx context.Context, routeID uuid.UUID, stopIDs []uuid.UUID) ([]models.RouteStop, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.routes[routeID]; !ok {
+		return nil, ErrNotFound
+	}
+	if err := checkPermutation(m.routeStops(routeID, false), stopIDs); err != nil {
+		return nil, err
+	}
+
+	for i, id := range stopIDs {
+		stop := m.stops[id]
+		stop.Order = i + 1
+		m.stops[id] = stop
+	}
+	return m.routeStops(routeID, false), nil
+}
+
+func (m *memoryStops) SetActive(ctx context.Context, routeID, stopID uuid.UUID, active bool) (*models.RouteStop, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stop, ok := m.stops[stopID]
+	if !ok || stop.RouteID != routeID {
+		return nil, ErrNotFound
+	}
+	stop.IsActive = active
+	m.stops[stopID] = stop
+	return &stop, nil
+}
+
+// routeStops retorna las paradas de la ruta ordenadas; requiere el lock tomado
+func (m *memoryStore) routeStops(routeID uuid.UUID, activeOnly bool) []models.RouteStop {
+	stops := []models.RouteStop{}
+	for _, stop := range m.stops {
+		if stop.RouteID == routeID && (stop.IsActive || !activeOnly) {
+			stops = append(stops, stop)
+		}
+	}
+	sort.Slice(stops, func(i, j int) bool {
+		return stops[i].Order < stops[j].Order
+	})
+	return stops
+}
+
+func (m *memoryStops) Nearby(ctx context.Context, lat, lon, radiusMeters float64, limit int) ([]models.NearbyStop, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	box := geo.BoundingBox(lat, lon, radiusMeters)
+	stops := []models.NearbyStop{}
+	for _, stop := range m.stops {
+		route, ok := m.routes[stop.RouteID]
+		if !stop.IsActive || !ok || !route.IsActive || !box.Contains(stop.Latitude, stop.Longitude) {
+			continue
+		}
+		distance := geo.Distance(lat, lon, stop.Latitude, stop.Longitude)
+		if distance > radiusMeters {
+			continue
+		}
+		stops = append(stops, models.NearbyStop{
+			ID:        stop.ID,
+			Name:      stop.Name,
+			Latitude:  stop.Latitude,
+			Longitude: stop.Longitude,
+			Route: models.RouteInfo{
+				ID:          route.ID,
+				Name:        route.Name,
+				Origin:      route.OriginName,
+				Destination: route.DestinationName,
+				BasePrice:   float64(route.BasePriceCents) / 100.0,
+			},
+			DistanceMeters: distance,
+		})
+	}
+	sort.Slice(stops, func(i, j int) bool {
+		if stops[i].DistanceMeters != stops[j].DistanceMeters {
+			return stops[i].DistanceMeters < stops[j].DistanceMeters
+		}
+		return bytes.Compare(stops[i].ID[:], stops[j].ID[:]) < 0
+	})
+	if limit > 0 && len(stops) > limit {
+		stops = stops[:limit]
+	}
+	return stops, nil
+}
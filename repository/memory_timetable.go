@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryTimetables struct {
+	*memoryStore
+}
+
+// copyTimetable evita compartir los slices con el store
+func copyTimetable(t models.Timetable) models.Timetable {
+	t.Weekdays = append([]int(nil), t.Weekdays...)
+	t.Times = append([]string(nil), t.Times...)
+	return t
+}
+
+func (m *memoryTimetables) ListByRoute(ctx context.Context, routeID uuid.UUID) ([]models.Timetable, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	timetables := []models.Timetable{}
+	for _, t := range m.timetables {
+		if t.RouteID == routeID {
+			timetables = append(timetables, copyTimetable(t))
+		}
+	}
+	sort.Slice(timetables, func(i, j int) bool {
+		return timetables[i].CreatedAt.Before(timetables[j].CreatedAt)
+	})
+	return timetables, nil
+}
+
+func (m *memoryTimetables) Get(ctx context.Context, id uuid.UUID) (*models.Timetable, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	timetable, ok := m.timetables[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	timetable = copyTimetable(timetable)
+	return &timetable, nil
+}
+
+func (m *memoryTimetables) Create(ctx context.Context, timetable *models.Timetable) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if timetable.ID == uuid.Nil {
+		timetable.ID = uuid.New()
+	}
+	if _, exists := m.timetables[timetable.ID]; exists {
+		return ErrConflict
+	}
+	if _, ok := m.routes[timetable.RouteID]; !ok {
+		return ErrConflict
+	}
+	now := time.Now()
+	timetable.CreatedAt = now
+	timetable.UpdatedAt = now
+	m.timetables[timetable.ID] = copyTimetable(*timetable)
+	return nil
+}
+
+func (m *memoryTimetables) Update(ctx context.Context, timetable *models.Timetable) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.timetables[timetable.ID]
+	if !ok || current.RouteID != timetable.RouteID {
+		return ErrNotFound
+	}
+	timetable.CreatedAt = current.CreatedAt
+	timetable.UpdatedAt = time.Now()
+	m.timetables[timetable.ID] = copyTimetable(*timetable)
+	return nil
+}
+
+func (m *memoryTimetables) Delete(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.timetables[id]; !ok {
+		return ErrNotFound
+	}
+	// Las salidas generadas se conservan sin horario (ON DELETE SET NULL)
+	for departureID, d := range m.departures {
+		if d.TimetableID != nil && *d.TimetableID == id {
+			d.TimetableID = nil
+			m.departures[departureID] = d
+		}
+	}
+	delete(m.timetables, id)
+	return nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryTrips struct {
+	*memoryStore
+}
+
+func (m *memoryTrips) Create(ctx context.Context, trip *models.Trip) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if trip.ID == uuid.Nil {
+		trip.ID = uuid.New()
+	}
+	if _, exists := m.trips[trip.ID]; exists {
+		return ErrConflict
+	}
+	if _, ok := m.routes[trip.RouteID]; !ok {
+		return ErrConflict
+	}
+	if trip.HoldsSeat() {
+		if err := m.bookSeat(*trip.DepartureID); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	trip.CreatedAt = now
+	trip.UpdatedAt = now
+	m.trips[trip.ID] = *trip
+	return nil
+}
+
+func (m *memoryTrips) Get(ctx context.Context, id uuid.UUID) (*models.Trip, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	trip, ok := m.trips[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &trip, nil
+}
+
+func (m *memoryTrips) Update(ctx context.Context, id uuid.UUID, fn func(*models.Trip) error) (*models.Trip, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	trip, ok := m.trips[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	heldSeat := trip.HoldsSeat()
+	if err := fn(&trip); err != nil {
+		return nil, err
+	}
+	if heldSeat && !trip.HoldsSeat() {
+		m.releaseSeat(*trip.DepartureID)
+	}
+	trip.UpdatedAt = time.Now()
+	m.trips[id] = trip
+	return &trip, nil
+}
+
+func (m *memoryTrips) ListDetails(ctx context.Context, filter TripFilter) ([]models.TripDetail, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	trips := []models.Trip{}
+	for _, trip := range m.trips {
+		if trip.PassengerID != filter.PassengerID {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, trip.Status) {
+			continue
+		}
+		if filter.RouteID != nil && trip.RouteID != *filter.RouteID {
+			continue
+		}
+		at := trip.SortTime()
+		if filter.From != nil && at.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !at.Before(*filter.To) {
+			continue
+		}
+		if filter.AfterTime != nil {
+			cmp := compareTrip(at, trip.ID, *filter.AfterTime, filter.AfterID)
+			if (filter.Desc && cmp >= 0) || (!filter.Desc && cmp <= 0) {
+				continue
+			}
+		}
+		trips = append(trips, trip)
+	}
+	slices.SortFunc(trips, func(a, b models.Trip) int {
+		cmp := compareTrip(a.SortTime(), a.ID, b.SortTime(), b.ID)
+		if filter.Desc {
+			return -cmp
+		}
+		return cmp
+	})
+	if filter.Limit > 0 && len(trips) > filter.Limit {
+		trips = trips[:filter.Limit]
+	}
+
+	details := make([]models.TripDetail, 0, len(trips))
+	for _, trip := range trips {
+		route := m.routes[trip.RouteID]
+		details = append(details, models.NewTripDetail(&trip, &route,
+			m.stopInfo(trip.PickupStopID), m.stopInfo(trip.DropoffStopID)))
+	}
+	return details, nil
+}
+
+// compareTrip ordena por fecha y luego por id, igual que Postgres
+func compareTrip(aTime time.Time, aID uuid.UUID, bTime time.Time, bID uuid.UUID) int {
+	if c := aTime.Compare(bTime); c != 0 {
+		return c
+	}
+	return bytes.Compare(aID[:], bID[:])
+}
+
+func (m *memoryStore) stopInfo(id *uuid.UUID) *models.StopInfo {
+	if id == nil {
+		return nil
+	}
+	stop, ok := m.stops[*id]
+	if !ok {
+		return nil
+	}
+	return &models.StopInfo{ID: stop.ID, Name: stop.Name}
+}
+
+func (m *memoryTrips) ListByDeparture(ctx context.Context, departureID uuid.UUID) ([]models.DepartureTrip, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	trips := []models.DepartureTrip{}
+	for _, trip := range m.trips {
+		if trip.HoldsSeat() && *trip.DepartureID == departureID {
+			passenger := m.users[trip.PassengerID]
+			trips = append(trips, models.DepartureTrip{
+				Trip:           trip,
+				PassengerName:  passenger.Name,
+				PassengerPhone: passenger.Phone,
+			})
+		}
+	}
+	slices.SortFunc(trips, func(a, b models.DepartureTrip) int {
+		return compareTrip(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return trips, nil
+}
+
+func (m *memoryTrips) ExpireOverdue(ctx context.Context, expireBefore, noShowBefore time.Time) (*ExpireResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	result := &ExpireResult{}
+	for id, trip := range m.trips {
+		if trip.ScheduledAt == nil {
+			continue
+		}
+		var to, reason string
+		switch {
+		case trip.Status == models.TripStatusRequested && trip.ScheduledAt.Before(expireBefore):
+			to, reason = models.TripStatusExpired, models.TripReasonNotConfirmed
+			result.Expired++
+		case trip.Status == models.TripStatusConfirmed && trip.ScheduledAt.Before(noShowBefore):
+			to, reason = models.TripStatusNoShow, models.TripReasonNotBoarded
+			result.NoShow++
+		default:
+			continue
+		}
+		trip.Transition(to, now)
+		trip.StatusReason = &reason
+		if trip.DepartureID != nil {
+			m.releaseSeat(*trip.DepartureID)
+		}
+		m.trips[id] = trip
+	}
+	return result, nil
+}
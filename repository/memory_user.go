@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryUsers struct {
+	*memoryStore
+}
+
+func (m *memoryUsers) Get(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (m *memoryUsers) GetByPhone(ctx context.Context, phone string) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.Phone == phone {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *memoryUsers) Create(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.users {
+		if existing.Phone == user.Phone || existing.ID == user.ID {
+			return ErrConflict
+		}
+	}
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.Role == "" {
+		user.Role = models.RolePassenger
+	}
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	m.users[user.ID] = *user
+	return nil
+}
+
+func (m *memoryUsers) List(ctx context.Context, role string) ([]models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := []models.User{}
+	for _, user := range m.users {
+		if role == "" || user.Role == role {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.After(users[j].CreatedAt)
+	})
+	return users, nil
+}
+
+func (m *memoryUsers) UpdateRole(ctx context.Context, id uuid.UUID, role string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	m.users[id] = user
+	return &user, nil
+}
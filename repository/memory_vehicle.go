@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type memoryVehicles struct {
+	*memoryStore
+}
+
+func (m *memoryVehicles) List(ctx context.Context) ([]models.Vehicle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	vehicles := make([]models.Vehicle, 0, len(m.vehicles))
+	for _, vehicle := range m.vehicles {
+		vehicles = append(vehicles, vehicle)
+	}
+	sort.Slice(vehicles, func(i, j int) bool { return vehicles[i].Plate < vehicles[j].Plate })
+	return vehicles, nil
+}
+
+func (m *memoryVehicles) Get(ctx context.Context, id uuid.UUID) (*models.Vehicle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	vehicle, ok := m.vehicles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &vehicle, nil
+}
+
+// plateTaken indica si otro vehículo usa la placa; requiere m.mu tomado
+func (m *memoryVehicles) plateTaken(plate string, id uuid.UUID) bool {
+	for _, vehicle := range m.vehicles {
+		if vehicle.Plate == plate && vehicle.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryVehicles) Create(ctx context.Context, vehicle *models.Vehicle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if vehicle.ID == uuid.Nil {
+		vehicle.ID = uuid.New()
+	}
+	if _, exists := m.vehicles[vehicle.ID]; exists || m.plateTaken(vehicle.Plate, vehicle.ID) {
+		return ErrConflict
+	}
+	now := time.Now()
+	vehicle.CreatedAt = now
+	vehicle.UpdatedAt = now
+	m.vehicles[vehicle.ID] = *vehicle
+	return nil
+}
+
+func (m *memoryVehicles) Update(ctx context.Context, vehicle *models.Vehicle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.vehicles[vehicle.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.plateTaken(vehicle.Plate, vehicle.ID) {
+		return ErrConflict
+	}
+	vehicle.CreatedAt = current.CreatedAt
+	vehicle.UpdatedAt = time.Now()
+	m.vehicles[vehicle.ID] = *vehicle
+	return nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+// querier es lo común entre el pool y una transacción
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// pgError traduce los errores de pgx con db.MapError y además marca como
+// ErrConflict las violaciones de unicidad y de llave foránea
+func pgError(err error) error {
+	err = db.MapError(err)
+	if errors.Is(err, ErrConflict) {
+		return err
+	}
+	if errors.Is(err, db.ErrUniqueViolation) || errors.Is(err, db.ErrForeignKeyViolation) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
+
+const routeColumns = `id, name, is_active, origin_name, origin_lat, origin_lon,
+	destination_name, destination_lat, destination_lon,
+	base_price_cents, currency, created_at, updated_at`
+
+func scanRoute(row pgx.Row) (*models.Route, error) {
+	var route models.Route
+	err := row.Scan(
+		&route.ID,
+		&route.Name,
+		&route.IsActive,
+		&route.OriginName,
+		&route.OriginLat,
+		&route.OriginLon,
+		&route.DestinationName,
+		&route.DestinationLat,
+		&route.DestinationLon,
+		&route.BasePriceCents,
+		&route.Currency,
+		&route.CreatedAt,
+		&route.UpdatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &route, nil
+}
+
+const stopColumns = `id, route_id, name, stop_order, latitude, longitude, is_active, created_at`
+
+func scanStop(row pgx.Row) (*models.RouteStop, error) {
+	var stop models.RouteStop
+	err := row.Scan(
+		&stop.ID,
+		&stop.RouteID,
+		&stop.Name,
+		&stop.Order,
+		&stop.Latitude,
+		&stop.Longitude,
+		&stop.IsActive,
+		&stop.CreatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &stop, nil
+}
+
+const departureColumns = `id, route_id, timetable_id, departs_at, capacity, seats_booked,
+	vehicle_label, driver_id, vehicle_id, status, created_at, updated_at`
+
+func scanDeparture(row pgx.Row) (*models.Departure, error) {
+	var departure models.Departure
+	err := row.Scan(
+		&departure.ID,
+		&departure.RouteID,
+		&departure.TimetableID,
+		&departure.DepartsAt,
+		&departure.Capacity,
+		&departure.SeatsBooked,
+		&departure.VehicleLabel,
+		&departure.DriverID,
+		&departure.VehicleID,
+		&departure.Status,
+		&departure.CreatedAt,
+		&departure.UpdatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &departure, nil
+}
+
+const timetableColumns = `id, route_id, weekdays, times, capacity, vehicle_label, is_active, created_at, updated_at`
+
+func scanTimetable(row pgx.Row) (*models.Timetable, error) {
+	var timetable models.Timetable
+	err := row.Scan(
+		&timetable.ID,
+		&timetable.RouteID,
+		&timetable.Weekdays,
+		&timetable.Times,
+		&timetable.Capacity,
+		&timetable.VehicleLabel,
+		&timetable.IsActive,
+		&timetable.CreatedAt,
+		&timetable.UpdatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &timetable, nil
+}
+
+const fareColumns = `id, route_id, version, kind, base_cents, per_stop_cents, per_km_cents, matrix, created_at`
+
+func scanFare(row pgx.Row) (*models.FareRule, error) {
+	var rule models.FareRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.RouteID,
+		&rule.Version,
+		&rule.Kind,
+		&rule.BaseCents,
+		&rule.PerStopCents,
+		&rule.PerKmCents,
+		&rule.Matrix,
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &rule, nil
+}
+
+const tripColumns = `id, route_id, departure_id, passenger_id, pickup_stop_id, dropoff_stop_id, status, status_reason, payment_method,
+	price_cents, currency, fare_rule_id, fare_rule_version, scheduled_at, started_at, finished_at, cancelled_at,
+	cancellation_fee_cents, refund_cents, created_at, updated_at`
+
+func scanTrip(row pgx.Row) (*models.Trip, error) {
+	var trip models.Trip
+	if err := row.Scan(tripFields(&trip)...); err != nil {
+		return nil, pgError(err)
+	}
+	return &trip, nil
+}
+
+// tripFields retorna los destinos de Scan para tripColumns, en orden
+func tripFields(trip *models.Trip) []any {
+	return []any{
+		&trip.ID,
+		&trip.RouteID,
+		&trip.DepartureID,
+		&trip.PassengerID,
+		&trip.PickupStopID,
+		&trip.DropoffStopID,
+		&trip.Status,
+		&trip.StatusReason,
+		&trip.PaymentMethod,
+		&trip.PriceCents,
+		&trip.Currency,
+		&trip.FareRuleID,
+		&trip.FareRuleVersion,
+		&trip.ScheduledAt,
+		&trip.StartedAt,
+		&trip.FinishedAt,
+		&trip.CancelledAt,
+		&trip.CancellationFeeCents,
+		&trip.RefundCents,
+		&trip.CreatedAt,
+		&trip.UpdatedAt,
+	}
+}
+
+const userColumns = `id, name, COALESCE(email, ''), phone, role, created_at, updated_at`
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Phone,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &user, nil
+}
+
+const driverColumns = `d.user_id, u.name, u.phone, d.license_number, d.license_expires_on,
+	d.is_active, d.created_at, d.updated_at`
+
+// driverFrom une el perfil con su usuario; las consultas usan los alias d y u
+const driverFrom = ` FROM app.drivers d JOIN app.users u ON u.id = d.user_id`
+
+func scanDriver(row pgx.Row) (*models.Driver, error) {
+	var driver models.Driver
+	err := row.Scan(
+		&driver.UserID,
+		&driver.Name,
+		&driver.Phone,
+		&driver.LicenseNumber,
+		&driver.LicenseExpiresOn,
+		&driver.IsActive,
+		&driver.CreatedAt,
+		&driver.UpdatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &driver, nil
+}
+
+const vehicleColumns = `id, plate, model, capacity, soat_expires_on, inspection_expires_on,
+	is_active, created_at, updated_at`
+
+func scanVehicle(row pgx.Row) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	err := row.Scan(
+		&vehicle.ID,
+		&vehicle.Plate,
+		&vehicle.Model,
+		&vehicle.Capacity,
+		&vehicle.SOATExpiresOn,
+		&vehicle.InspectionExpiresOn,
+		&vehicle.IsActive,
+		&vehicle.CreatedAt,
+		&vehicle.UpdatedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &vehicle, nil
+}
+
+// collect recorre rows aplicando scan y siempre retorna un slice no nil
+func collect[T any](rows pgx.Rows, scan func(pgx.Row) (*T, error)) ([]T, error) {
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, pgError(err)
+		}
+		items = append(items, *item)
+	}
+	return items, pgError(rows.Err())
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgDepartures struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgDepartures) ListByRoute(ctx context.Context, routeID uuid.UUID, from, to time.Time) ([]models.Departure, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT `+departureColumns+`
+		FROM app.departures
+		WHERE route_id = $1 AND departs_at >= $2 AND departs_at < $3
+		ORDER BY departs_at ASC
+	`, routeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanDeparture)
+}
+
+func (p *pgDepartures) Get(ctx context.Context, id uuid.UUID) (*models.Departure, error) {
+	return scanDeparture(p.pool.QueryRow(ctx,
+		"SELECT "+departureColumns+" FROM app.departures WHERE id = $1", id))
+}
+
+func (p *pgDepartures) Create(ctx context.Context, departure *models.Departure) error {
+	if departure.ID == uuid.Nil {
+		departure.ID = uuid.New()
+	}
+	now := time.Now()
+
+	if departure.Status == "" {
+		departure.Status = models.DepartureStatusScheduled
+	}
+
+	created, err := scanDeparture(p.pool.QueryRow(ctx, `
+		INSERT INTO app.departures (id, route_id, timetable_id, departs_at, capacity, seats_booked,
+		                            vehicle_label, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8, $8)
+		RETURNING `+departureColumns,
+		departure.ID,
+		departure.RouteID,
+		departure.TimetableID,
+		departure.DepartsAt,
+		departure.Capacity,
+		departure.VehicleLabel,
+		departure.Status,
+		now,
+	))
+	if err != nil {
+		return err
+	}
+	*departure = *created
+	return nil
+}
+
+func (p *pgDepartures) Materialize(ctx context.Context, departures []models.Departure) error {
+	if len(departures) == 0 {
+		return nil
+	}
+	now := time.Now()
+
+	batch := &pgx.Batch{}
+	for _, d := range departures {
+		batch.Queue(`
+			INSERT INTO app.departures (id, route_id, timetable_id, departs_at, capacity, seats_booked,
+			                            vehicle_label, status, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8, $8)
+			ON CONFLICT (route_id, departs_at) DO NOTHING`,
+			uuid.New(), d.RouteID, d.TimetableID, d.DepartsAt, d.Capacity, d.VehicleLabel,
+			models.DepartureStatusScheduled, now)
+	}
+	return pgError(p.pool.SendBatch(ctx, batch).Close())
+}
+
+func (p *pgDepartures) SetCapacity(ctx context.Context, id uuid.UUID, capacity int) (*models.Departure, error) {
+	updated, err := scanDeparture(p.pool.QueryRow(ctx, `
+		UPDATE app.departures SET capacity = $2, updated_at = $3
+		WHERE id = $1 AND seats_booked <= $2
+		RETURNING `+departureColumns,
+		id, capacity, time.Now()))
+	if errors.Is(err, ErrNotFound) {
+		// Distinguir salida inexistente de capacidad insuficiente
+		if _, err := p.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrConflict
+	}
+	return updated, err
+}
+
+// bookSeat ocupa un asiento de la salida. El UPDATE condicional bloquea la
+// fila, así que reservas concurrentes se serializan y nunca superan capacity.
+func bookSeat(ctx context.Context, tx pgx.Tx, departureID uuid.UUID) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE app.departures SET seats_booked = seats_booked + 1, updated_at = $2
+		WHERE id = $1 AND status = $3 AND seats_booked < capacity
+	`, departureID, time.Now(), models.DepartureStatusScheduled)
+	if err != nil {
+		return pgError(err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var status string
+	err = tx.QueryRow(ctx, "SELECT status FROM app.departures WHERE id = $1", departureID).Scan(&status)
+	if err != nil {
+		return pgError(err)
+	}
+	if status != models.DepartureStatusScheduled {
+		return ErrDepartureCancelled
+	}
+	return ErrDepartureFull
+}
+
+// releaseSeat libera un asiento de la salida
+func releaseSeat(ctx context.Context, tx pgx.Tx, departureID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE app.departures SET seats_booked = seats_booked - 1, updated_at = $2
+		WHERE id = $1 AND seats_booked > 0
+	`, departureID, time.Now())
+	return pgError(err)
+}
+
+func (p *pgDepartures) Cancel(ctx context.Context, id uuid.UUID) (*models.Departure, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Los viajes se bloquean antes que la salida, en el mismo orden que
+	// pgTrips.Update y ExpireOverdue, para no crear un deadlock
+	cancellable := models.StatusesAllowing(models.TripStatusCancelled)
+	_, err = tx.Exec(ctx, `
+		SELECT id FROM app.trips
+		WHERE departure_id = $1 AND status = ANY($2)
+		FOR UPDATE
+	`, id, cancellable)
+	if err != nil {
+		return nil, pgError(err)
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(ctx, `
+		UPDATE app.trips
+		SET status = $2, cancelled_at = $3, updated_at = $3,
+		    cancellation_fee_cents = 0, refund_cents = price_cents
+		WHERE departure_id = $1 AND status = ANY($4)
+	`, id, models.TripStatusCancelled, now, cancellable)
+	if err != nil {
+		return nil, err
+	}
+
+	departure, err := scanDeparture(tx.QueryRow(ctx, `
+		UPDATE app.departures SET status = $2, seats_booked = 0, updated_at = $3
+		WHERE id = $1
+		RETURNING `+departureColumns,
+		id, models.DepartureStatusCancelled, now))
+	if err != nil {
+		return nil, err
+	}
+	return departure, tx.Commit(ctx)
+}
+
+func (p *pgDepartures) Assign(ctx context.Context, id uuid.UUID, driverID, vehicleID *uuid.UUID) (*models.Departure, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var departsAt time.Time
+	err = tx.QueryRow(ctx, "SELECT departs_at FROM app.departures WHERE id = $1 FOR UPDATE", id).Scan(&departsAt)
+	if err != nil {
+		return nil, pgError(err)
+	}
+
+	// Bloquear conductor y vehículo serializa las asignaciones concurrentes
+	// que podrían cruzarse
+	if driverID != nil {
+		busy, err := scheduledNear(ctx, tx, "SELECT 1 FROM app.drivers WHERE user_id = $1 FOR UPDATE", "driver_id", *driverID, id, departsAt)
+		if err != nil {
+			return nil, err
+		}
+		if busy {
+			return nil, ErrDriverBusy
+		}
+	}
+	if vehicleID != nil {
+		busy, err := scheduledNear(ctx, tx, "SELECT 1 FROM app.vehicles WHERE id = $1 FOR UPDATE", "vehicle_id", *vehicleID, id, departsAt)
+		if err != nil {
+			return nil, err
+		}
+		if busy {
+			return nil, ErrVehicleBusy
+		}
+	}
+
+	departure, err := scanDeparture(tx.QueryRow(ctx, `
+		UPDATE app.departures SET driver_id = $2, vehicle_id = $3, updated_at = $4
+		WHERE id = $1
+		RETURNING `+departureColumns,
+		id, driverID, vehicleID, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return departure, tx.Commit(ctx)
+}
+
+// scheduledNear bloquea con lock la fila del conductor o vehículo ownerID e
+// indica si tiene otra salida programada a menos de models.DepartureDuration
+// de departsAt. column es driver_id o vehicle_id.
+func scheduledNear(ctx context.Context, tx pgx.Tx, lock, column string, ownerID, exclude uuid.UUID, departsAt time.Time) (bool, error) {
+	if _, err := tx.Exec(ctx, lock, ownerID); err != nil {
+		return false, pgError(err)
+	}
+	var busy bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM app.departures
+			WHERE `+column+` = $1 AND id <> $2 AND status = $3
+			  AND departs_at > $4 AND departs_at < $5
+		)`,
+		ownerID, exclude, models.DepartureStatusScheduled,
+		departsAt.Add(-models.DepartureDuration), departsAt.Add(models.DepartureDuration),
+	).Scan(&busy)
+	return busy, pgError(err)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgDrivers struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgDrivers) List(ctx context.Context) ([]models.Driver, error) {
+	rows, err := p.pool.Query(ctx, "SELECT "+driverColumns+driverFrom+" ORDER BY u.name, d.user_id")
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanDriver)
+}
+
+func (p *pgDrivers) Get(ctx context.Context, userID uuid.UUID) (*models.Driver, error) {
+	return scanDriver(p.pool.QueryRow(ctx,
+		"SELECT "+driverColumns+driverFrom+" WHERE d.user_id = $1", userID))
+}
+
+func (p *pgDrivers) Create(ctx context.Context, driver *models.Driver) error {
+	created, err := scanDriver(p.pool.QueryRow(ctx, `
+		WITH d AS (
+			INSERT INTO app.drivers (user_id, license_number, license_expires_on, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+			RETURNING *
+		)
+		SELECT `+driverColumns+` FROM d JOIN app.users u ON u.id = d.user_id`,
+		driver.UserID,
+		driver.LicenseNumber,
+		driver.LicenseExpiresOn,
+		driver.IsActive,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	*driver = *created
+	return nil
+}
+
+func (p *pgDrivers) Update(ctx context.Context, driver *models.Driver) error {
+	updated, err := scanDriver(p.pool.QueryRow(ctx, `
+		WITH d AS (
+			UPDATE app.drivers
+			SET license_number = $2, license_expires_on = $3, is_active = $4, updated_at = $5
+			WHERE user_id = $1
+			RETURNING *
+		)
+		SELECT `+driverColumns+` FROM d JOIN app.users u ON u.id = d.user_id`,
+		driver.UserID,
+		driver.LicenseNumber,
+		driver.LicenseExpiresOn,
+		driver.IsActive,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	*driver = *updated
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgFares struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgFares) Current(ctx context.Context, routeID uuid.UUID) (*models.FareRule, error) {
+	return scanFare(p.pool.QueryRow(ctx, `
+		SELECT `+fareColumns+`
+		FROM app.fare_rules
+		WHERE route_id = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`, routeID))
+}
+
+func (p *pgFares) ListByRoute(ctx context.Context, routeID uuid.UUID) ([]models.FareRule, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT `+fareColumns+`
+		FROM app.fare_rules
+		WHERE route_id = $1
+		ORDER BY version DESC
+	`, routeID)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanFare)
+}
+
+func (p *pgFares) Create(ctx context.Context, rule *models.FareRule) error {
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	if rule.Matrix == nil {
+		rule.Matrix = []models.FareMatrixEntry{}
+	}
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Bloquear la ruta para asignar versiones sin huecos ni duplicados
+	if err := lockRoute(ctx, tx, rule.RouteID); err != nil {
+		return err
+	}
+
+	created, err := scanFare(tx.QueryRow(ctx, `
+		INSERT INTO app.fare_rules (id, route_id, version, kind, base_cents, per_stop_cents, per_km_cents,
+		                            matrix, created_at)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, $6, $7, $8
+		FROM app.fare_rules WHERE route_id = $2
+		RETURNING `+fareColumns,
+		rule.ID,
+		rule.RouteID,
+		rule.Kind,
+		rule.BaseCents,
+		rule.PerStopCents,
+		rule.PerKmCents,
+		rule.Matrix,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	*rule = *created
+	return nil
+}
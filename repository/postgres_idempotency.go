@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgIdempotency struct {
+	pool *pgxpool.Pool
+}
+
+const idempotencyColumns = `user_id, key, request_hash, status_code, content_type,
+	COALESCE(response_body, ''::bytea), created_at, completed_at`
+
+func scanIdempotency(row pgx.Row) (*models.IdempotencyKey, error) {
+	var key models.IdempotencyKey
+	err := row.Scan(
+		&key.UserID,
+		&key.Key,
+		&key.RequestHash,
+		&key.StatusCode,
+		&key.ContentType,
+		&key.ResponseBody,
+		&key.CreatedAt,
+		&key.CompletedAt,
+	)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &key, nil
+}
+
+func (p *pgIdempotency) Begin(ctx context.Context, key *models.IdempotencyKey, notBefore, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	// Si otro request borra la llave entre el INSERT y el SELECT se reintenta una vez
+	for attempt := 0; attempt < 2; attempt++ {
+		tag, err := p.pool.Exec(ctx, `
+			INSERT INTO app.idempotency_keys (user_id, key, request_hash, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash,
+			    status_code = 0,
+			    content_type = '',
+			    response_body = NULL,
+			    created_at = EXCLUDED.created_at,
+			    completed_at = NULL
+			WHERE app.idempotency_keys.created_at < $5
+			   OR (app.idempotency_keys.completed_at IS NULL AND app.idempotency_keys.created_at < $6)
+		`, key.UserID, key.Key, key.RequestHash, key.CreatedAt, notBefore, staleBefore)
+		if err != nil {
+			return nil, pgError(err)
+		}
+		if tag.RowsAffected() > 0 {
+			return nil, nil
+		}
+
+		existing, err := scanIdempotency(p.pool.QueryRow(ctx,
+			"SELECT "+idempotencyColumns+" FROM app.idempotency_keys WHERE user_id = $1 AND key = $2",
+			key.UserID, key.Key))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		return existing, err
+	}
+	return nil, ErrConflict
+}
+
+func (p *pgIdempotency) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	tag, err := p.pool.Exec(ctx, `
+		UPDATE app.idempotency_keys
+		SET status_code = $3, content_type = $4, response_body = $5, completed_at = $6
+		WHERE user_id = $1 AND key = $2 AND request_hash = $7
+	`, key.UserID, key.Key, key.StatusCode, key.ContentType, key.ResponseBody, key.CompletedAt, key.RequestHash)
+	if err != nil {
+		return pgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *pgIdempotency) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	_, err := p.pool.Exec(ctx,
+		"DELETE FROM app.idempotency_keys WHERE user_id = $1 AND key = $2 AND completed_at IS NULL",
+		userID, key)
+	return pgError(err)
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgRoutes struct {
+	pool *pgxpool.Pool
+}
+
+// routeSortColumns son las columnas de cada orden de rutas
+var routeSortColumns = map[string]string{
+	models.RouteSortCreatedAt: "created_at",
+	models.RouteSortName:      "name",
+	models.RouteSortPrice:     "base_price_cents",
+}
+
+func (p *pgRoutes) ListActive(ctx context.Context, filter RouteFilter) ([]models.Route, error) {
+	column, ok := routeSortColumns[filter.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	order, after := "ASC", ">"
+	if filter.Desc {
+		order, after = "DESC", "<"
+	}
+
+	args := []any{likePattern(filter.Search), filter.Currency, filter.MinPriceCents, filter.MaxPriceCents}
+	query := `
+		SELECT ` + routeColumns + `
+		FROM app.routes
+		WHERE is_active = true
+		  AND ($1 = '' OR name ILIKE $1 OR origin_name ILIKE $1 OR destination_name ILIKE $1)
+		  AND ($2 = '' OR currency = $2)
+		  AND ($3::integer IS NULL OR base_price_cents >= $3)
+		  AND ($4::integer IS NULL OR base_price_cents <= $4)`
+
+	cursor, err := filter.afterRoute()
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil {
+		var value any
+		switch column {
+		case "name":
+			value = cursor.Name
+		case "base_price_cents":
+			value = cursor.BasePriceCents
+		default:
+			value = cursor.CreatedAt
+		}
+		args = append(args, value, cursor.ID)
+		query += `
+		  AND (` + column + `, id) ` + after + ` ($5, $6)`
+	}
+
+	query += `
+		ORDER BY ` + column + ` ` + order + `, id ` + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return collect(rows, scanRoute)
+}
+
+// likePattern arma el patrón ILIKE de una búsqueda por texto; "" si no hay búsqueda
+func likePattern(search string) string {
+	if search == "" {
+		return ""
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search)
+	return "%" + escaped + "%"
+}
+
+func (p *pgRoutes) Get(ctx context.Context, id uuid.UUID) (*models.Route, error) {
+	return scanRoute(p.pool.QueryRow(ctx,
+		"SELECT "+routeColumns+" FROM app.routes WHERE id = $1", id))
+}
+
+func (p *pgRoutes) Create(ctx context.Context, route *models.Route) error {
+	if route.ID == uuid.Nil {
+		route.ID = uuid.New()
+	}
+	now := time.Now()
+
+	created, err := scanRoute(p.pool.QueryRow(ctx, `
+		INSERT INTO app.routes (id, name, is_active, origin_name, origin_lat, origin_lon,
+		                        destination_name, destination_lat, destination_lon,
+		                        base_price_cents, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+		RETURNING `+routeColumns,
+		route.ID,
+		route.Name,
+		route.IsActive,
+		route.OriginName,
+		route.OriginLat,
+		route.OriginLon,
+		route.DestinationName,
+		route.DestinationLat,
+		route.DestinationLon,
+		route.BasePriceCents,
+		route.Currency,
+		now,
+	))
+	if err != nil {
+		return err
+	}
+	*route = *created
+	return nil
+}
+
+func (p *pgRoutes) Update(ctx context.Context, route *models.Route) error {
+	updated, err := scanRoute(p.pool.QueryRow(ctx, `
+		UPDATE app.routes
+		SET name = $2, origin_name = $3, origin_lat = $4, origin_lon = $5,
+		    destination_name = $6, destination_lat = $7, destination_lon = $8,
+		    base_price_cents = $9, currency = $10, updated_at = $11
+		WHERE id = $1
+		RETURNING `+routeColumns,
+		route.ID,
+		route.Name,
+		route.OriginName,
+		route.OriginLat,
+		route.OriginLon,
+		route.DestinationName,
+		route.DestinationLat,
+		route.DestinationLon,
+		route.BasePriceCents,
+		route.Currency,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	*route = *updated
+	return nil
+}
+
+func (p *pgRoutes) SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.Route, error) {
+	return scanRoute(p.pool.QueryRow(ctx,
+		"UPDATE app.routes SET is_active = $2, updated_at = $3 WHERE id = $1 RETURNING "+routeColumns,
+		id, active, time.Now()))
+}
+
+func (p *pgRoutes) Path(ctx context.Context, id uuid.UUID) (string, error) {
+	var polyline string
+	err := p.pool.QueryRow(ctx,
+		"SELECT COALESCE(path_polyline, '') FROM app.routes WHERE id = $1", id).Scan(&polyline)
+	if err != nil {
+		return "", pgError(err)
+	}
+	return polyline, nil
+}
+
+func (p *pgRoutes) SetPath(ctx context.Context, id uuid.UUID, polyline string) error {
+	tag, err := p.pool.Exec(ctx,
+		"UPDATE app.routes SET path_polyline = NULLIF($2, ''), updated_at = $3 WHERE id = $1",
+		id, polyline, time.Now())
+	if err != nil {
+		return pgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *pgRoutes) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM app.route_stops WHERE route_id = $1", id); err != nil {
+		return pgError(err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM app.departures WHERE route_id = $1", id); err != nil {
+		return pgError(err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM app.timetables WHERE route_id = $1", id); err != nil {
+		return pgError(err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM app.fare_rules WHERE route_id = $1", id); err != nil {
+		return pgError(err)
+	}
+	tag, err := tx.Exec(ctx, "DELETE FROM app.routes WHERE id = $1", id)
+	if err != nil {
+		return pgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (p *pgRoutes) AssignDriver(ctx context.Context, routeID, driverID uuid.UUID) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO app.route_drivers (route_id, driver_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		routeID, driverID, time.Now())
+	return pgError(err)
+}
+
+func (p *pgRoutes) UnassignDriver(ctx context.Context, routeID, driverID uuid.UUID) error {
+	tag, err := p.pool.Exec(ctx,
+		"DELETE FROM app.route_drivers WHERE route_id = $1 AND driver_id = $2",
+		routeID, driverID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *pgRoutes) HasDriver(ctx context.Context, routeID, driverID uuid.UUID) (bool, error) {
+	var exists bool
+	err := p.pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM app.route_drivers WHERE route_id = $1 AND driver_id = $2)",
+		routeID, driverID).Scan(&exists)
+	return exists, err
+}
+
+func (p *pgRoutes) CancellationPolicy(ctx context.Context, id uuid.UUID) (*models.CancellationPolicy, error) {
+	var policy models.CancellationPolicy
+	err := p.pool.QueryRow(ctx,
+		"SELECT cancel_free_minutes, cancel_fee_percent FROM app.routes WHERE id = $1", id,
+	).Scan(&policy.FreeUntilMinutes, &policy.FeePercent)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return &policy, nil
+}
+
+func (p *pgRoutes) SetCancellationPolicy(ctx context.Context, id uuid.UUID, policy models.CancellationPolicy) error {
+	tag, err := p.pool.Exec(ctx,
+		"UPDATE app.routes SET cancel_free_minutes = $2, cancel_fee_percent = $3, updated_at = $4 WHERE id = $1",
+		id, policy.FreeUntilMinutes, policy.FeePercent, time.Now())
+	if err != nil {
+		return pgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgSessions struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgSessions) SaveCode(ctx context.Context, code *models.AuthCode, notBefore time.Time) (bool, error) {
+	tag, err := p.pool.Exec(ctx, `
+		INSERT INTO app.auth_codes (phone, code_hash, attempts, expires_at, created_at)
+		VALUES ($1, $2, 0, $3, $4)
+		ON CONFLICT (phone) DO UPDATE
+		SET code_hash = EXCLUDED.code_hash,
+		    attempts = 0,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = EXCLUDED.created_at
+		WHERE app.auth_codes.created_at < $5
+	`, code.Phone, code.CodeHash, code.ExpiresAt, code.CreatedAt, notBefore)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *pgSessions) ConsumeCode(ctx context.Context, phone, codeHash string, now time.Time, maxAttempts int) (bool, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// La fila se bloquea para contar intentos de forma atómica
+	var code models.AuthCode
+	err = tx.QueryRow(ctx,
+		"SELECT code_hash, attempts, expires_at FROM app.auth_codes WHERE phone = $1 FOR UPDATE",
+		phone).Scan(&code.CodeHash, &code.Attempts, &code.ExpiresAt)
+	if err := pgError(err); errors.Is(err, ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if now.After(code.ExpiresAt) || code.Attempts >= maxAttempts {
+		return false, nil
+	}
+
+	if code.CodeHash != codeHash {
+		if _, err := tx.Exec(ctx,
+			"UPDATE app.auth_codes SET attempts = attempts + 1 WHERE phone = $1", phone); err != nil {
+			return false, err
+		}
+		return false, tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM app.auth_codes WHERE phone = $1", phone); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (p *pgSessions) CreateSession(ctx context.Context, session *models.Session) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO app.sessions (token_hash, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4)",
+		session.TokenHash, session.UserID, session.ExpiresAt, session.CreatedAt)
+	return pgError(err)
+}
+
+func (p *pgSessions) UserBySession(ctx context.Context, tokenHash string, now time.Time) (*models.User, error) {
+	return scanUser(p.pool.QueryRow(ctx, `
+		SELECT u.id, u.name, COALESCE(u.email, ''), u.phone, u.role, u.created_at, u.updated_at
+		FROM app.sessions s
+		JOIN app.users u ON u.id = s.user_id
+		WHERE s.token_hash = $1 AND s.expires_at > $2
+	`, tokenHash, now))
+}
+
+func (p *pgSessions) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := p.pool.Exec(ctx, "DELETE FROM app.sessions WHERE token_hash = $1", tokenHash)
+	return err
+}
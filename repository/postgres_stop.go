@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/geo"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgStops struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgStops) ListByRoute(ctx context.Context, routeID uuid.UUID, activeOnly bool) ([]models.RouteStop, error) {
+	return listStops(ctx, p.pool, routeID, activeOnly)
+}
+
+func (p *pgStops) Get(ctx context.Context, id uuid.UUID) (*models.RouteStop, error) {
+	return scanStop(p.pool.QueryRow(ctx,
+		"SELECT "+stopColumns+" FROM app.route_stops WHERE id = $1", id))
+}
+
+func (p *pgStops) Create(ctx context.Context, stop *models.RouteStop) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Bloquear la ruta para serializar cambios de orden
+	if err := lockRoute(ctx, tx, stop.RouteID); err != nil {
+		return err
+	}
+
+	stops, err := listStops(ctx, tx, stop.RouteID, false)
+	if err != nil {
+		return err
+	}
+
+	position := stop.Order
+	if position == 0 {
+		position = len(stops) + 1
+	}
+	if position < 1 || position > len(stops)+1 {
+		return fmt.Errorf("%w: order debe estar entre 1 y %d", ErrInvalidStopOrder, len(stops)+1)
+	}
+
+	if stop.ID == uuid.Nil {
+		stop.ID = uuid.New()
+	}
+
+	// Insertar al final y luego mover a la posición pedida
+	created, err := scanStop(tx.QueryRow(ctx, `
+		INSERT INTO app.route_stops (id, route_id, name, stop_order, latitude, longitude, is_active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, true, $7)
+		RETURNING `+stopColumns,
+		stop.ID,
+		stop.RouteID,
+		stop.Name,
+		len(stops)+1,
+		stop.Latitude,
+		stop.Longitude,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+
+	if position <= len(stops) {
+		ids := make([]uuid.UUID, 0, len(stops)+1)
+		for _, s := range stops {
+			if s.Order == position {
+				ids = append(ids, created.ID)
+			}
+			ids = append(ids, s.ID)
+		}
+		if err := renumberStops(ctx, tx, stop.RouteID, ids); err != nil {
+			return err
+		}
+		created.Order = position
+	}
+
+	if err := checkStopOrder(ctx, tx, stop.RouteID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	*stop = *created
+	return nil
+}
+
+func (p *pgStops) Update(ctx context.Context, stop *models.RouteStop) error {
+	updated, err := scanStop(p.pool.QueryRow(ctx, `
+		UPDATE app.route_stops SET name = $3, latitude = $4, longitude = $5
+		WHERE id = $1 AND route_id = $2
+		RETURNING `+stopColumns,
+		stop.ID, stop.RouteID, stop.Name, stop.Latitude, stop.Longitude))
+	if err != nil {
+		return err
+	}
+	*stop = *updated
+	return nil
+}
+
+func (p *pgStops) Reorder(ctx context.Context, routeID uuid.UUID, stopIDs []uuid.UUID) ([]models.RouteStop, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockRoute(ctx, tx, routeID); err != nil {
+		return nil, err
+	}
+
+	stops, err := listStops(ctx, tx, routeID, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPermutation(stops, stopIDs); err != nil {
+		return nil, err
+	}
+
+	if err := renumberStops(ctx, tx, routeID, stopIDs); err != nil {
+		return nil, err
+	}
+	if err := checkStopOrder(ctx, tx, routeID); err != nil {
+		return nil, err
+	}
+
+	stops, err = listStops(ctx, tx, routeID, false)
+	if err != nil {
+		return nil, err
+	}
+	return stops, tx.Commit(ctx)
+}
+
+func (p *pgStops) SetActive(ctx context.Context, routeID, stopID uuid.UUID, active bool) (*models.RouteStop, error) {
+	return scanStop(p.pool.QueryRow(ctx,
+		"UPDATE app.route_stops SET is_active = $3 WHERE id = $1 AND route_id = $2 RETURNING "+stopColumns,
+		stopID, routeID, active))
+}
+
+// listStops retorna las paradas de la ruta ordenadas por stop_order
+func listStops(ctx context.Context, q querier, routeID uuid.UUID, activeOnly bool) ([]models.RouteStop, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+stopColumns+`
+		FROM app.route_stops
+		WHERE route_id = $1 AND (is_active OR NOT $2)
+		ORDER BY stop_order ASC
+	`, routeID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanStop)
+}
+
+// lockRoute bloquea la fila de la ruta durante la transacción
+func lockRoute(ctx context.Context, tx pgx.Tx, routeID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, "SELECT id FROM app.routes WHERE id = $1 FOR UPDATE", routeID).Scan(&id)
+	return pgError(err)
+}
+
+// renumberStops asigna stop_order 1..n según el orden de ids.
+// Primero pasa los órdenes a negativo para no chocar con la restricción
+// única (route_id, stop_order) a mitad de la actualización.
+func renumberStops(ctx context.Context, tx pgx.Tx, routeID uuid.UUID, ids []uuid.UUID) error {
+	if _, err := tx.Exec(ctx,
+		"UPDATE app.route_stops SET stop_order = -stop_order WHERE route_id = $1", routeID); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if _, err := tx.Exec(ctx,
+			"UPDATE app.route_stops SET stop_order = $3 WHERE id = $1 AND route_id = $2",
+			id, routeID, i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStopOrder verifica que stop_order sea contiguo (1..n) en la ruta
+func checkStopOrder(ctx context.Context, tx pgx.Tx, routeID uuid.UUID) error {
+	rows, err := tx.Query(ctx,
+		"SELECT stop_order FROM app.route_stops WHERE route_id = $1 ORDER BY stop_order ASC", routeID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var orders []int
+	for rows.Next() {
+		var order int
+		if err := rows.Scan(&order); err != nil {
+			return err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return contiguous(orders)
+}
+
+// contiguous verifica que los órdenes (ya ordenados) sean 1..n
+func contiguous(orders []int) error {
+	for i, order := range orders {
+		if order != i+1 {
+			return fmt.Errorf("%w: stop_order no es contiguo, se esperaba %d y se encontró %d",
+				ErrInvalidStopOrder, i+1, order)
+		}
+	}
+	return nil
+}
+
+// checkPermutation verifica que ids contenga exactamente las paradas dadas
+func checkPermutation(stops []models.RouteStop, ids []uuid.UUID) error {
+	if len(ids) != len(stops) {
+		return fmt.Errorf("%w: stop_ids debe incluir las %d paradas de la ruta", ErrInvalidStopOrder, len(stops))
+	}
+
+	current := make(map[uuid.UUID]bool, len(stops))
+	for _, s := range stops {
+		current[s.ID] = true
+	}
+	for _, id := range ids {
+		if !current[id] {
+			return fmt.Errorf("%w: stop_ids contiene paradas repetidas o de otra ruta", ErrInvalidStopOrder)
+		}
+		delete(current, id)
+	}
+	return nil
+}
+
+func (p *pgStops) Nearby(ctx context.Context, lat, lon, radiusMeters float64, limit int) ([]models.NearbyStop, error) {
+	// El rectángulo usa el índice de lat/lon; haversine solo se calcula
+	// para las paradas que quedan dentro
+	box := geo.BoundingBox(lat, lon, radiusMeters)
+	rows, err := p.pool.Query(ctx, `
+		SELECT id, name, latitude, longitude, route_id, route_name, origin_name, destination_name,
+		       base_price_cents, distance
+		FROM (
+			SELECT s.id, s.name, s.latitude, s.longitude,
+			       r.id AS route_id, r.name AS route_name, r.origin_name, r.destination_name, r.base_price_cents,
+			       2 * $3::float8 * asin(sqrt(
+			           power(sin(radians(s.latitude - $1) / 2), 2) +
+			           cos(radians($1)) * cos(radians(s.latitude)) * power(sin(radians(s.longitude - $2) / 2), 2)
+			       )) AS distance
+			FROM app.route_stops s
+			JOIN app.routes r ON r.id = s.route_id
+			WHERE s.is_active = true AND r.is_active = true
+			  AND s.latitude BETWEEN $4 AND $5
+			  AND s.longitude BETWEEN $6 AND $7
+		) candidates
+		WHERE distance <= $8
+		ORDER BY distance, id
+		LIMIT $9
+	`, lat, lon, geo.EarthRadiusMeters, box.MinLat, box.MaxLat, box.MinLon, box.MaxLon, radiusMeters, limit)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	defer rows.Close()
+
+	stops := []models.NearbyStop{}
+	for rows.Next() {
+		var (
+			stop      models.NearbyStop
+			basePrice int
+		)
+		err := rows.Scan(&stop.ID, &stop.Name, &stop.Latitude, &stop.Longitude,
+			&stop.Route.ID, &stop.Route.Name, &stop.Route.Origin, &stop.Route.Destination,
+			&basePrice, &stop.DistanceMeters)
+		if err != nil {
+			return nil, pgError(err)
+		}
+		stop.Route.BasePrice = float64(basePrice) / 100.0
+		stops = append(stops, stop)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError(err)
+	}
+	return stops, nil
+}
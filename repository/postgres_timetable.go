@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgTimetables struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgTimetables) ListByRoute(ctx context.Context, routeID uuid.UUID) ([]models.Timetable, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT `+timetableColumns+`
+		FROM app.timetables
+		WHERE route_id = $1
+		ORDER BY created_at ASC
+	`, routeID)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanTimetable)
+}
+
+func (p *pgTimetables) Get(ctx context.Context, id uuid.UUID) (*models.Timetable, error) {
+	return scanTimetable(p.pool.QueryRow(ctx,
+		"SELECT "+timetableColumns+" FROM app.timetables WHERE id = $1", id))
+}
+
+func (p *pgTimetables) Create(ctx context.Context, timetable *models.Timetable) error {
+	if timetable.ID == uuid.Nil {
+		timetable.ID = uuid.New()
+	}
+	now := time.Now()
+
+	created, err := scanTimetable(p.pool.QueryRow(ctx, `
+		INSERT INTO app.timetables (id, route_id, weekdays, times, capacity, vehicle_label, is_active,
+		                            created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING `+timetableColumns,
+		timetable.ID,
+		timetable.RouteID,
+		timetable.Weekdays,
+		timetable.Times,
+		timetable.Capacity,
+		timetable.VehicleLabel,
+		timetable.IsActive,
+		now,
+	))
+	if err != nil {
+		return err
+	}
+	*timetable = *created
+	return nil
+}
+
+func (p *pgTimetables) Update(ctx context.Context, timetable *models.Timetable) error {
+	updated, err := scanTimetable(p.pool.QueryRow(ctx, `
+		UPDATE app.timetables
+		SET weekdays = $3, times = $4, capacity = $5, vehicle_label = $6, is_active = $7, updated_at = $8
+		WHERE id = $1 AND route_id = $2
+		RETURNING `+timetableColumns,
+		timetable.ID,
+		timetable.RouteID,
+		timetable.Weekdays,
+		timetable.Times,
+		timetable.Capacity,
+		timetable.VehicleLabel,
+		timetable.IsActive,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	*timetable = *updated
+	return nil
+}
+
+func (p *pgTimetables) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM app.timetables WHERE id = $1", id)
+	if err != nil {
+		return pgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
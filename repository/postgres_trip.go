@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgTrips struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgTrips) Create(ctx context.Context, trip *models.Trip) error {
+	if trip.ID == uuid.Nil {
+		trip.ID = uuid.New()
+	}
+	now := time.Now()
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// El asiento se reserva en la misma transacción que el viaje
+	if trip.HoldsSeat() {
+		if err := bookSeat(ctx, tx, *trip.DepartureID); err != nil {
+			return err
+		}
+	}
+
+	created, err := scanTrip(tx.QueryRow(ctx, `
+		INSERT INTO app.trips (id, route_id, departure_id, passenger_id, pickup_stop_id, dropoff_stop_id, status,
+		                       payment_method, price_cents, currency, fare_rule_id, fare_rule_version,
+		                       scheduled_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)
+		RETURNING `+tripColumns,
+		trip.ID,
+		trip.RouteID,
+		trip.DepartureID,
+		trip.PassengerID,
+		trip.PickupStopID,
+		trip.DropoffStopID,
+		trip.Status,
+		trip.PaymentMethod,
+		trip.PriceCents,
+		trip.Currency,
+		trip.FareRuleID,
+		trip.FareRuleVersion,
+		trip.ScheduledAt,
+		now,
+	))
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	*trip = *created
+	return nil
+}
+
+func (p *pgTrips) Get(ctx context.Context, id uuid.UUID) (*models.Trip, error) {
+	return scanTrip(p.pool.QueryRow(ctx,
+		"SELECT "+tripColumns+" FROM app.trips WHERE id = $1", id))
+}
+
+func (p *pgTrips) Update(ctx context.Context, id uuid.UUID, fn func(*models.Trip) error) (*models.Trip, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	trip, err := scanTrip(tx.QueryRow(ctx,
+		"SELECT "+tripColumns+" FROM app.trips WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		return nil, err
+	}
+	heldSeat := trip.HoldsSeat()
+	if err := fn(trip); err != nil {
+		return nil, err
+	}
+	if heldSeat && !trip.HoldsSeat() {
+		if err := releaseSeat(ctx, tx, *trip.DepartureID); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := scanTrip(tx.QueryRow(ctx, `
+		UPDATE app.trips
+		SET pickup_stop_id = $2, dropoff_stop_id = $3, status = $4, payment_method = $5,
+		    price_cents = $6, currency = $7, scheduled_at = $8, started_at = $9,
+		    finished_at = $10, cancelled_at = $11, updated_at = $12, status_reason = $13,
+		    cancellation_fee_cents = $14, refund_cents = $15
+		WHERE id = $1
+		RETURNING `+tripColumns,
+		trip.ID,
+		trip.PickupStopID,
+		trip.DropoffStopID,
+		trip.Status,
+		trip.PaymentMethod,
+		trip.PriceCents,
+		trip.Currency,
+		trip.ScheduledAt,
+		trip.StartedAt,
+		trip.FinishedAt,
+		trip.CancelledAt,
+		time.Now(),
+		trip.StatusReason,
+		trip.CancellationFeeCents,
+		trip.RefundCents,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit(ctx)
+}
+
+func (p *pgTrips) ListDetails(ctx context.Context, filter TripFilter) ([]models.TripDetail, error) {
+	order, after := "ASC", ">"
+	if filter.Desc {
+		order, after = "DESC", "<"
+	}
+	statuses := filter.Statuses
+	if statuses == nil {
+		statuses = []string{}
+	}
+
+	// Ruta y paradas se leen en la misma consulta
+	rows, err := p.pool.Query(ctx, `
+		SELECT t.id, t.passenger_id, t.departure_id, t.status, t.status_reason, t.payment_method, t.price_cents, t.currency,
+		       t.fare_rule_version, t.cancellation_fee_cents, t.refund_cents, t.scheduled_at, t.created_at,
+		       r.id, r.name, r.origin_name, r.destination_name, r.base_price_cents,
+		       ps.id, ps.name, ds.id, ds.name
+		FROM app.trips t
+		JOIN app.routes r ON r.id = t.route_id
+		LEFT JOIN app.route_stops ps ON ps.id = t.pickup_stop_id
+		LEFT JOIN app.route_stops ds ON ds.id = t.dropoff_stop_id
+		WHERE t.passenger_id = $1
+		  AND (cardinality($2::text[]) = 0 OR t.status = ANY($2))
+		  AND ($3::uuid IS NULL OR t.route_id = $3)
+		  AND ($4::timestamptz IS NULL OR COALESCE(t.scheduled_at, t.created_at) >= $4)
+		  AND ($5::timestamptz IS NULL OR COALESCE(t.scheduled_at, t.created_at) < $5)
+		  AND ($6::timestamptz IS NULL OR (COALESCE(t.scheduled_at, t.created_at), t.id) `+after+` ($6, $7))
+		ORDER BY COALESCE(t.scheduled_at, t.created_at) `+order+`, t.id `+order+`
+		LIMIT $8
+	`, filter.PassengerID, statuses, filter.RouteID, filter.From, filter.To,
+		filter.AfterTime, filter.AfterID, filter.Limit)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	defer rows.Close()
+
+	details := []models.TripDetail{}
+	for rows.Next() {
+		var (
+			trip                models.Trip
+			route               models.Route
+			pickupID, dropoffID *uuid.UUID
+			pickupName          *string
+			dropoffName         *string
+		)
+		err := rows.Scan(
+			&trip.ID, &trip.PassengerID, &trip.DepartureID, &trip.Status, &trip.StatusReason, &trip.PaymentMethod,
+			&trip.PriceCents, &trip.Currency, &trip.FareRuleVersion, &trip.CancellationFeeCents, &trip.RefundCents,
+			&trip.ScheduledAt, &trip.CreatedAt,
+			&route.ID, &route.Name, &route.OriginName, &route.DestinationName, &route.BasePriceCents,
+			&pickupID, &pickupName, &dropoffID, &dropoffName,
+		)
+		if err != nil {
+			return nil, pgError(err)
+		}
+		details = append(details, models.NewTripDetail(&trip, &route,
+			stopInfo(pickupID, pickupName), stopInfo(dropoffID, dropoffName)))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError(err)
+	}
+	return details, nil
+}
+
+// stopInfo arma el StopInfo de un LEFT JOIN; nil si no hubo parada
+func stopInfo(id *uuid.UUID, name *string) *models.StopInfo {
+	if id == nil || name == nil {
+		return nil
+	}
+	return &models.StopInfo{ID: *id, Name: *name}
+}
+
+func (p *pgTrips) ListByDeparture(ctx context.Context, departureID uuid.UUID) ([]models.DepartureTrip, error) {
+	// El pasajero se lee en la misma consulta; la subconsulta evita que
+	// las columnas de app.users choquen con tripColumns
+	rows, err := p.pool.Query(ctx, `
+		SELECT `+tripColumns+`, passenger_name, passenger_phone
+		FROM (
+			SELECT t.*, u.name AS passenger_name, COALESCE(u.phone, '') AS passenger_phone
+			FROM app.trips t
+			JOIN app.users u ON u.id = t.passenger_id
+			WHERE t.departure_id = $1 AND t.status <> ALL($2)
+		) t
+		ORDER BY created_at, id
+	`, departureID, models.TripStatusesWithoutSeat)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	defer rows.Close()
+
+	trips := []models.DepartureTrip{}
+	for rows.Next() {
+		var trip models.DepartureTrip
+		fields := append(tripFields(&trip.Trip), &trip.PassengerName, &trip.PassengerPhone)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, pgError(err)
+		}
+		trips = append(trips, trip)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError(err)
+	}
+	return trips, nil
+}
+
+func (p *pgTrips) ExpireOverdue(ctx context.Context, expireBefore, noShowBefore time.Time) (*ExpireResult, error) {
+	// Los viajes y luego sus salidas se bloquean en el mismo orden que Update
+	rows, err := p.pool.Query(ctx, `
+		WITH overdue AS (
+			UPDATE app.trips
+			SET status = CASE status WHEN $1 THEN $3 ELSE $4 END,
+			    status_reason = CASE status WHEN $1 THEN $5 ELSE $6 END,
+			    updated_at = $9
+			WHERE (status = $1 AND scheduled_at < $7)
+			   OR (status = $2 AND scheduled_at < $8)
+			RETURNING departure_id, status
+		), released AS (
+			UPDATE app.departures d
+			SET seats_booked = GREATEST(d.seats_booked - o.n, 0), updated_at = $9
+			FROM (
+				SELECT departure_id, count(*) AS n FROM overdue
+				WHERE departure_id IS NOT NULL
+				GROUP BY departure_id
+			) o
+			WHERE d.id = o.departure_id
+		)
+		SELECT status, count(*) FROM overdue GROUP BY status
+	`, models.TripStatusRequested, models.TripStatusConfirmed,
+		models.TripStatusExpired, models.TripStatusNoShow,
+		models.TripReasonNotConfirmed, models.TripReasonNotBoarded,
+		expireBefore, noShowBefore, time.Now())
+	if err != nil {
+		return nil, pgError(err)
+	}
+	defer rows.Close()
+
+	result := &ExpireResult{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, pgError(err)
+		}
+		switch status {
+		case models.TripStatusExpired:
+			result.Expired = n
+		case models.TripStatusNoShow:
+			result.NoShow = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgUsers struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgUsers) Get(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return scanUser(p.pool.QueryRow(ctx,
+		"SELECT "+userColumns+" FROM app.users WHERE id = $1", id))
+}
+
+func (p *pgUsers) GetByPhone(ctx context.Context, phone string) (*models.User, error) {
+	return scanUser(p.pool.QueryRow(ctx,
+		"SELECT "+userColumns+" FROM app.users WHERE phone = $1", phone))
+}
+
+func (p *pgUsers) Create(ctx context.Context, user *models.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.Role == "" {
+		user.Role = models.RolePassenger
+	}
+
+	created, err := scanUser(p.pool.QueryRow(ctx, `
+		INSERT INTO app.users (id, name, email, phone, role, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $6)
+		RETURNING `+userColumns,
+		user.ID,
+		user.Name,
+		user.Email,
+		user.Phone,
+		user.Role,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	*user = *created
+	return nil
+}
+
+func (p *pgUsers) List(ctx context.Context, role string) ([]models.User, error) {
+	rows, err := p.pool.Query(ctx,
+		"SELECT "+userColumns+" FROM app.users WHERE $1 = '' OR role = $1 ORDER BY created_at DESC", role)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanUser)
+}
+
+func (p *pgUsers) UpdateRole(ctx context.Context, id uuid.UUID, role string) (*models.User, error) {
+	return scanUser(p.pool.QueryRow(ctx,
+		"UPDATE app.users SET role = $2, updated_at = $3 WHERE id = $1 RETURNING "+userColumns,
+		id, role, time.Now()))
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+type pgVehicles struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgVehicles) List(ctx context.Context) ([]models.Vehicle, error) {
+	rows, err := p.pool.Query(ctx, "SELECT "+vehicleColumns+" FROM app.vehicles ORDER BY plate")
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanVehicle)
+}
+
+func (p *pgVehicles) Get(ctx context.Context, id uuid.UUID) (*models.Vehicle, error) {
+	return scanVehicle(p.pool.QueryRow(ctx,
+		"SELECT "+vehicleColumns+" FROM app.vehicles WHERE id = $1", id))
+}
+
+func (p *pgVehicles) Create(ctx context.Context, vehicle *models.Vehicle) error {
+	if vehicle.ID == uuid.Nil {
+		vehicle.ID = uuid.New()
+	}
+
+	created, err := scanVehicle(p.pool.QueryRow(ctx, `
+		INSERT INTO app.vehicles (id, plate, model, capacity, soat_expires_on, inspection_expires_on,
+		                          is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING `+vehicleColumns,
+		vehicle.ID,
+		vehicle.Plate,
+		vehicle.Model,
+		vehicle.Capacity,
+		vehicle.SOATExpiresOn,
+		vehicle.InspectionExpiresOn,
+		vehicle.IsActive,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	*vehicle = *created
+	return nil
+}
+
+func (p *pgVehicles) Update(ctx context.Context, vehicle *models.Vehicle) error {
+	updated, err := scanVehicle(p.pool.QueryRow(ctx, `
+		UPDATE app.vehicles
+		SET plate = $2, model = $3, capacity = $4, soat_expires_on = $5, inspection_expires_on = $6,
+		    is_active = $7, updated_at = $8
+		WHERE id = $1
+		RETURNING `+vehicleColumns,
+		vehicle.ID,
+		vehicle.Plate,
+		vehicle.Model,
+		vehicle.Capacity,
+		vehicle.SOATExpiresOn,
+		vehicle.InspectionExpiresOn,
+		vehicle.IsActive,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+	*vehicle = *updated
+	return nil
+}
@@ -0,0 +1,327 @@
+// Package repository define el acceso a datos de la aplicación.
+// Cada entidad tiene una interfaz con dos implementaciones: Postgres (pgx)
+// para producción y en memoria para pruebas y desarrollo sin base de datos.
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/models"
+)
+
+var (
+	// ErrNotFound indica que el registro buscado no existe. Es el mismo
+	// valor que db.ErrNotFound.
+	ErrNotFound = db.ErrNotFound
+	// ErrConflict indica que la operación choca con datos existentes
+	// (por ejemplo, borrar una ruta que ya tiene viajes). Los errores de
+	// Postgres que lo originan siguen disponibles con errors.Is
+	// (db.ErrUniqueViolation, db.ErrForeignKeyViolation).
+	ErrConflict = errors.New("conflicto con datos existentes")
+	// ErrInvalidStopOrder indica un orden de paradas inválido
+	ErrInvalidStopOrder = errors.New("orden de paradas inválido")
+	// ErrDepartureFull indica que la salida no tiene asientos disponibles
+	ErrDepartureFull = errors.New("la salida no tiene asientos disponibles")
+	// ErrDepartureCancelled indica que la salida fue cancelada
+	ErrDepartureCancelled = errors.New("la salida fue cancelada")
+	// ErrDriverBusy indica que el conductor ya tiene otra salida cercana
+	ErrDriverBusy = errors.New("el conductor ya tiene una salida en ese horario")
+	// ErrVehicleBusy indica que el vehículo ya tiene otra salida cercana
+	ErrVehicleBusy = errors.New("el vehículo ya tiene una salida en ese horario")
+)
+
+// RouteRepository accede a app.routes y a la asignación de conductores
+type RouteRepository interface {
+	// ListActive retorna las rutas activas que cumplen filter. Si el cursor
+	// de filter no corresponde al orden retorna models.ErrInvalidCursor.
+	ListActive(ctx context.Context, filter RouteFilter) ([]models.Route, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Route, error)
+	Create(ctx context.Context, route *models.Route) error
+	// Update guarda los datos editables de la ruta (no is_active)
+	Update(ctx context.Context, route *models.Route) error
+	SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.Route, error)
+	// Delete borra la ruta y sus paradas; ErrConflict si tiene viajes
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Path retorna la polilínea codificada del trazado ("" si no tiene)
+	Path(ctx context.Context, id uuid.UUID) (string, error)
+	// SetPath guarda la polilínea del trazado; "" la borra
+	SetPath(ctx context.Context, id uuid.UUID, polyline string) error
+	// CancellationPolicy retorna la política de cancelación de la ruta
+	CancellationPolicy(ctx context.Context, id uuid.UUID) (*models.CancellationPolicy, error)
+	SetCancellationPolicy(ctx context.Context, id uuid.UUID, policy models.CancellationPolicy) error
+
+	AssignDriver(ctx context.Context, routeID, driverID uuid.UUID) error
+	UnassignDriver(ctx context.Context, routeID, driverID uuid.UUID) error
+	HasDriver(ctx context.Context, routeID, driverID uuid.UUID) (bool, error)
+}
+
+// StopRepository accede a app.route_stops.
+// stop_order se mantiene contiguo (1..n) en cada ruta.
+type StopRepository interface {
+	// ListByRoute retorna las paradas ordenadas por stop_order
+	ListByRoute(ctx context.Context, routeID uuid.UUID, activeOnly bool) ([]models.RouteStop, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.RouteStop, error)
+	// Create inserta la parada en stop.Order (1..n+1) desplazando las
+	// siguientes; con Order 0 la agrega al final
+	Create(ctx context.Context, stop *models.RouteStop) error
+	// Update guarda nombre y coordenadas
+	Update(ctx context.Context, stop *models.RouteStop) error
+	// Reorder asigna stop_order según stopIDs, que debe incluir todas las paradas
+	Reorder(ctx context.Context, routeID uuid.UUID, stopIDs []uuid.UUID) ([]models.RouteStop, error)
+	SetActive(ctx context.Context, routeID, stopID uuid.UUID, active bool) (*models.RouteStop, error)
+	// Nearby retorna las paradas activas de rutas activas a radiusMeters o
+	// menos de lat/lon, de la más cercana a la más lejana
+	Nearby(ctx context.Context, lat, lon, radiusMeters float64, limit int) ([]models.NearbyStop, error)
+}
+
+// DepartureRepository accede a app.departures
+type DepartureRepository interface {
+	// ListByRoute retorna las salidas de la ruta en [from, to), por hora de salida
+	ListByRoute(ctx context.Context, routeID uuid.UUID, from, to time.Time) ([]models.Departure, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Departure, error)
+	// Create registra la salida; ErrConflict si la ruta ya sale a esa hora
+	Create(ctx context.Context, departure *models.Departure) error
+	// Materialize registra las salidas generadas por horarios. Las que ya
+	// existen (misma ruta y hora) se dejan como están, incluso si fueron
+	// canceladas.
+	Materialize(ctx context.Context, departures []models.Departure) error
+	// SetCapacity cambia la capacidad; ErrConflict si quedaría por debajo
+	// de los asientos ya reservados
+	SetCapacity(ctx context.Context, id uuid.UUID, capacity int) (*models.Departure, error)
+	// Cancel cancela la salida y, en la misma transacción, los viajes que
+	// aún no iniciaron, sin cargo y con reembolso del precio completo
+	Cancel(ctx context.Context, id uuid.UUID) (*models.Departure, error)
+	// Assign reemplaza el conductor y el vehículo de la salida (nil quita la
+	// asignación). Retorna ErrDriverBusy o ErrVehicleBusy si alguno ya
+	// tiene otra salida programada a menos de models.DepartureDuration.
+	Assign(ctx context.Context, id uuid.UUID, driverID, vehicleID *uuid.UUID) (*models.Departure, error)
+}
+
+// DriverRepository accede a app.drivers
+type DriverRepository interface {
+	// List retorna los conductores por nombre
+	List(ctx context.Context) ([]models.Driver, error)
+	Get(ctx context.Context, userID uuid.UUID) (*models.Driver, error)
+	// Create registra el perfil; ErrConflict si el usuario ya tiene uno
+	Create(ctx context.Context, driver *models.Driver) error
+	// Update guarda licencia, vencimiento e is_active
+	Update(ctx context.Context, driver *models.Driver) error
+}
+
+// VehicleRepository accede a app.vehicles
+type VehicleRepository interface {
+	// List retorna los vehículos por placa
+	List(ctx context.Context) ([]models.Vehicle, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Vehicle, error)
+	// Create registra el vehículo; ErrConflict si la placa ya existe
+	Create(ctx context.Context, vehicle *models.Vehicle) error
+	// Update guarda los datos del vehículo; ErrConflict si la placa ya existe
+	Update(ctx context.Context, vehicle *models.Vehicle) error
+}
+
+// TimetableRepository accede a app.timetables
+type TimetableRepository interface {
+	// ListByRoute retorna los horarios de la ruta, incluidos los inactivos
+	ListByRoute(ctx context.Context, routeID uuid.UUID) ([]models.Timetable, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Timetable, error)
+	Create(ctx context.Context, timetable *models.Timetable) error
+	// Update guarda días, horas, capacidad, vehículo e is_active. Las
+	// salidas ya generadas no cambian.
+	Update(ctx context.Context, timetable *models.Timetable) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// FareRepository accede a app.fare_rules. Las reglas no se editan: cada
+// cambio de tarifa crea una versión nueva.
+type FareRepository interface {
+	// Current retorna la última versión de la tarifa; ErrNotFound si la
+	// ruta no tiene tarifa por tramo
+	Current(ctx context.Context, routeID uuid.UUID) (*models.FareRule, error)
+	// ListByRoute retorna todas las versiones, la más reciente primero
+	ListByRoute(ctx context.Context, routeID uuid.UUID) ([]models.FareRule, error)
+	// Create guarda la regla como la siguiente versión de la ruta
+	Create(ctx context.Context, rule *models.FareRule) error
+}
+
+// TripRepository accede a app.trips
+type TripRepository interface {
+	// Create registra el viaje. Si tiene salida, reserva el asiento en la
+	// misma operación atómica; ErrDepartureFull si ya no quedan asientos y
+	// ErrDepartureCancelled si la salida fue cancelada.
+	Create(ctx context.Context, trip *models.Trip) error
+	Get(ctx context.Context, id uuid.UUID) (*models.Trip, error)
+	// Update lee el viaje con la fila bloqueada, aplica fn y guarda el
+	// resultado de forma atómica. Si fn retorna error no se guarda nada.
+	// Si el viaje deja de ocupar asiento (por ejemplo, al cancelarse) se
+	// libera en la salida.
+	Update(ctx context.Context, id uuid.UUID, fn func(*models.Trip) error) (*models.Trip, error)
+	// ListDetails retorna los viajes que cumplen filter con su ruta y
+	// paradas, ordenados por Trip.SortTime y luego por id
+	ListDetails(ctx context.Context, filter TripFilter) ([]models.TripDetail, error)
+	// ListByDeparture retorna los viajes que ocupan asiento en la salida (no
+	// cancelados) con el nombre y teléfono del pasajero, por fecha de
+	// creación y luego por id
+	ListByDeparture(ctx context.Context, departureID uuid.UUID) ([]models.DepartureTrip, error)
+	// ExpireOverdue vence los viajes cuya salida ya pasó: los "requested"
+	// con scheduled_at antes de expireBefore pasan a expired y los
+	// "confirmed" con scheduled_at antes de noShowBefore a no_show, con su
+	// motivo en status_reason. Los asientos se liberan en la misma operación
+	// atómica. Los viajes sin scheduled_at no se tocan.
+	ExpireOverdue(ctx context.Context, expireBefore, noShowBefore time.Time) (*ExpireResult, error)
+}
+
+// ExpireResult cuenta los viajes vencidos por TripRepository.ExpireOverdue
+type ExpireResult struct {
+	Expired int `json:"expired"`
+	NoShow  int `json:"no_show"`
+}
+
+// RouteFilter filtra y pagina RouteRepository.ListActive
+type RouteFilter struct {
+	// Search busca en name, origin_name y destination_name sin distinguir mayúsculas
+	Search        string
+	Currency      string // vacío: todas
+	MinPriceCents *int
+	MaxPriceCents *int
+	Sort          string // models.RouteSort*; vacío: created_at
+	Desc          bool
+	After         *models.Cursor
+	Limit         int // 0: sin límite
+}
+
+// afterRoute retorna una ruta con el valor de orden y el id del cursor, o
+// nil si no hay cursor
+func (f RouteFilter) afterRoute() (*models.Route, error) {
+	if f.After == nil {
+		return nil, nil
+	}
+	route := &models.Route{ID: f.After.ID}
+	switch f.Sort {
+	case models.RouteSortName:
+		route.Name = f.After.Key
+	case models.RouteSortPrice:
+		price, err := strconv.Atoi(f.After.Key)
+		if err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+		route.BasePriceCents = price
+	default:
+		createdAt, err := time.Parse(time.RFC3339Nano, f.After.Key)
+		if err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+		route.CreatedAt = createdAt
+	}
+	return route, nil
+}
+
+// TripFilter filtra y pagina TripRepository.ListDetails
+type TripFilter struct {
+	PassengerID uuid.UUID
+	Statuses    []string   // vacío: todos
+	RouteID     *uuid.UUID // nil: todas
+	From        *time.Time // SortTime >= From
+	To          *time.Time // SortTime < To
+	Desc        bool
+	// AfterTime/AfterID: continuar después de este viaje (cursor)
+	AfterTime *time.Time
+	AfterID   uuid.UUID
+	Limit     int
+}
+
+// UserRepository accede a app.users
+type UserRepository interface {
+	Get(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByPhone(ctx context.Context, phone string) (*models.User, error)
+	// Create registra el usuario; ErrConflict si el teléfono ya existe
+	Create(ctx context.Context, user *models.User) error
+	// List retorna los usuarios con el rol dado (todos si role es "")
+	List(ctx context.Context, role string) ([]models.User, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, role string) (*models.User, error)
+}
+
+// SessionRepository accede a los códigos de acceso y a las sesiones
+type SessionRepository interface {
+	// SaveCode reemplaza el código vigente del teléfono, salvo que se haya
+	// enviado uno después de notBefore; en ese caso retorna false
+	SaveCode(ctx context.Context, code *models.AuthCode, notBefore time.Time) (bool, error)
+	// ConsumeCode valida el código y lo borra si es correcto. Un código
+	// incorrecto suma un intento; expirado o agotado nunca es válido.
+	ConsumeCode(ctx context.Context, phone, codeHash string, now time.Time, maxAttempts int) (bool, error)
+
+	CreateSession(ctx context.Context, session *models.Session) error
+	// UserBySession retorna el dueño de una sesión vigente
+	UserBySession(ctx context.Context, tokenHash string, now time.Time) (*models.User, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+}
+
+// IdempotencyRepository accede a app.idempotency_keys
+type IdempotencyRepository interface {
+	// Begin reserva la llave para un request nuevo. Si la llave ya existe y
+	// fue creada después de notBefore, no la modifica y retorna el registro
+	// existente; si es más antigua la reemplaza como si no existiera. Una
+	// llave sin completar creada antes de staleBefore también se reemplaza:
+	// su request original se cayó sin liberarla.
+	Begin(ctx context.Context, key *models.IdempotencyKey, notBefore, staleBefore time.Time) (*models.IdempotencyKey, error)
+	// Complete guarda la respuesta del request original
+	Complete(ctx context.Context, key *models.IdempotencyKey) error
+	// Release borra la llave para que un reintento vuelva a procesarse
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+}
+
+// Repositories agrupa los repositorios que usan los handlers
+type Repositories struct {
+	Routes      RouteRepository
+	Stops       StopRepository
+	Departures  DepartureRepository
+	Timetables  TimetableRepository
+	Fares       FareRepository
+	Trips       TripRepository
+	Users       UserRepository
+	Sessions    SessionRepository
+	Idempotency IdempotencyRepository
+	Drivers     DriverRepository
+	Vehicles    VehicleRepository
+}
+
+// NewPostgres retorna los repositorios respaldados por el pool de pgx
+func NewPostgres(pool *pgxpool.Pool) *Repositories {
+	return &Repositories{
+		Routes:      &pgRoutes{pool: pool},
+		Stops:       &pgStops{pool: pool},
+		Departures:  &pgDepartures{pool: pool},
+		Timetables:  &pgTimetables{pool: pool},
+		Fares:       &pgFares{pool: pool},
+		Trips:       &pgTrips{pool: pool},
+		Users:       &pgUsers{pool: pool},
+		Sessions:    &pgSessions{pool: pool},
+		Idempotency: &pgIdempotency{pool: pool},
+		Drivers:     &pgDrivers{pool: pool},
+		Vehicles:    &pgVehicles{pool: pool},
+	}
+}
+
+// NewMemory retorna repositorios en memoria, seguros para uso concurrente.
+// Todos comparten el mismo almacenamiento.
+func NewMemory() *Repositories {
+	store := newMemoryStore()
+	return &Repositories{
+		Routes:      &memoryRoutes{store},
+		Stops:       &memoryStops{store},
+		Departures:  &memoryDepartures{store},
+		Timetables:  &memoryTimetables{store},
+		Fares:       &memoryFares{store},
+		Trips:       &memoryTrips{store},
+		Users:       &memoryUsers{store},
+		Sessions:    &memorySessions{store},
+		Idempotency: &memoryIdempotency{store},
+		Drivers:     &memoryDrivers{store},
+		Vehicles:    &memoryVehicles{store},
+	}
+}
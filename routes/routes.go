@@ -5,15 +5,20 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/luisdev-dark/realgov3.git/handlers"
+	"github.com/luisdev-dark/realgov3.git/models"
 )
 
 // SetupRouter configura las rutas del MVP
-func SetupRouter() *chi.Mux {
+func SetupRouter(h *handlers.Handler) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Middleware CORS simple para Expo / web
 	r.Use(corsMiddleware)
 
+	// Errores de enrutamiento con el mismo formato JSON que los handlers
+	r.NotFound(handlers.NotFound)
+	r.MethodNotAllowed(handlers.MethodNotAllowed)
+
 	// Healthcheck
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -21,12 +26,101 @@ func SetupRouter() *chi.Mux {
 	})
 
 	// Rutas de rutas (routes)
-	r.Get("/routes", handlers.GetRoutes)
-	r.Get("/routes/{id}", handlers.GetRouteByID)
+	r.Get("/routes", h.GetRoutes)
+	r.Get("/routes.geojson", h.GetRoutesGeoJSON)
+	r.Get("/routes/{id}", h.GetRouteByID)
+	r.Get("/routes/{id}.geojson", h.GetRouteGeoJSON)
+	r.Get("/routes/{id}/departures", h.ListRouteDepartures)
+	r.Get("/routes/{id}/fare", h.GetRouteFare)
+	r.Get("/routes/{id}/cancellation-policy", h.GetRouteCancellationPolicy)
+	r.Get("/stops/nearby", h.NearbyStops)
+	r.Get("/plan", h.PlanTrip)
+	r.Get("/gtfs.zip", h.ExportGTFS)
+
+	// Autenticación por teléfono con código de un solo uso
+	r.Post("/auth/code", h.RequestCode)
+	r.Post("/auth/verify", h.VerifyCode)
+
+	// Rutas que requieren sesión
+	r.Group(func(r chi.Router) {
+		r.Use(h.RequireAuth)
+
+		r.Post("/auth/logout", h.Logout)
+		r.Get("/me", h.GetMe)
+		r.Get("/me/trips", h.ListMyTrips)
+
+		// Rutas de viajes (trips)
+		r.With(h.Idempotent).Post("/trips", h.CreateTrip)
+		r.Get("/trips/{id}", h.GetTripByID)
+		r.Get("/trips/{id}/cancellation", h.GetCancellationQuote)
+		r.Post("/trips/{id}/cancel", h.CancelTrip)
+		r.Get("/trips/{id}/boarding-pass.png", h.GetBoardingPass)
+
+		// Operación del viaje: conductores asignados y administradores
+		r.Group(func(r chi.Router) {
+			r.Use(handlers.RequireRole(models.RoleDriver, models.RoleAdmin))
+
+			r.Post("/trips/{id}/confirm", h.ConfirmTrip)
+			r.Post("/trips/{id}/start", h.StartTrip)
+			r.Post("/trips/{id}/complete", h.CompleteTrip)
+		})
+
+		// Herramientas del conductor asignado a la salida
+		r.Route("/driver", func(r chi.Router) {
+			r.Use(handlers.RequireRole(models.RoleDriver))
 
-	// Rutas de viajes (trips)
-	r.Post("/trips", handlers.CreateTrip)
-	r.Get("/trips/{id}", handlers.GetTripByID)
+			r.Get("/departures/{id}/manifest", h.GetDepartureManifest)
+			r.Post("/checkin", h.CheckIn)
+		})
+
+		// Gestión (solo administradores)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(handlers.RequireRole(models.RoleAdmin))
+
+			r.Post("/routes", h.CreateRoute)
+			r.Put("/routes/{id}", h.UpdateRoute)
+			r.Delete("/routes/{id}", h.DeleteRoute)
+			r.Post("/routes/{id}/activate", h.ActivateRoute)
+			r.Post("/routes/{id}/deactivate", h.DeactivateRoute)
+			r.Put("/routes/{id}/path", h.SetRoutePath)
+			r.Delete("/routes/{id}/path", h.DeleteRoutePath)
+			r.Put("/routes/{id}/cancellation-policy", h.SetRouteCancellationPolicy)
+
+			r.Get("/routes/{id}/stops", h.ListRouteStops)
+			r.Post("/routes/{id}/stops", h.CreateRouteStop)
+			r.Put("/routes/{id}/stops/order", h.ReorderRouteStops)
+			r.Put("/routes/{id}/stops/{stopID}", h.UpdateRouteStop)
+			r.Post("/routes/{id}/stops/{stopID}/activate", h.ActivateRouteStop)
+			r.Post("/routes/{id}/stops/{stopID}/deactivate", h.DeactivateRouteStop)
+
+			r.Post("/routes/{id}/departures", h.CreateRouteDeparture)
+			r.Put("/routes/{id}/departures/{departureID}", h.UpdateDepartureCapacity)
+			r.Post("/routes/{id}/departures/{departureID}/cancel", h.CancelDeparture)
+			r.Put("/routes/{id}/departures/{departureID}/assignment", h.AssignDeparture)
+			r.Get("/routes/{id}/fares", h.ListRouteFares)
+			r.Post("/routes/{id}/fares", h.CreateRouteFare)
+			r.Get("/routes/{id}/timetables", h.ListRouteTimetables)
+			r.Post("/routes/{id}/timetables", h.CreateRouteTimetable)
+			r.Put("/routes/{id}/timetables/{timetableID}", h.UpdateRouteTimetable)
+			r.Delete("/routes/{id}/timetables/{timetableID}", h.DeleteRouteTimetable)
+
+			r.Get("/users", h.ListUsers)
+			r.Put("/users/{id}/role", h.UpdateUserRole)
+			r.Post("/routes/{id}/drivers", h.AssignRouteDriver)
+			r.Delete("/routes/{id}/drivers/{driverID}", h.UnassignRouteDriver)
+
+			r.Get("/drivers", h.ListDrivers)
+			r.Post("/drivers", h.CreateDriver)
+			r.Get("/drivers/{id}", h.GetDriver)
+			r.Put("/drivers/{id}", h.UpdateDriver)
+			r.Get("/vehicles", h.ListVehicles)
+			r.Post("/vehicles", h.CreateVehicle)
+			r.Get("/vehicles/{id}", h.GetVehicle)
+			r.Put("/vehicles/{id}", h.UpdateVehicle)
+
+			r.Post("/gtfs/import", h.ImportGTFS)
+		})
+	})
 
 	return r
 }
@@ -36,7 +130,8 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Next-Cursor, Idempotent-Replayed")
 
 		// Responder rápido a preflight
 		if r.Method == http.MethodOptions {
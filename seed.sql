@@ -0,0 +1,31 @@
+-- Datos de prueba. Ejecutar después de `go run . migrate up`.
+-- Es idempotente: se puede correr varias veces.
+
+INSERT INTO app.users (id, name, email, phone, role) VALUES
+    ('00000000-0000-0000-0000-000000000001', 'Juan Pérez', 'juan@example.com', '+51987654321', 'passenger')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO app.routes (id, name, is_active, origin_name, origin_lat, origin_lon,
+                        destination_name, destination_lat, destination_lon,
+                        base_price_cents, currency) VALUES
+    ('11111111-1111-1111-1111-111111111111', 'Ruta Centro - Norte', true,
+     'Centro de Lima', -12.0464, -77.0428, 'Norte de Lima', -11.9498, -77.0622, 500, 'PEN'),
+    ('22222222-2222-2222-2222-222222222222', 'Ruta Sur - Este', true,
+     'Sur de Lima', -12.1560, -76.9719, 'Este de Lima', -12.0262, -76.9220, 650, 'PEN')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO app.route_stops (id, route_id, name, stop_order, latitude, longitude) VALUES
+    ('11111111-1111-1111-1111-111111111112', '11111111-1111-1111-1111-111111111111', 'Plaza de Armas', 1, -12.0464, -77.0428),
+    ('11111111-1111-1111-1111-111111111113', '11111111-1111-1111-1111-111111111111', 'Parque Kennedy', 2, -12.1219, -77.0297),
+    ('11111111-1111-1111-1111-111111111114', '11111111-1111-1111-1111-111111111111', 'Estación Central', 3, -11.9498, -77.0622),
+    ('22222222-2222-2222-2222-222222222223', '22222222-2222-2222-2222-222222222222', 'Mall del Sur', 1, -12.1560, -76.9719),
+    ('22222222-2222-2222-2222-222222222224', '22222222-2222-2222-2222-222222222222', 'Avenida Benavides', 2, -12.1285, -76.9890),
+    ('22222222-2222-2222-2222-222222222225', '22222222-2222-2222-2222-222222222222', 'Terminal de Buses', 3, -12.0262, -76.9220)
+ON CONFLICT (id) DO NOTHING;
+
+-- Salidas de mañana (hora de Lima) con 15 asientos
+INSERT INTO app.departures (id, route_id, departs_at, capacity) VALUES
+    (gen_random_uuid(), '11111111-1111-1111-1111-111111111111', ((current_date + 1) + time '07:00') AT TIME ZONE 'America/Lima', 15),
+    (gen_random_uuid(), '11111111-1111-1111-1111-111111111111', ((current_date + 1) + time '18:00') AT TIME ZONE 'America/Lima', 15),
+    (gen_random_uuid(), '22222222-2222-2222-2222-222222222222', ((current_date + 1) + time '08:00') AT TIME ZONE 'America/Lima', 15)
+ON CONFLICT (route_id, departs_at) DO NOTHING;
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/db"
+	"github.com/luisdev-dark/realgov3.git/repository"
+	"github.com/luisdev-dark/realgov3.git/worker"
+)
+
+const tripsUsage = `Uso: go run . trips <comando>
+
+Comandos:
+  expire [-expire-grace 0s] [-no-show-grace 15m]
+                                    vence una vez los viajes cuya salida ya pasó
+                                    (para cron o despliegues sin proceso permanente)`
+
+// runTrips ejecuta los subcomandos de mantenimiento de viajes
+func runTrips(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tripsUsage)
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "expire":
+		runTripsExpire(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, tripsUsage)
+		os.Exit(2)
+	}
+}
+
+func runTripsExpire(args []string) {
+	cfg, err := worker.ExpiryConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Error configurando vencimiento: %v", err)
+	}
+	flags := flag.NewFlagSet("trips expire", flag.ExitOnError)
+	flags.DurationVar(&cfg.ExpireGrace, "expire-grace", cfg.ExpireGrace, "tiempo después de la salida para vencer viajes sin confirmar")
+	flags.DurationVar(&cfg.NoShowGrace, "no-show-grace", cfg.NoShowGrace, "tiempo después de la salida para marcar no_show")
+	flags.Parse(args)
+
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("Error conectando a la base de datos: %v", err)
+	}
+	defer db.CloseDB()
+
+	result, err := worker.ExpireTrips(context.Background(), repository.NewPostgres(db.GetDB()).Trips, cfg, time.Now())
+	if err != nil {
+		log.Fatalf("Error venciendo viajes: %v", err)
+	}
+	fmt.Printf("%d viajes vencidos sin confirmar (expired), %d sin abordar (no_show)\n", result.Expired, result.NoShow)
+}
@@ -0,0 +1,96 @@
+// Package worker agrupa los procesos periódicos del servicio: el
+// vencimiento de los viajes que nunca se confirmaron o abordaron y la
+// generación de las salidas de los horarios.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// Valores por defecto de ExpiryConfig
+const (
+	DefaultExpiryInterval = time.Minute
+	DefaultNoShowGrace    = 15 * time.Minute
+	DefaultExpireGrace    = 0
+)
+
+// ExpiryConfig configura el vencimiento de viajes
+type ExpiryConfig struct {
+	// Interval es cada cuánto corre el proceso dentro del servidor; 0 lo
+	// desactiva (por ejemplo, si se ejecuta aparte con "trips expire")
+	Interval time.Duration
+	// ExpireGrace es el tiempo después de la salida en que un viaje
+	// "requested" sin confirmar pasa a expired
+	ExpireGrace time.Duration
+	// NoShowGrace es el tiempo después de la salida en que un viaje
+	// "confirmed" sin abordar pasa a no_show
+	NoShowGrace time.Duration
+}
+
+// ExpiryConfigFromEnv lee TRIP_EXPIRY_INTERVAL, TRIP_EXPIRE_GRACE y
+// TRIP_NO_SHOW_GRACE en formato de time.ParseDuration (ej: "90s", "15m")
+func ExpiryConfigFromEnv() (ExpiryConfig, error) {
+	cfg := ExpiryConfig{
+		Interval:    DefaultExpiryInterval,
+		ExpireGrace: DefaultExpireGrace,
+		NoShowGrace: DefaultNoShowGrace,
+	}
+	vars := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"TRIP_EXPIRY_INTERVAL", &cfg.Interval},
+		{"TRIP_EXPIRE_GRACE", &cfg.ExpireGrace},
+		{"TRIP_NO_SHOW_GRACE", &cfg.NoShowGrace},
+	}
+	for _, v := range vars {
+		raw := os.Getenv(v.name)
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return cfg, fmt.Errorf("%s inválida: %q", v.name, raw)
+		}
+		*v.value = d
+	}
+	return cfg, nil
+}
+
+// ExpireTrips vence una vez los viajes cuya salida pasó hace más del
+// periodo de gracia, tomando now como hora actual
+func ExpireTrips(ctx context.Context, trips repository.TripRepository, cfg ExpiryConfig, now time.Time) (*repository.ExpireResult, error) {
+	return trips.ExpireOverdue(ctx, now.Add(-cfg.ExpireGrace), now.Add(-cfg.NoShowGrace))
+}
+
+// RunExpiry ejecuta ExpireTrips cada cfg.Interval hasta que ctx termine.
+// Los errores se registran en el log y se reintenta en la siguiente vuelta.
+func RunExpiry(ctx context.Context, trips repository.TripRepository, cfg ExpiryConfig) {
+	if cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := ExpireTrips(ctx, trips, cfg, time.Now())
+		switch {
+		case err != nil:
+			log.Printf("Error venciendo viajes: %v", err)
+		case result.Expired > 0 || result.NoShow > 0:
+			log.Printf("Viajes vencidos: %d expired, %d no_show", result.Expired, result.NoShow)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
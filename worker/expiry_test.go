@@ -0,0 +1,131 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+	"github.com/luisdev-dark/realgov3.git/worker"
+)
+
+func TestExpireTrips(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	now := time.Now().Truncate(time.Second)
+
+	route := &models.Route{Name: "Ruta", IsActive: true, OriginName: "A", DestinationName: "B", Currency: "PEN"}
+	if err := repos.Routes.Create(ctx, route); err != nil {
+		t.Fatal(err)
+	}
+	departure := func(at time.Time) *models.Departure {
+		d := &models.Departure{RouteID: route.ID, DepartsAt: at, Capacity: 10, Status: models.DepartureStatusScheduled}
+		if err := repos.Departures.Create(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	trip := func(d *models.Departure, status string) uuid.UUID {
+		trip := &models.Trip{
+			RouteID:       route.ID,
+			DepartureID:   &d.ID,
+			PassengerID:   uuid.New(),
+			Status:        status,
+			PaymentMethod: models.PaymentMethodCash,
+			ScheduledAt:   &d.DepartsAt,
+		}
+		if err := repos.Trips.Create(ctx, trip); err != nil {
+			t.Fatal(err)
+		}
+		return trip.ID
+	}
+
+	past := departure(now.Add(-time.Hour))
+	recent := departure(now.Add(-5 * time.Minute))
+	future := departure(now.Add(time.Hour))
+
+	unconfirmed := trip(past, models.TripStatusRequested)
+	notBoarded := trip(past, models.TripStatusConfirmed)
+	inGrace := trip(recent, models.TripStatusConfirmed)
+	started := trip(past, models.TripStatusStarted)
+	upcoming := trip(future, models.TripStatusRequested)
+
+	cfg := worker.ExpiryConfig{ExpireGrace: 0, NoShowGrace: 15 * time.Minute}
+	result, err := worker.ExpireTrips(ctx, repos.Trips, cfg, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expired != 1 || result.NoShow != 1 {
+		t.Fatalf("resultado = %+v, se esperaba 1 expired y 1 no_show", result)
+	}
+
+	want := []struct {
+		id     uuid.UUID
+		status string
+		reason string
+	}{
+		{unconfirmed, models.TripStatusExpired, models.TripReasonNotConfirmed},
+		{notBoarded, models.TripStatusNoShow, models.TripReasonNotBoarded},
+		{inGrace, models.TripStatusConfirmed, ""},
+		{started, models.TripStatusStarted, ""},
+		{upcoming, models.TripStatusRequested, ""},
+	}
+	for _, w := range want {
+		got, err := repos.Trips.Get(ctx, w.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reason := ""
+		if got.StatusReason != nil {
+			reason = *got.StatusReason
+		}
+		if got.Status != w.status || reason != w.reason {
+			t.Errorf("viaje en %s = %s (%q), se esperaba %s (%q)", got.ScheduledAt, got.Status, reason, w.status, w.reason)
+		}
+	}
+
+	// Los viajes vencidos liberan su asiento
+	got, err := repos.Departures.Get(ctx, past.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SeatsBooked != 1 {
+		t.Fatalf("seats_booked = %d, solo el viaje iniciado debe ocupar asiento", got.SeatsBooked)
+	}
+
+	// Una segunda pasada no vuelve a vencer nada
+	result, err = worker.ExpireTrips(ctx, repos.Trips, cfg, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expired != 0 || result.NoShow != 0 {
+		t.Fatalf("segunda pasada = %+v", result)
+	}
+}
+
+func TestExpiryConfigFromEnv(t *testing.T) {
+	t.Setenv("TRIP_EXPIRY_INTERVAL", "")
+	t.Setenv("TRIP_EXPIRE_GRACE", "5m")
+	t.Setenv("TRIP_NO_SHOW_GRACE", "")
+	cfg, err := worker.ExpiryConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := worker.ExpiryConfig{
+		Interval:    worker.DefaultExpiryInterval,
+		ExpireGrace: 5 * time.Minute,
+		NoShowGrace: worker.DefaultNoShowGrace,
+	}
+	if cfg != want {
+		t.Fatalf("config = %+v, se esperaba %+v", cfg, want)
+	}
+
+	for _, value := range []string{"diez", "-1m"} {
+		t.Setenv("TRIP_NO_SHOW_GRACE", value)
+		if _, err := worker.ExpiryConfigFromEnv(); err == nil {
+			t.Errorf("TRIP_NO_SHOW_GRACE=%q no retornó error", value)
+		}
+	}
+}
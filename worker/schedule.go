@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+)
+
+// DefaultScheduleInterval es cada cuánto se generan las salidas de los horarios
+const DefaultScheduleInterval = time.Hour
+
+// ScheduleIntervalFromEnv lee DEPARTURE_SCHEDULE_INTERVAL en formato de
+// time.ParseDuration (ej: "30m"); 0 desactiva el proceso dentro del servidor
+func ScheduleIntervalFromEnv() (time.Duration, error) {
+	raw := os.Getenv("DEPARTURE_SCHEDULE_INTERVAL")
+	if raw == "" {
+		return DefaultScheduleInterval, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("DEPARTURE_SCHEDULE_INTERVAL inválida: %q", raw)
+	}
+	return d, nil
+}
+
+// ScheduleDepartures genera una vez las salidas de los horarios de las rutas
+// activas desde la fecha de now hasta models.ScheduleDays días después. Las
+// salidas ya generadas no cambian. Retorna cuántas salidas se consideraron.
+func ScheduleDepartures(ctx context.Context, repos *repository.Repositories, now time.Time) (int, error) {
+	routes, err := repos.Routes.ListActive(ctx, repository.RouteFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, route := range routes {
+		timetables, err := repos.Timetables.ListByRoute(ctx, route.ID)
+		if err != nil {
+			return total, err
+		}
+		var departures []models.Departure
+		for _, t := range timetables {
+			departures = append(departures, t.ScheduledDepartures(now)...)
+		}
+		if err := repos.Departures.Materialize(ctx, departures); err != nil {
+			return total, err
+		}
+		total += len(departures)
+	}
+	return total, nil
+}
+
+// RunSchedule ejecuta ScheduleDepartures cada interval hasta que ctx
+// termine. Los errores se registran en el log y se reintenta en la
+// siguiente vuelta.
+func RunSchedule(ctx context.Context, repos *repository.Repositories, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ScheduleDepartures(ctx, repos, time.Now()); err != nil {
+			log.Printf("Error generando salidas: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luisdev-dark/realgov3.git/models"
+	"github.com/luisdev-dark/realgov3.git/repository"
+	"github.com/luisdev-dark/realgov3.git/worker"
+)
+
+func TestScheduleDepartures(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	// Lunes 2 de marzo de 2026
+	now := time.Date(2026, time.March, 2, 10, 0, 0, 0, models.LocalZone)
+
+	route := func(active bool) *models.Route {
+		route := &models.Route{Name: "Ruta", IsActive: active, OriginName: "A", DestinationName: "B", Currency: "PEN"}
+		if err := repos.Routes.Create(ctx, route); err != nil {
+			t.Fatal(err)
+		}
+		timetable := &models.Timetable{RouteID: route.ID, Weekdays: []int{1}, Times: []string{"06:00", "07:00"}, Capacity: 10, IsActive: true}
+		if err := repos.Timetables.Create(ctx, timetable); err != nil {
+			t.Fatal(err)
+		}
+		return route
+	}
+	active := route(true)
+	inactive := route(false)
+
+	// Lunes de hoy hasta 60 días después: 9 lunes con 2 salidas cada uno
+	for range 2 {
+		count, err := worker.ScheduleDepartures(ctx, repos, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 18 {
+			t.Fatalf("count = %d, se esperaba 18", count)
+		}
+	}
+
+	to := now.AddDate(0, 0, models.ScheduleDays+1)
+	departures, err := repos.Departures.ListByRoute(ctx, active.ID, now.AddDate(0, 0, -1), to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(departures) != 18 {
+		t.Fatalf("la ruta activa tiene %d salidas, se esperaban 18", len(departures))
+	}
+	if last := departures[len(departures)-1].DepartsAt; !last.Equal(time.Date(2026, time.April, 27, 7, 0, 0, 0, models.LocalZone)) {
+		t.Fatalf("última salida = %v", last)
+	}
+
+	if departures, _ := repos.Departures.ListByRoute(ctx, inactive.ID, now.AddDate(0, 0, -1), to); len(departures) != 0 {
+		t.Fatalf("la ruta inactiva tiene %d salidas", len(departures))
+	}
+}